@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/aggregator/pkg"
+	retry "github.com/yetanotherco/aligned_layer/core"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/validation"
+	"github.com/yetanotherco/aligned_layer/metrics"
 )
 
 var (
@@ -18,8 +24,14 @@ var (
 	GitDate   string
 )
 
+// The root config flag is not required, as required root flags are also checked before running the subcommands.
+// aggregatorMain checks it instead
 var flags = []cli.Flag{
-	config.ConfigFileFlag,
+	&cli.StringFlag{
+		Name:  config.ConfigFileFlag.Name,
+		Usage: config.ConfigFileFlag.Usage,
+	},
+	config.WatchConfigFileFlag,
 }
 
 func main() {
@@ -31,6 +43,7 @@ func main() {
 	app.Usage = "Aligned Layer Aggregator"
 	app.Description = "Service that aggregates signed responses from operator nodes."
 	app.Action = aggregatorMain
+	app.Commands = []*cli.Command{validateCommand}
 
 	err := app.Run(os.Args)
 	if err != nil {
@@ -38,17 +51,66 @@ func main() {
 	}
 }
 
+var validateCommand = &cli.Command{
+	Name:        "validate",
+	Usage:       "Check the aggregator config file",
+	Description: "Lists every problem of the config file. With --network, also checks the endpoints, the deployed contracts and that the ecdsa key is the aggregator of the service manager",
+	Flags:       []cli.Flag{config.ConfigFileFlag, validation.NetworkFlag, validation.FormatFlag},
+	Action: func(ctx *cli.Context) error {
+		report := validation.ValidateAggregatorConfig(ctx.String(config.ConfigFileFlag.Name), ctx.Bool(validation.NetworkFlag.Name))
+		return validation.Result(ctx, report)
+	},
+}
+
 func aggregatorMain(ctx *cli.Context) error {
+	if !ctx.IsSet(config.ConfigFileFlag.Name) {
+		_ = cli.ShowAppHelp(ctx)
+		return fmt.Errorf("Required flag %q not set", config.ConfigFileFlag.Name)
+	}
 
 	configFilePath := ctx.String(config.ConfigFileFlag.Name)
-	aggregatorConfig := config.NewAggregatorConfig(configFilePath)
+	aggregatorConfig, err := config.NewAggregatorConfig(configFilePath)
+	if err != nil {
+		return err
+	}
+	defer aggregatorConfig.BaseConfig.Close()
+
+	logger := aggregatorConfig.BaseConfig.Logger
+
+	// Metrics
+	reg := prometheus.NewRegistry()
+	aggregatorMetrics := metrics.NewMetrics(aggregatorConfig.Aggregator.MetricsIpPortAddress, reg, logger)
+	// Report the retried calls of the process in the metrics
+	retry.SetObserver(aggregatorMetrics)
+
+	chainClients, err := pkg.NewChainClientsFromConfig(*aggregatorConfig, aggregatorMetrics)
+	if err != nil {
+		logger.Error("Cannot connect to the chain", "err", err)
+		return err
+	}
 
-	aggregator, err := pkg.NewAggregator(*aggregatorConfig)
+	aggregator, err := pkg.NewAggregator(*aggregatorConfig, *chainClients, reg, aggregatorMetrics)
 	if err != nil {
 		aggregatorConfig.BaseConfig.Logger.Error("Cannot create aggregator", "err", err)
 		return err
 	}
 
+	// Reload the fields that can be changed while running on SIGHUP or when the file changes
+	reloader, err := config.NewAggregatorReloader(configFilePath)
+	if err != nil {
+		return err
+	}
+	err = config.WatchReloads(configFilePath, ctx.Bool(config.WatchConfigFileFlag.Name), logger, func() {
+		aggregator.ReloadConfig(reloader)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Stop on SIGINT or SIGTERM, cancelling the calls in flight
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Supervisor revives garbage collector
 	go func() {
 		for {
@@ -58,15 +120,26 @@ func aggregatorMain(ctx *cli.Context) error {
 		}
 	}()
 
+	// Supervisor revives the aggregated responses tracker
+	go func() {
+		for runCtx.Err() == nil {
+			log.Println("Starting aggregated responses tracker")
+			aggregator.TrackAggregatedResponses(runCtx)
+			if runCtx.Err() == nil {
+				log.Println("Aggregated responses tracker panicked, Supervisor restarting")
+			}
+		}
+	}()
+
 	// Listen for new task created in the ServiceManager contract in a separate goroutine, both V1 and V2 subscriptions:
 	go func() {
-		listenErr := aggregator.SubscribeToNewTasks()
+		listenErr := aggregator.SubscribeToNewTasks(runCtx)
 		if listenErr != nil {
 			aggregatorConfig.BaseConfig.Logger.Fatal("Error subscribing for new tasks", "err", listenErr)
 		}
 	}()
 
-	err = aggregator.Start(context.Background())
+	err = aggregator.Start(runCtx)
 
 	return err
 }
@@ -0,0 +1,224 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TaskStatusResponse struct {
+	TaskIndex                 uint32             `json:"task_index"`
+	BatchIdentifierHash       string             `json:"batch_identifier_hash"`
+	BatchMerkleRoot           string             `json:"batch_merkle_root"`
+	SenderAddress             string             `json:"sender_address"`
+	TaskCreatedBlock          uint32             `json:"task_created_block"`
+	State                     TaskState          `json:"state"`
+	SignersOperatorIds        []string           `json:"signers_operator_ids"`
+	SignedStakePercentages    map[string]float64 `json:"signed_stake_percentages,omitempty"`
+	SignedStakePercentagesErr string             `json:"signed_stake_percentages_error,omitempty"`
+	TimeLeftInWindow          string             `json:"time_left_in_window"`
+	TxHash                    string             `json:"tx_hash,omitempty"`
+	Error                     string             `json:"error,omitempty"`
+}
+
+// TaskListResponse is a page of the tasks held in memory.
+// NextFrom is the task index to request the next page from, it is omitted on the last page
+type TaskListResponse struct {
+	Tasks    []TaskStatusResponse `json:"tasks"`
+	NextFrom *uint32              `json:"next_from,omitempty"`
+}
+
+const (
+	defaultTaskListLimit = 100
+	maxTaskListLimit     = 1000
+	// Time given to the admin requests in progress to finish on shutdown
+	adminShutdownTimeout = 5 * time.Second
+)
+
+// ServeAdmin serves a read-only HTTP API to inspect the tasks the aggregator holds in memory, until ctx is done.
+// It is meant to be bound to localhost, as it has no authentication.
+// Endpoints:
+//   - GET /tasks?from={index}&limit={n}: lists the tasks from the given task index on, ordered by task index.
+//     Built from memory only, so the signed stake percentages are not included
+//   - GET /tasks/{id}: returns a single task with its signed stake percentages, where id is either the task index
+//     or the 0x prefixed batch identifier hash
+//   - GET /operators: returns the participation record of every operator seen by the aggregator
+func (agg *Aggregator) ServeAdmin(ctx context.Context) error {
+	server := http.Server{
+		Addr:         agg.AggregatorConfig.Aggregator.AdminIpPortAddress,
+		Handler:      agg.adminHandler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			agg.logger.Warn("Could not shut down the admin server gracefully", "err", err)
+		}
+	}()
+
+	agg.logger.Info("Starting admin server on address", "address",
+		agg.AggregatorConfig.Aggregator.AdminIpPortAddress)
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (agg *Aggregator) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", agg.handleListTasks)
+	mux.HandleFunc("GET /tasks/{id}", agg.handleGetTask)
+	mux.HandleFunc("GET /operators", agg.handleListOperators)
+	return mux
+}
+
+func (agg *Aggregator) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	from := uint64(0)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid from task index"})
+			return
+		}
+		from = parsed
+	}
+	limit := defaultTaskListLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxTaskListLimit {
+			writeJson(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxTaskListLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	taskIndexes := make([]uint32, 0, len(agg.taskStatusByIdx))
+	for taskIndex := range agg.taskStatusByIdx {
+		if uint64(taskIndex) >= from {
+			taskIndexes = append(taskIndexes, taskIndex)
+		}
+	}
+	sort.Slice(taskIndexes, func(i, j int) bool { return taskIndexes[i] < taskIndexes[j] })
+
+	response := TaskListResponse{Tasks: make([]TaskStatusResponse, 0, min(limit, len(taskIndexes)))}
+	if len(taskIndexes) > limit {
+		nextFrom := taskIndexes[limit]
+		response.NextFrom = &nextFrom
+		taskIndexes = taskIndexes[:limit]
+	}
+	for _, taskIndex := range taskIndexes {
+		response.Tasks = append(response.Tasks, agg.buildTaskStatusResponse(agg.taskStatusByIdx[taskIndex]))
+	}
+
+	writeJson(w, http.StatusOK, response)
+}
+
+func (agg *Aggregator) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var taskIndex uint32
+	if strings.HasPrefix(id, "0x") {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+		if err != nil || len(decoded) != 32 {
+			writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid batch identifier hash"})
+			return
+		}
+		var batchIdentifierHash [32]byte
+		copy(batchIdentifierHash[:], decoded)
+
+		agg.taskMutex.Lock()
+		idx, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+		agg.taskMutex.Unlock()
+		if !ok {
+			writeJson(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+			return
+		}
+		taskIndex = idx
+	} else {
+		idx, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid task index"})
+			return
+		}
+		taskIndex = uint32(idx)
+	}
+
+	agg.taskMutex.Lock()
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	var task TaskStatusResponse
+	if ok {
+		task = agg.buildTaskStatusResponse(status)
+	}
+	agg.taskMutex.Unlock()
+	if !ok {
+		writeJson(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+		return
+	}
+
+	percentages, err := agg.signedStakePercentages(r.Context(), taskIndex)
+	if err != nil {
+		task.SignedStakePercentagesErr = err.Error()
+	} else {
+		task.SignedStakePercentages = make(map[string]float64, len(percentages))
+		for quorumNum, percentage := range percentages {
+			task.SignedStakePercentages[strconv.Itoa(int(quorumNum))] = percentage
+		}
+	}
+
+	writeJson(w, http.StatusOK, task)
+}
+
+func (agg *Aggregator) handleListOperators(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, agg.operatorScoreboard.Snapshot())
+}
+
+// buildTaskStatusResponse builds the response of a task from memory, without its signed stake percentages.
+// Must be called with the taskMutex held
+func (agg *Aggregator) buildTaskStatusResponse(status *TaskStatus) TaskStatusResponse {
+	batchData := agg.batchDataByIdentifierHash[status.BatchIdentifierHash]
+
+	signers := make([]string, 0, len(status.SignersOperatorIds))
+	for _, operatorId := range status.SignersOperatorIds {
+		signers = append(signers, operatorIdLabel(operatorId))
+	}
+
+	timeLeft := time.Until(status.ExpiresAt)
+	if timeLeft < 0 || status.State != TaskStatePending {
+		timeLeft = 0
+	}
+
+	return TaskStatusResponse{
+		TaskIndex:           status.TaskIndex,
+		BatchIdentifierHash: "0x" + hex.EncodeToString(status.BatchIdentifierHash[:]),
+		BatchMerkleRoot:     "0x" + hex.EncodeToString(batchData.BatchMerkleRoot[:]),
+		SenderAddress:       "0x" + hex.EncodeToString(batchData.SenderAddress[:]),
+		TaskCreatedBlock:    status.TaskCreatedBlock,
+		State:               status.State,
+		SignersOperatorIds:  signers,
+		TimeLeftInWindow:    timeLeft.Round(time.Second).String(),
+		TxHash:              status.TxHash,
+		Error:               status.Err,
+	}
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+)
+
+// addTestTasks adds a task for each of the batch merkle roots, returning their batch identifier hashes
+func addTestTasks(aggregator *Aggregator, chain *fake.Chain, batchMerkleRoots ...[32]byte) [][32]byte {
+	batchIdentifierHashes := make([][32]byte, 0, len(batchMerkleRoots))
+	for _, batchMerkleRoot := range batchMerkleRoots {
+		batch := chain.SubmitBatch(batchMerkleRoot, common.HexToAddress("0x1"), "http://localhost/batch.json", big.NewInt(1e15))
+		aggregator.AddNewTask(batch.BatchMerkleRoot, batch.SenderAddress, batch.TaskCreatedBlock)
+		batchIdentifierHashes = append(batchIdentifierHashes, fake.BatchIdentifierHash(batch.BatchMerkleRoot, batch.SenderAddress))
+	}
+	return batchIdentifierHashes
+}
+
+func getAdmin(t *testing.T, aggregator *Aggregator, path string, body any) int {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	recorder := httptest.NewRecorder()
+	aggregator.adminHandler().ServeHTTP(recorder, request)
+	if body != nil && recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), body); err != nil {
+			t.Fatalf("Could not decode the response of %s: %v", path, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestAdminListTasksIsPaginated(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	addTestTasks(aggregator, chain, [32]byte{1}, [32]byte{2}, [32]byte{3})
+
+	var page TaskListResponse
+	if code := getAdmin(t, aggregator, "/tasks?limit=2", &page); code != http.StatusOK {
+		t.Fatalf("Expected the tasks to be listed, got status %d", code)
+	}
+	if len(page.Tasks) != 2 || page.Tasks[0].TaskIndex != 0 || page.Tasks[1].TaskIndex != 1 {
+		t.Errorf("Expected the first two tasks, got %+v", page.Tasks)
+	}
+	if page.NextFrom == nil || *page.NextFrom != 2 {
+		t.Fatalf("Expected the next page to start at task 2, got %v", page.NextFrom)
+	}
+	if page.Tasks[0].SignedStakePercentages != nil || page.Tasks[0].SignedStakePercentagesErr != "" {
+		t.Errorf("Expected the list to be built without fetching the signed stake")
+	}
+
+	page = TaskListResponse{}
+	if code := getAdmin(t, aggregator, "/tasks?from=2", &page); code != http.StatusOK {
+		t.Fatalf("Expected the tasks to be listed, got status %d", code)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].TaskIndex != 2 || page.NextFrom != nil {
+		t.Errorf("Expected only the last task on the last page, got %+v", page)
+	}
+}
+
+func TestAdminListTasksRejectsInvalidParameters(t *testing.T) {
+	aggregator := newTestAggregator(t, fake.NewChain())
+	for _, path := range []string{"/tasks?limit=0", "/tasks?limit=1001", "/tasks?limit=a", "/tasks?from=-1"} {
+		if code := getAdmin(t, aggregator, path, nil); code != http.StatusBadRequest {
+			t.Errorf("Expected %s to be rejected, got status %d", path, code)
+		}
+	}
+}
+
+func TestAdminGetTask(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	batchIdentifierHashes := addTestTasks(aggregator, chain, [32]byte{1}, [32]byte{2})
+	hashPath := "/tasks/0x" + hex.EncodeToString(batchIdentifierHashes[1][:])
+
+	for _, path := range []string{"/tasks/1", hashPath} {
+		var task TaskStatusResponse
+		if code := getAdmin(t, aggregator, path, &task); code != http.StatusOK {
+			t.Fatalf("Expected %s to be found, got status %d", path, code)
+		}
+		if task.TaskIndex != 1 || task.State != TaskStatePending {
+			t.Errorf("Expected %s to return the pending task 1, got %+v", path, task)
+		}
+		// The fake registry has no stake for the task block, so only the attempt to fetch it shows
+		if task.SignedStakePercentages == nil && task.SignedStakePercentagesErr == "" {
+			t.Errorf("Expected %s to fetch the signed stake", path)
+		}
+	}
+
+	if code := getAdmin(t, aggregator, "/tasks/7", nil); code != http.StatusNotFound {
+		t.Errorf("Expected an unknown task not to be found, got status %d", code)
+	}
+	if code := getAdmin(t, aggregator, "/tasks/0x01", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid batch identifier hash to be rejected, got status %d", code)
+	}
+}
+
+func TestServeAdminStopsWithItsContext(t *testing.T) {
+	aggregator := newTestAggregator(t, fake.NewChain())
+	aggregator.AggregatorConfig.Aggregator.AdminIpPortAddress = "localhost:0"
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- aggregator.ServeAdmin(ctx) }()
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("Expected the admin server to stop without error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the admin server to stop once its context is done")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -13,15 +14,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yetanotherco/aligned_layer/metrics"
 
-	sdkclients "github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
 	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
-	oppubkeysserv "github.com/Layr-Labs/eigensdk-go/services/operatorsinfo"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
-	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
 	"github.com/yetanotherco/aligned_layer/core/types"
 	"github.com/yetanotherco/aligned_layer/core/utils"
@@ -41,13 +39,20 @@ type BatchData struct {
 }
 
 type Aggregator struct {
-	AggregatorConfig      *config.AggregatorConfig
-	NewBatchChan          chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
-	avsReader             *chainio.AvsReader
-	avsSubscriber         *chainio.AvsSubscriber
-	avsWriter             *chainio.AvsWriter
-	taskSubscriber        chan error
+	// Config at startup. The fields that can be reloaded are read from settings
+	AggregatorConfig  *config.AggregatorConfig
+	settings          atomic.Pointer[config.AggregatorSettings]
+	NewBatchChan      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	VerifiedBatchChan chan [32]byte
+	avsReader         AvsReader
+	avsSubscriber     AvsSubscriber
+	avsWriter         AvsWriter
+	taskSubscriber    chan error
+	// Block of the latest batch received, the new task subscriptions resume from it.
+	// Only accessed by SubscribeToNewTasks
+	lastNewBatchBlock     uint64
 	blsAggregationService blsagg.BlsAggregationService
+	avsRegistryService    avsregistry.AvsRegistryService
 
 	// BLS Signature Service returns an Index
 	// Since our ID is not an idx, we build this cache
@@ -67,6 +72,10 @@ type Aggregator struct {
 	// Stores the TaskResponse for each batch by batchIdentifierHash
 	batchDataByIdentifierHash map[[32]byte]BatchData
 
+	// Stores the TaskStatus for each batch by batch index.
+	// Only used to expose the state of the tasks through the admin API
+	taskStatusByIdx map[uint32]*TaskStatus
+
 	// This task index is to communicate with the local BLS
 	// Service.
 	// Note: In case of a reboot it can start from 0 again
@@ -77,6 +86,7 @@ type Aggregator struct {
 	// - batchesIdxByIdentifierHash
 	// - batchCreatedBlockByIdx
 	// - batchDataByIdentifierHash
+	// - taskStatusByIdx
 	// - nextBatchIndex
 	taskMutex *sync.Mutex
 
@@ -91,54 +101,32 @@ type Aggregator struct {
 
 	// Telemetry
 	telemetry *Telemetry
+
+	// Per operator record of signed and missed tasks
+	operatorScoreboard *OperatorScoreboard
+
+	// Aggregated responses sent but not final yet
+	responseTracker *ResponseTracker
+
+	// Context given to Start, used by the RPC methods of the operators as they don't get one
+	ctx context.Context
 }
 
-func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error) {
+// NewAggregator builds an aggregator on top of chainClients, see NewChainClientsFromConfig.
+// aggregatorMetrics must have been created on reg, which is served when metrics are enabled
+func NewAggregator(aggregatorConfig config.AggregatorConfig, chainClients ChainClients, reg *prometheus.Registry, aggregatorMetrics *metrics.Metrics) (*Aggregator, error) {
 	newBatchChan := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
 	logger := aggregatorConfig.BaseConfig.Logger
 
-	// Metrics
-	reg := prometheus.NewRegistry()
-	aggregatorMetrics := metrics.NewMetrics(aggregatorConfig.Aggregator.MetricsIpPortAddress, reg, logger)
-
 	// Telemetry
 	aggregatorTelemetry := NewTelemetry(aggregatorConfig.Aggregator.TelemetryIpPortAddress, logger)
 
-	avsReader, err := chainio.NewAvsReaderFromConfig(aggregatorConfig.BaseConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	avsSubscriber, err := chainio.NewAvsSubscriberFromConfig(aggregatorConfig.BaseConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	avsWriter, err := chainio.NewAvsWriterFromConfig(aggregatorConfig.BaseConfig, aggregatorConfig.EcdsaConfig, aggregatorMetrics)
-	if err != nil {
-		return nil, err
-	}
-
 	batchesIdentifierHashByIdx := make(map[uint32][32]byte)
 	batchesIdxByIdentifierHash := make(map[[32]byte]uint32)
 	batchDataByIdentifierHash := make(map[[32]byte]BatchData)
 	batchCreatedBlockByIdx := make(map[uint32]uint64)
-
-	chainioConfig := sdkclients.BuildAllConfig{
-		EthHttpUrl:                 aggregatorConfig.BaseConfig.EthRpcUrl,
-		EthWsUrl:                   aggregatorConfig.BaseConfig.EthWsUrl,
-		RegistryCoordinatorAddr:    aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.Hex(),
-		OperatorStateRetrieverAddr: aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.Hex(),
-		AvsName:                    "AlignedLayer",
-		PromMetricsIpPortAddress:   ":9090",
-	}
-
-	clients, err := sdkclients.BuildReadClients(chainioConfig, logger)
-	if err != nil {
-		logger.Errorf("Cannot create sdk clients", "err", err)
-		return nil, err
-	}
+	taskStatusByIdx := make(map[uint32]*TaskStatus)
 
 	// This is a dummy "hash function" made to fulfill the BLS aggregator service API requirements.
 	// When operators respond to a task, a call to `ProcessNewSignature` is made. In `v0.1.6` of the eigensdk,
@@ -155,39 +143,48 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 		return taskResponseDigest, nil
 	}
 
-	operatorPubkeysService := oppubkeysserv.NewOperatorsInfoServiceInMemory(context.Background(), clients.AvsRegistryChainSubscriber, clients.AvsRegistryChainReader, nil, oppubkeysserv.Opts{}, logger)
-	avsRegistryService := avsregistry.NewAvsRegistryServiceChainCaller(avsReader.ChainReader, operatorPubkeysService, logger)
+	avsRegistryService := chainClients.AvsRegistryService
 	blsAggregationService := blsagg.NewBlsAggregatorService(avsRegistryService, hashFunction, logger)
 
 	nextBatchIndex := uint32(0)
 
 	aggregator := Aggregator{
-		AggregatorConfig: &aggregatorConfig,
-		avsReader:        avsReader,
-		avsSubscriber:    avsSubscriber,
-		avsWriter:        avsWriter,
-		NewBatchChan:     newBatchChan,
+		AggregatorConfig:  &aggregatorConfig,
+		avsReader:         chainClients.AvsReader,
+		avsSubscriber:     chainClients.AvsSubscriber,
+		avsWriter:         chainClients.AvsWriter,
+		NewBatchChan:      newBatchChan,
+		VerifiedBatchChan: make(chan [32]byte, 100),
 
 		batchesIdentifierHashByIdx: batchesIdentifierHashByIdx,
 		batchesIdxByIdentifierHash: batchesIdxByIdentifierHash,
 		batchDataByIdentifierHash:  batchDataByIdentifierHash,
 		batchCreatedBlockByIdx:     batchCreatedBlockByIdx,
+		taskStatusByIdx:            taskStatusByIdx,
 		nextBatchIndex:             nextBatchIndex,
 		taskMutex:                  &sync.Mutex{},
 		walletMutex:                &sync.Mutex{},
 
 		blsAggregationService: blsAggregationService,
+		avsRegistryService:    avsRegistryService,
 		logger:                logger,
 		metricsReg:            reg,
 		metrics:               aggregatorMetrics,
 		telemetry:             aggregatorTelemetry,
+		operatorScoreboard:    NewOperatorScoreboard(aggregatorMetrics),
+		responseTracker:       NewResponseTracker(),
+		ctx:                   context.Background(),
 	}
 
+	aggregator.settings.Store(aggregatorConfig.Settings())
+
 	return &aggregator, nil
 }
 
+// Start serves the operators and sends the aggregated responses until ctx is done
 func (agg *Aggregator) Start(ctx context.Context) error {
 	agg.logger.Infof("Starting aggregator...")
+	agg.ctx = ctx
 
 	go func() {
 		err := agg.ServeOperators()
@@ -196,6 +193,15 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 		}
 	}()
 
+	if agg.AggregatorConfig.Aggregator.AdminIpPortAddress != "" {
+		go func() {
+			err := agg.ServeAdmin(ctx)
+			if err != nil {
+				agg.logger.Error("Admin server failed", "err", err)
+			}
+		}()
+	}
+
 	var metricsErrChan <-chan error
 	if agg.AggregatorConfig.Aggregator.EnableMetrics {
 		metricsErrChan = agg.metrics.Start(ctx, agg.metricsReg)
@@ -213,14 +219,14 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 			agg.logger.Info("Received response from BLS aggregation service",
 				"taskIndex", blsAggServiceResp.TaskIndex)
 
-			go agg.handleBlsAggServiceResponse(blsAggServiceResp)
+			go agg.handleBlsAggServiceResponse(ctx, blsAggServiceResp)
 		}
 	}
 }
 
 const MaxSentTxRetries = 5
 
-func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
+func (agg *Aggregator) handleBlsAggServiceResponse(ctx context.Context, blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
 	defer func() {
 		err := recover() //stops panics
 		if err != nil {
@@ -230,16 +236,34 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 
 	agg.taskMutex.Lock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Locked Resources: Fetching task data")
-	batchIdentifierHash := agg.batchesIdentifierHashByIdx[blsAggServiceResp.TaskIndex]
+	batchIdentifierHash, taskTracked := agg.batchesIdentifierHashByIdx[blsAggServiceResp.TaskIndex]
 	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
 	taskCreatedBlock := agg.batchCreatedBlockByIdx[blsAggServiceResp.TaskIndex]
+	taskStatus, taskStatusExists := agg.taskStatusByIdx[blsAggServiceResp.TaskIndex]
+	taskRemoved := taskStatusExists && taskStatus.State == TaskStateRemoved
 	agg.taskMutex.Unlock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Fetching task data")
 
+	// Released tasks are freed right away, see ReleaseTask
+	if !taskTracked {
+		agg.logger.Info("Task is not tracked anymore, batch was already responded on-chain, not sending aggregated response",
+			"taskIndex", blsAggServiceResp.TaskIndex)
+		return
+	}
+
 	// Finish task trace once the task is processed (either successfully or not)
 	defer agg.telemetry.FinishTrace(batchData.BatchMerkleRoot)
 
+	if taskRemoved {
+		agg.logger.Warn("Task was removed by a reorg, not sending aggregated response",
+			"taskIndex", blsAggServiceResp.TaskIndex,
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+		return
+	}
+
 	if blsAggServiceResp.Err != nil {
+		agg.recordTaskState(blsAggServiceResp.TaskIndex, TaskStateFailed, "", blsAggServiceResp.Err)
+		agg.recordMissedOperatorsFromTaskState(ctx, blsAggServiceResp.TaskIndex)
 		agg.telemetry.LogTaskError(batchData.BatchMerkleRoot, blsAggServiceResp.Err)
 		agg.logger.Error("BlsAggregationServiceResponse contains an error", "err", blsAggServiceResp.Err, "batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
 		return
@@ -247,6 +271,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 	nonSignerPubkeys := []servicemanager.BN254G1Point{}
 	for _, nonSignerPubkey := range blsAggServiceResp.NonSignersPubkeysG1 {
 		nonSignerPubkeys = append(nonSignerPubkeys, utils.ConvertToBN254G1Point(nonSignerPubkey))
+		agg.recordMissedOperator(blsAggServiceResp.TaskIndex, eigentypes.OperatorIdFromG1Pubkey(nonSignerPubkey))
 	}
 	quorumApks := []servicemanager.BN254G1Point{}
 	for _, quorumApk := range blsAggServiceResp.QuorumApksG1 {
@@ -264,6 +289,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		NonSignerStakeIndices:        blsAggServiceResp.NonSignerStakeIndices,
 	}
 
+	agg.recordTaskState(blsAggServiceResp.TaskIndex, TaskStateQuorumReached, "", nil)
 	agg.telemetry.LogQuorumReached(batchData.BatchMerkleRoot)
 
 	agg.logger.Info("Threshold reached", "taskIndex", blsAggServiceResp.TaskIndex,
@@ -274,20 +300,32 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
 		"taskCreatedBlock", taskCreatedBlock)
 
-	err := agg.avsSubscriber.WaitForOneBlock(taskCreatedBlock)
+	waitCtx, cancel := agg.taskContext(ctx, blsAggServiceResp.TaskIndex)
+	err := agg.avsSubscriber.WaitForOneBlock(waitCtx, taskCreatedBlock)
+	cancel()
 	if err != nil {
 		agg.logger.Error("Error waiting for one block, sending anyway", "err", err)
 	}
 
 	agg.logger.Info("Sending aggregated response onchain", "taskIndex", blsAggServiceResp.TaskIndex,
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "merkleRoot", "0x"+hex.EncodeToString(batchData.BatchMerkleRoot[:]))
-	receipt, err := agg.sendAggregatedResponse(batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
+	receipt, err := agg.sendAggregatedResponse(ctx, batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
 	if err == nil {
 		// In some cases, we may fail to retrieve the receipt for the transaction.
 		txHash := "Unknown"
 		if receipt != nil {
 			txHash = receipt.TxHash.String()
+			// Keep the response until it is final, in case the transaction is dropped by a reorg
+			agg.responseTracker.add(&trackedResponse{
+				taskIndex:                   blsAggServiceResp.TaskIndex,
+				batchIdentifierHash:         batchIdentifierHash,
+				batchMerkleRoot:             batchData.BatchMerkleRoot,
+				senderAddress:               batchData.SenderAddress,
+				nonSignerStakesAndSignature: nonSignerStakesAndSignature,
+				txHash:                      receipt.TxHash,
+			})
 		}
+		agg.recordTaskState(blsAggServiceResp.TaskIndex, TaskStateResponded, txHash, nil)
 		agg.telemetry.TaskSentToEthereum(batchData.BatchMerkleRoot, txHash)
 		agg.logger.Info("Aggregator successfully responded to task",
 			"taskIndex", blsAggServiceResp.TaskIndex,
@@ -296,6 +334,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		return
 	}
 
+	agg.recordTaskState(blsAggServiceResp.TaskIndex, TaskStateFailed, "", err)
 	agg.logger.Error("Aggregator failed to respond to task, this batch will be lost",
 		"err", err,
 		"taskIndex", blsAggServiceResp.TaskIndex,
@@ -307,7 +346,8 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 
 // / Sends response to contract and waits for transaction receipt
 // / Returns error if it fails to send tx or receipt is not found
-func (agg *Aggregator) sendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
+// / The response gets BlsServiceTaskTimeout to be included, as long as the task had to reach quorum
+func (agg *Aggregator) sendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
 
 	agg.walletMutex.Lock()
 	agg.logger.Infof("- Locked Wallet Resources: Sending aggregated response for batch",
@@ -320,15 +360,19 @@ func (agg *Aggregator) sendAggregatedResponse(batchIdentifierHash [32]byte, batc
 		agg.metrics.IncBumpedGasPriceForAggregatedResponse()
 		agg.telemetry.BumpedTaskGasPrice(batchMerkleRoot, bumpedGasPrice.String())
 	}
+	settings := agg.Settings()
+	ctx, cancel := context.WithTimeout(ctx, settings.BlsServiceTaskTimeout)
+	defer cancel()
 	receipt, err := agg.avsWriter.SendAggregatedResponse(
+		ctx,
 		batchIdentifierHash,
 		batchMerkleRoot,
 		senderAddress,
 		nonSignerStakesAndSignature,
-		agg.AggregatorConfig.Aggregator.GasBaseBumpPercentage,
-		agg.AggregatorConfig.Aggregator.GasBumpIncrementalPercentage,
-		agg.AggregatorConfig.Aggregator.GasBumpPercentageLimit,
-		agg.AggregatorConfig.Aggregator.TimeToWaitBeforeBump,
+		settings.GasBaseBumpPercentage,
+		settings.GasBumpIncrementalPercentage,
+		settings.GasBumpPercentageLimit,
+		settings.TimeToWaitBeforeBump,
 		onGasPriceBumped,
 	)
 	if err != nil {
@@ -388,12 +432,13 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 		"Task", batchIndex,
 		"batchIdentifierHash", batchIdentifierHash,
 	)
+	agg.initTaskStatus(batchIndex, batchIdentifierHash, taskCreatedBlock)
 	agg.nextBatchIndex += 1
 
 	quorumNums := eigentypes.QuorumNums{eigentypes.QuorumNum(QUORUM_NUMBER)}
 	quorumThresholdPercentages := eigentypes.QuorumThresholdPercentages{eigentypes.QuorumThresholdPercentage(QUORUM_THRESHOLD)}
 
-	err := agg.blsAggregationService.InitializeNewTaskWithWindow(batchIndex, taskCreatedBlock, quorumNums, quorumThresholdPercentages, agg.AggregatorConfig.Aggregator.BlsServiceTaskTimeout, 15*time.Second)
+	err := agg.blsAggregationService.InitializeNewTaskWithWindow(batchIndex, taskCreatedBlock, quorumNums, quorumThresholdPercentages, agg.Settings().BlsServiceTaskTimeout, 15*time.Second)
 	if err != nil {
 		agg.logger.Fatalf("BLS aggregation service error when initializing new task: %s", err)
 	}
@@ -404,6 +449,56 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 	agg.logger.Info("New task added", "batchIndex", batchIndex, "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
 }
 
+// RemoveTask is called when the NewBatchV3 log of a task is removed by a reorg.
+// The task is kept in the maps so it can still be inspected, but operator responses for it are no longer accepted
+// and the aggregated response is not sent. If the batch is included again, it is added as a new task.
+func (agg *Aggregator) RemoveTask(batchIdentifierHash [32]byte) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	batchIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	if !ok {
+		agg.logger.Warn("Removed task not found in maps", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+		return
+	}
+
+	delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
+	agg.updateTaskStatus(batchIndex, func(status *TaskStatus) {
+		status.State = TaskStateRemoved
+	})
+	agg.logger.Warn("Task removed by a reorg", "batchIndex", batchIndex, "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+}
+
+// ReleaseTask is called when a BatchVerified event is received for a batch.
+// If the task has not reached quorum yet, it was responded by someone else, so operator responses for it
+// are no longer accepted and the aggregated response is not sent. Tasks responded by this aggregator are left as they are.
+// As nothing is done with a released task anymore, its entries are freed right away instead of by ClearTasksFromMaps.
+// The bls aggregation service can't drop a task, its signatures are kept until the task expires
+func (agg *Aggregator) ReleaseTask(batchIdentifierHash [32]byte) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	batchIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	if !ok {
+		return
+	}
+	status, ok := agg.taskStatusByIdx[batchIndex]
+	if !ok || status.State != TaskStatePending {
+		return
+	}
+
+	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
+	delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
+	delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
+	delete(agg.batchesIdentifierHashByIdx, batchIndex)
+	delete(agg.batchCreatedBlockByIdx, batchIndex)
+	delete(agg.taskStatusByIdx, batchIndex)
+	agg.telemetry.FinishTrace(batchData.BatchMerkleRoot)
+	agg.metrics.IncAggregatorReleasedTasks()
+	agg.logger.Info("Task released, batch was already responded on-chain", "batchIndex", batchIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+}
+
 // |---RETRYABLE---|
 
 // Long-lived goroutine that periodically checks and removes old Tasks from stored Maps
@@ -417,14 +512,15 @@ func (agg *Aggregator) ClearTasksFromMaps() {
 		}
 	}()
 
-	agg.AggregatorConfig.BaseConfig.Logger.Info(fmt.Sprintf("- Removing finalized Task Infos from Maps every %v", agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod))
+	agg.AggregatorConfig.BaseConfig.Logger.Info(fmt.Sprintf("- Removing finalized Task Infos from Maps every %v", agg.Settings().GarbageCollectorPeriod))
 	lastIdxDeleted := uint32(0)
 
 	for {
-		time.Sleep(agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod)
+		time.Sleep(agg.Settings().GarbageCollectorPeriod)
 
 		agg.AggregatorConfig.BaseConfig.Logger.Info("Cleaning finalized tasks from maps")
-		oldTaskIdHash, err := agg.avsReader.GetOldTaskHash(agg.AggregatorConfig.Aggregator.GarbageCollectorTasksAge, agg.AggregatorConfig.Aggregator.GarbageCollectorTasksInterval)
+		settings := agg.Settings()
+		oldTaskIdHash, err := agg.avsReader.GetOldTaskHash(settings.GarbageCollectorTasksAge, settings.GarbageCollectorTasksInterval)
 		if err != nil {
 			agg.logger.Error("Error getting old task hash, skipping this garbage collect", "err", err)
 			continue // Retry in the next iteration
@@ -443,12 +539,17 @@ func (agg *Aggregator) ClearTasksFromMaps() {
 			batchIdentifierHash, exists := agg.batchesIdentifierHashByIdx[i]
 			if exists {
 				agg.logger.Info("Cleaning up finalized task", "taskIndex", i)
-				delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
+				// The hash may point to a newer task if the batch was removed by a reorg and included again
+				if idx, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]; !ok || idx == i {
+					delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
+					delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
+				}
 				delete(agg.batchCreatedBlockByIdx, i)
 				delete(agg.batchesIdentifierHashByIdx, i)
-				delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
+				delete(agg.taskStatusByIdx, i)
 			} else {
-				agg.logger.Warn("Task not found in maps", "taskIndex", i)
+				// Released tasks are freed when they are released
+				agg.logger.Debug("Task not found in maps", "taskIndex", i)
 			}
 		}
 		lastIdxDeleted = taskIdxToDelete
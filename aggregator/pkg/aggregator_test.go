@@ -0,0 +1,270 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+var (
+	_ AvsReader     = (*fake.Chain)(nil)
+	_ AvsWriter     = (*fake.Chain)(nil)
+	_ AvsSubscriber = (*fake.Chain)(nil)
+)
+
+func newTestAggregator(t *testing.T, chain *fake.Chain) *Aggregator {
+	logger := logging.NewTextSLogger(io.Discard, nil)
+	aggregatorConfig := config.AggregatorConfig{BaseConfig: &config.BaseConfig{Logger: logger}}
+	aggregatorConfig.Aggregator.BlsServiceTaskTimeout = time.Minute
+
+	reg := prometheus.NewRegistry()
+	chainClients := ChainClients{
+		AvsReader:          chain,
+		AvsWriter:          chain,
+		AvsSubscriber:      chain,
+		AvsRegistryService: avsregistry.NewFakeAvsRegistryService(1, []eigentypes.TestOperator{}),
+	}
+	aggregator, err := NewAggregator(aggregatorConfig, chainClients, reg, metrics.NewMetrics("", reg, logger))
+	if err != nil {
+		t.Fatalf("Could not create aggregator: %v", err)
+	}
+	return aggregator
+}
+
+func waitForTaskState(t *testing.T, aggregator *Aggregator, taskIndex uint32, state TaskState) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, ok := aggregator.getTaskStatus(taskIndex); ok && status.State == state {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	status, _ := aggregator.getTaskStatus(taskIndex)
+	t.Fatalf("Expected task %d to be %v, got %v", taskIndex, state, status.State)
+}
+
+func TestAggregatorTracksNewAndRemovedBatches(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = aggregator.SubscribeToNewTasks(ctx) }()
+	// Wait for the subscription before submitting the batch
+	for deadline := time.Now().Add(5 * time.Second); chain.NewBatchV3Subscribers() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	senderAddress := common.HexToAddress("0x1")
+	batch := chain.SubmitBatch([32]byte{1}, senderAddress, "http://localhost/batch.json", big.NewInt(1e15))
+	waitForTaskState(t, aggregator, 0, TaskStatePending)
+
+	chain.RemoveBatch(fake.BatchIdentifierHash(batch.BatchMerkleRoot, batch.SenderAddress))
+	waitForTaskState(t, aggregator, 0, TaskStateRemoved)
+}
+
+func TestNewTaskSubscriptionResumesFromLatestBatch(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = aggregator.SubscribeToNewTasks(ctx) }()
+	for deadline := time.Now().Add(5 * time.Second); chain.NewBatchV3Subscribers() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	batch := chain.SubmitBatch([32]byte{1}, common.HexToAddress("0x1"), "http://localhost/batch.json", big.NewInt(1e15))
+	waitForTaskState(t, aggregator, 0, TaskStatePending)
+	chain.FailNewBatchV3Subscriptions(errors.New("subscription dropped"))
+	for deadline := time.Now().Add(5 * time.Second); chain.NewBatchV3Subscribers() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	fromBlocks := chain.NewBatchV3FromBlocks()
+	if len(fromBlocks) != 2 || fromBlocks[0] != 0 || fromBlocks[1] != batch.Raw.BlockNumber {
+		t.Errorf("Expected the new subscription to resume from block %d, got %v", batch.Raw.BlockNumber, fromBlocks)
+	}
+}
+
+func TestReleasedTaskIsFreed(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+
+	batch := chain.SubmitBatch([32]byte{1}, common.HexToAddress("0x1"), "http://localhost/batch.json", big.NewInt(1e15))
+	batchIdentifierHash := fake.BatchIdentifierHash(batch.BatchMerkleRoot, batch.SenderAddress)
+	aggregator.AddNewTask(batch.BatchMerkleRoot, batch.SenderAddress, batch.TaskCreatedBlock)
+	aggregator.ReleaseTask(batchIdentifierHash)
+
+	if len(aggregator.batchesIdxByIdentifierHash) != 0 || len(aggregator.batchesIdentifierHashByIdx) != 0 ||
+		len(aggregator.batchDataByIdentifierHash) != 0 || len(aggregator.batchCreatedBlockByIdx) != 0 || len(aggregator.taskStatusByIdx) != 0 {
+		t.Errorf("Expected every entry of the released task to be freed")
+	}
+
+	// The bls aggregation service still answers for the task once it expires, nothing is sent for it
+	aggregator.handleBlsAggServiceResponse(context.Background(), blsagg.BlsAggregationServiceResponse{TaskIndex: 0})
+	if calls := chain.RespondToTaskV2Calls(); len(calls) != 0 {
+		t.Errorf("Expected no aggregated response for the released task, got %d", len(calls))
+	}
+}
+
+func TestAggregatedResponseSentAgainAfterReorg(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+
+	senderAddress := common.HexToAddress("0x1")
+	batch := chain.SubmitBatch([32]byte{1}, senderAddress, "http://localhost/batch.json", big.NewInt(1e15))
+	batchIdentifierHash := fake.BatchIdentifierHash(batch.BatchMerkleRoot, batch.SenderAddress)
+	aggregator.AddNewTask(batch.BatchMerkleRoot, batch.SenderAddress, batch.TaskCreatedBlock)
+
+	nonSignerStakesAndSignature := servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{}
+	receipt, err := aggregator.sendAggregatedResponse(context.Background(), batchIdentifierHash, batch.BatchMerkleRoot, batch.SenderAddress, nonSignerStakesAndSignature)
+	if err != nil || receipt == nil {
+		t.Fatalf("Expected the aggregated response to be sent, got receipt %v and error %v", receipt, err)
+	}
+	response := &trackedResponse{
+		taskIndex:                   0,
+		batchIdentifierHash:         batchIdentifierHash,
+		batchMerkleRoot:             batch.BatchMerkleRoot,
+		senderAddress:               batch.SenderAddress,
+		nonSignerStakesAndSignature: nonSignerStakesAndSignature,
+		txHash:                      receipt.TxHash,
+	}
+	aggregator.responseTracker.add(response)
+
+	// Not final yet, and the transaction is still included
+	aggregator.checkTrackedResponse(context.Background(), response, 0)
+	if calls := chain.RespondToTaskV2Calls(); len(calls) != 1 {
+		t.Fatalf("Expected 1 respondToTaskV2 call, got %d", len(calls))
+	}
+
+	// A receipt that can not be read does not mean the transaction was dropped
+	chain.DropResponse(batchIdentifierHash)
+	chain.SetTransactionReceiptError(errors.New("connection refused"))
+	aggregator.checkTrackedResponse(context.Background(), response, 0)
+	if calls := chain.RespondToTaskV2Calls(); len(calls) != 1 {
+		t.Fatalf("Expected the response not to be sent again without its receipt, got %d respondToTaskV2 calls", len(calls))
+	}
+
+	chain.SetTransactionReceiptError(nil)
+	aggregator.checkTrackedResponse(context.Background(), response, 0)
+	calls := chain.RespondToTaskV2Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected the dropped response to be sent again, got %d respondToTaskV2 calls", len(calls))
+	}
+	if response.resends != 1 || response.txHash != calls[1].TxHash {
+		t.Errorf("Expected the tracked response to point to the new transaction, got %d resends and tx %v", response.resends, response.txHash)
+	}
+
+	chain.Finalize()
+	finalizedBlock, err := aggregator.getFinalizedBlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("Could not get finalized block: %v", err)
+	}
+	aggregator.checkTrackedResponse(context.Background(), response, finalizedBlock)
+	if len(aggregator.responseTracker.snapshot()) != 0 {
+		t.Errorf("Expected the final response to stop being tracked")
+	}
+	waitForTaskState(t, aggregator, 0, TaskStateFinalized)
+}
+
+func TestSignatureWithoutTaskStatusIsNotScored(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+
+	// The task is known but its status was already removed from the maps
+	batchIdentifierHash := [32]byte{1}
+	aggregator.batchesIdxByIdentifierHash[batchIdentifierHash] = 0
+
+	var reply uint8
+	signedTaskResponse := &types.SignedTaskResponse{
+		BatchIdentifierHash: batchIdentifierHash,
+		OperatorId:          eigentypes.OperatorId{1},
+	}
+	if err := aggregator.ProcessOperatorSignedTaskResponseV2(signedTaskResponse, &reply); err != nil {
+		t.Fatalf("Could not process the signature: %v", err)
+	}
+	if snapshot := aggregator.operatorScoreboard.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("Expected the signature not to be scored, got %+v", snapshot)
+	}
+}
+
+func TestLateSignatureIsTakenOutOfMissedTasks(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	operatorId := eigentypes.OperatorId{1}
+	aggregator.taskStatusByIdx[0] = &TaskStatus{State: TaskStateResponded}
+
+	aggregator.recordMissedOperator(0, operatorId)
+	aggregator.recordLateSignature(0, operatorId)
+	// The operator is only taken out of the missed tasks once
+	aggregator.recordLateSignature(0, operatorId)
+
+	snapshot := aggregator.operatorScoreboard.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].TasksMissed != 0 || snapshot[0].LateSignatures != 2 {
+		t.Errorf("Expected the late signer not to be counted as missing the task, got %+v", snapshot)
+	}
+}
+
+func TestSignatureOfFailedTaskIsNotLate(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+
+	batchIdentifierHash := [32]byte{1}
+	operatorId := eigentypes.OperatorId{1}
+	aggregator.batchesIdxByIdentifierHash[batchIdentifierHash] = 0
+	aggregator.taskStatusByIdx[0] = &TaskStatus{State: TaskStateFailed}
+	aggregator.recordMissedOperator(0, operatorId)
+
+	var reply uint8
+	signedTaskResponse := &types.SignedTaskResponse{
+		BatchIdentifierHash: batchIdentifierHash,
+		OperatorId:          operatorId,
+	}
+	if err := aggregator.ProcessOperatorSignedTaskResponseV2(signedTaskResponse, &reply); err != nil {
+		t.Fatalf("Could not process the signature: %v", err)
+	}
+	snapshot := aggregator.operatorScoreboard.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].TasksMissed != 1 || snapshot[0].LateSignatures != 0 {
+		t.Errorf("Expected the signature of the failed task not to be late, got %+v", snapshot)
+	}
+}
+
+func TestSignatureOfUnknownTaskStopsRetryingOnShutdown(t *testing.T) {
+	chain := fake.NewChain()
+	aggregator := newTestAggregator(t, chain)
+	ctx, cancel := context.WithCancel(context.Background())
+	aggregator.ctx = ctx
+
+	// The task index is retried for several seconds, waiting for the task to be created
+	var reply uint8
+	done := make(chan struct{})
+	go func() {
+		_ = aggregator.ProcessOperatorSignedTaskResponseV2(&types.SignedTaskResponse{BatchIdentifierHash: [32]byte{1}}, &reply)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the retries to stop once the context is cancelled")
+	}
+	if reply != 1 {
+		t.Errorf("Expected the signature to be rejected, got reply %d", reply)
+	}
+}
@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	sdkclients "github.com/Layr-Labs/eigensdk-go/chainio/clients"
+	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
+	oppubkeysserv "github.com/Layr-Labs/eigensdk-go/services/operatorsinfo"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// AvsReader is the part of chainio.AvsReader used by the aggregator
+type AvsReader interface {
+	GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byte, error)
+}
+
+// AvsWriter is the part of chainio.AvsWriter used by the aggregator
+type AvsWriter interface {
+	SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, onGasPriceBumped func(*big.Int)) (*gethtypes.Receipt, error)
+	BatchesStateRetryable(ctx context.Context, opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
+		TaskCreatedBlock      uint32
+		Responded             bool
+		RespondToTaskFeeLimit *big.Int
+	}, error)
+	BlockNumberRetryable(ctx context.Context, config *retry.RetryParams) (uint64, error)
+	HeaderByNumberRetryable(ctx context.Context, blockNumber *big.Int, config *retry.RetryParams) (*gethtypes.Header, error)
+	TransactionReceipt(ctx context.Context, txHash gethcommon.Hash) (*gethtypes.Receipt, error)
+}
+
+// AvsSubscriber is the part of chainio.AvsSubscriber used by the aggregator
+type AvsSubscriber interface {
+	SubscribeToNewTasksV3(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, fromBlock uint64) (chan error, error)
+	SubscribeToBatchVerified(ctx context.Context, batchVerifiedChan chan [32]byte) (chan error, error)
+	RemovedBatches() <-chan [32]byte
+	WaitForOneBlock(ctx context.Context, startBlock uint64) error
+}
+
+// ChainClients are the clients the aggregator reads from and writes to the chain with.
+// Tests may build them with the in-memory chain of core/chainio/fake
+type ChainClients struct {
+	AvsReader     AvsReader
+	AvsWriter     AvsWriter
+	AvsSubscriber AvsSubscriber
+	// Gives the stakes and public keys of the operators at the block of a task, for the BLS aggregation service
+	AvsRegistryService avsregistry.AvsRegistryService
+}
+
+// NewChainClientsFromConfig connects to the endpoints of the config.
+// aggregatorMetrics receives the gas the aggregator paid for the batchers
+func NewChainClientsFromConfig(aggregatorConfig config.AggregatorConfig, aggregatorMetrics *metrics.Metrics) (*ChainClients, error) {
+	logger := aggregatorConfig.BaseConfig.Logger
+
+	avsReader, err := chainio.NewAvsReaderFromConfig(aggregatorConfig.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	avsSubscriber, err := chainio.NewAvsSubscriberFromConfig(aggregatorConfig.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	avsWriter, err := chainio.NewAvsWriterFromConfig(aggregatorConfig.BaseConfig, aggregatorConfig.EcdsaConfig, aggregatorMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	chainioConfig := sdkclients.BuildAllConfig{
+		EthHttpUrl:                 aggregatorConfig.BaseConfig.EthRpcUrl,
+		EthWsUrl:                   aggregatorConfig.BaseConfig.EthWsUrlOrRpcUrl(),
+		RegistryCoordinatorAddr:    aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.Hex(),
+		OperatorStateRetrieverAddr: aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.Hex(),
+		AvsName:                    "AlignedLayer",
+		PromMetricsIpPortAddress:   ":9090",
+	}
+
+	clients, err := sdkclients.BuildReadClients(chainioConfig, logger)
+	if err != nil {
+		logger.Errorf("Cannot create sdk clients", "err", err)
+		return nil, err
+	}
+
+	// The registry subscriber of the sdk clients always uses a websocket client,
+	// so it is built again on top of the events backend, which may be polling over http
+	avsRegistryChainSubscriber, err := sdkavsregistry.BuildAvsRegistryChainSubscriber(
+		aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr,
+		aggregatorConfig.BaseConfig.EthEventsBackend, logger)
+	if err != nil {
+		logger.Errorf("Cannot create avs registry subscriber", "err", err)
+		return nil, err
+	}
+
+	operatorPubkeysService := oppubkeysserv.NewOperatorsInfoServiceInMemory(context.Background(), avsRegistryChainSubscriber, clients.AvsRegistryChainReader, nil, oppubkeysserv.Opts{}, logger)
+
+	return &ChainClients{
+		AvsReader:          avsReader,
+		AvsWriter:          avsWriter,
+		AvsSubscriber:      avsSubscriber,
+		AvsRegistryService: avsregistry.NewAvsRegistryServiceChainCaller(avsReader.ChainReader, operatorPubkeysService, logger),
+	}, nil
+}
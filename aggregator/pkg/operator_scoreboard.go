@@ -0,0 +1,240 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// Number of response latencies kept per operator to compute the median
+const OperatorLatencyWindowSize = 100
+
+type OperatorRecord struct {
+	TasksSigned    uint64
+	TasksMissed    uint64
+	LateSignatures uint64
+	LastSignedAt   time.Time
+	// Ring buffer with the last OperatorLatencyWindowSize response latencies
+	latencies    []time.Duration
+	nextLatency  int
+	latencyCount int
+}
+
+func (r *OperatorRecord) addLatency(latency time.Duration) {
+	if r.latencies == nil {
+		r.latencies = make([]time.Duration, OperatorLatencyWindowSize)
+	}
+	r.latencies[r.nextLatency] = latency
+	r.nextLatency = (r.nextLatency + 1) % OperatorLatencyWindowSize
+	if r.latencyCount < OperatorLatencyWindowSize {
+		r.latencyCount++
+	}
+}
+
+// MedianLatency returns the median of the last OperatorLatencyWindowSize response latencies
+func (r *OperatorRecord) MedianLatency() time.Duration {
+	if r.latencyCount == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, r.latencyCount)
+	copy(sorted, r.latencies[:r.latencyCount])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	middle := r.latencyCount / 2
+	if r.latencyCount%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// OperatorScoreboard keeps a per-operator record of the tasks signed and missed,
+// and the time they take to answer, so unreliable operators can be spotted.
+type OperatorScoreboard struct {
+	mutex   sync.Mutex
+	records map[eigentypes.OperatorId]*OperatorRecord
+	metrics *metrics.Metrics
+}
+
+func NewOperatorScoreboard(metrics *metrics.Metrics) *OperatorScoreboard {
+	return &OperatorScoreboard{
+		records: make(map[eigentypes.OperatorId]*OperatorRecord),
+		metrics: metrics,
+	}
+}
+
+// Must be called while holding the mutex
+func (s *OperatorScoreboard) record(operatorId eigentypes.OperatorId) *OperatorRecord {
+	record, ok := s.records[operatorId]
+	if !ok {
+		record = &OperatorRecord{}
+		s.records[operatorId] = record
+	}
+	return record
+}
+
+// RecordSignature registers a valid signature from an operator, and the time elapsed
+// since the aggregator received the NewBatchV3 event for the task.
+func (s *OperatorScoreboard) RecordSignature(operatorId eigentypes.OperatorId, latency time.Duration) {
+	s.mutex.Lock()
+	record := s.record(operatorId)
+	record.TasksSigned++
+	record.LastSignedAt = time.Now()
+	record.addLatency(latency)
+	median := record.MedianLatency()
+	s.mutex.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.IncOperatorTasksSigned(operatorIdLabel(operatorId))
+		s.metrics.SetOperatorMedianResponseLatency(operatorIdLabel(operatorId), median.Seconds())
+	}
+}
+
+// RecordMissed registers an operator that was part of the quorum but did not sign the task
+func (s *OperatorScoreboard) RecordMissed(operatorId eigentypes.OperatorId) {
+	s.mutex.Lock()
+	record := s.record(operatorId)
+	record.TasksMissed++
+	tasksMissed := record.TasksMissed
+	s.mutex.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.SetOperatorTasksMissed(operatorIdLabel(operatorId), tasksMissed)
+	}
+}
+
+// RecordLateSignature registers a signature that arrived after the task had already reached quorum.
+// If the operator was already recorded as missing the task, it is taken out of the missed ones
+func (s *OperatorScoreboard) RecordLateSignature(operatorId eigentypes.OperatorId, recordedMissed bool) {
+	s.mutex.Lock()
+	record := s.record(operatorId)
+	record.LateSignatures++
+	if recordedMissed && record.TasksMissed > 0 {
+		record.TasksMissed--
+	}
+	tasksMissed := record.TasksMissed
+	s.mutex.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.IncOperatorLateSignatures(operatorIdLabel(operatorId))
+		s.metrics.SetOperatorTasksMissed(operatorIdLabel(operatorId), tasksMissed)
+	}
+}
+
+type OperatorRecordResponse struct {
+	OperatorId            string  `json:"operator_id"`
+	TasksSigned           uint64  `json:"tasks_signed"`
+	TasksMissed           uint64  `json:"tasks_missed"`
+	LateSignatures        uint64  `json:"late_signatures"`
+	MedianResponseLatency float64 `json:"median_response_latency_seconds"`
+	LastSignedAt          string  `json:"last_signed_at,omitempty"`
+}
+
+// Snapshot returns a copy of every operator record, ordered by operator id
+func (s *OperatorScoreboard) Snapshot() []OperatorRecordResponse {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := make([]OperatorRecordResponse, 0, len(s.records))
+	for operatorId, record := range s.records {
+		lastSignedAt := ""
+		if !record.LastSignedAt.IsZero() {
+			lastSignedAt = record.LastSignedAt.UTC().Format(time.RFC3339)
+		}
+		snapshot = append(snapshot, OperatorRecordResponse{
+			OperatorId:            operatorIdLabel(operatorId),
+			TasksSigned:           record.TasksSigned,
+			TasksMissed:           record.TasksMissed,
+			LateSignatures:        record.LateSignatures,
+			MedianResponseLatency: record.MedianLatency().Seconds(),
+			LastSignedAt:          lastSignedAt,
+		})
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].OperatorId < snapshot[j].OperatorId })
+
+	return snapshot
+}
+
+func operatorIdLabel(operatorId eigentypes.OperatorId) string {
+	return "0x" + hex.EncodeToString(operatorId[:])
+}
+
+// recordMissedOperatorsFromTaskState is used when a task did not reach quorum, so there is no list of non signers.
+// Every operator registered at the task created block that did not sign is considered to have missed it.
+// It does not block: the stakes cached by the task are used, and they are fetched in the background otherwise.
+func (agg *Aggregator) recordMissedOperatorsFromTaskState(ctx context.Context, taskIndex uint32) {
+	agg.taskMutex.Lock()
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	if !ok {
+		agg.taskMutex.Unlock()
+		return
+	}
+	taskCreatedBlock := status.TaskCreatedBlock
+	operatorsAvsState := status.operatorsAvsState
+	signers := make(map[eigentypes.OperatorId]struct{}, len(status.SignersOperatorIds))
+	for _, operatorId := range status.SignersOperatorIds {
+		signers[operatorId] = struct{}{}
+	}
+	agg.taskMutex.Unlock()
+
+	recordMissed := func(operatorsAvsState map[eigentypes.OperatorId]eigentypes.OperatorAvsState) {
+		for operatorId := range operatorsAvsState {
+			if _, signed := signers[operatorId]; !signed {
+				agg.recordMissedOperator(taskIndex, operatorId)
+			}
+		}
+	}
+
+	if operatorsAvsState != nil {
+		recordMissed(operatorsAvsState)
+		return
+	}
+
+	go func() {
+		quorumNums := eigentypes.QuorumNums{eigentypes.QuorumNum(QUORUM_NUMBER)}
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		operatorsAvsState, err := agg.avsRegistryService.GetOperatorsAvsStateAtBlock(ctx, quorumNums, taskCreatedBlock)
+		if err != nil {
+			agg.logger.Warn("Could not get operators state to record missed tasks", "taskIndex", taskIndex, "err", err)
+			return
+		}
+		recordMissed(operatorsAvsState)
+	}()
+}
+
+// recordMissedOperator registers that the operator did not sign the task, remembering it in the task status so a
+// late signature of the operator takes it out of the missed ones
+func (agg *Aggregator) recordMissedOperator(taskIndex uint32, operatorId eigentypes.OperatorId) {
+	agg.taskMutex.Lock()
+	agg.updateTaskStatus(taskIndex, func(status *TaskStatus) {
+		if status.missedOperatorIds == nil {
+			status.missedOperatorIds = make(map[eigentypes.OperatorId]struct{})
+		}
+		status.missedOperatorIds[operatorId] = struct{}{}
+	})
+	agg.taskMutex.Unlock()
+
+	agg.operatorScoreboard.RecordMissed(operatorId)
+}
+
+// recordLateSignature registers the signature of the operator as late if the task was already responded.
+// The signatures of the tasks that failed, or were released or removed, are not late: the task never reached quorum
+// or it was not the aggregator that responded it
+func (agg *Aggregator) recordLateSignature(taskIndex uint32, operatorId eigentypes.OperatorId) {
+	agg.taskMutex.Lock()
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	if !ok || (status.State != TaskStateResponded && status.State != TaskStateFinalized) {
+		agg.taskMutex.Unlock()
+		return
+	}
+	_, recordedMissed := status.missedOperatorIds[operatorId]
+	delete(status.missedOperatorIds, operatorId)
+	agg.taskMutex.Unlock()
+
+	agg.operatorScoreboard.RecordLateSignature(operatorId, recordedMissed)
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+func TestOperatorRecordMedianLatency(t *testing.T) {
+	record := OperatorRecord{}
+	if record.MedianLatency() != 0 {
+		t.Errorf("Expected median of an empty record to be 0, got %v", record.MedianLatency())
+	}
+
+	for _, latency := range []time.Duration{3 * time.Second, 1 * time.Second, 2 * time.Second} {
+		record.addLatency(latency)
+	}
+	if record.MedianLatency() != 2*time.Second {
+		t.Errorf("Expected median 2s, got %v", record.MedianLatency())
+	}
+
+	record.addLatency(4 * time.Second)
+	if record.MedianLatency() != 2500*time.Millisecond {
+		t.Errorf("Expected median 2.5s, got %v", record.MedianLatency())
+	}
+}
+
+func TestOperatorRecordLatencyWindow(t *testing.T) {
+	record := OperatorRecord{}
+	// Fill the window with slow responses and then replace all of them with fast ones
+	for i := 0; i < OperatorLatencyWindowSize; i++ {
+		record.addLatency(time.Minute)
+	}
+	for i := 0; i < OperatorLatencyWindowSize; i++ {
+		record.addLatency(time.Second)
+	}
+	if record.MedianLatency() != time.Second {
+		t.Errorf("Expected old latencies to be out of the window, got median %v", record.MedianLatency())
+	}
+}
+
+func TestOperatorScoreboardSnapshot(t *testing.T) {
+	scoreboard := NewOperatorScoreboard(nil)
+	operatorA := eigentypes.OperatorId{1}
+	operatorB := eigentypes.OperatorId{2}
+
+	scoreboard.RecordSignature(operatorA, time.Second)
+	scoreboard.RecordSignature(operatorA, 3*time.Second)
+	scoreboard.RecordMissed(operatorB)
+	scoreboard.RecordMissed(operatorB)
+	// The late signature is for one of the missed tasks
+	scoreboard.RecordLateSignature(operatorB, true)
+
+	snapshot := scoreboard.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected 2 operators in the snapshot, got %d", len(snapshot))
+	}
+	if snapshot[0].TasksSigned != 2 || snapshot[0].TasksMissed != 0 || snapshot[0].MedianResponseLatency != 2 {
+		t.Errorf("Unexpected record for operator A: %+v", snapshot[0])
+	}
+	if snapshot[1].TasksSigned != 0 || snapshot[1].TasksMissed != 1 || snapshot[1].LateSignatures != 1 {
+		t.Errorf("Unexpected record for operator B: %+v", snapshot[1])
+	}
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// Settings returns the reloadable settings in use. They are swapped as a whole when the config file is reloaded
+func (agg *Aggregator) Settings() *config.AggregatorSettings {
+	return agg.settings.Load()
+}
+
+// ReloadConfig re-reads the config file with reloader, swapping the reloadable settings and logging what changed.
+// The running config is kept if the file is invalid or a field that can not be reloaded changed
+func (agg *Aggregator) ReloadConfig(reloader *config.AggregatorReloader) {
+	changes, err := reloader.Reload(func(environment sdklogging.LogLevel, settings *config.AggregatorSettings) error {
+		if logger, ok := agg.logger.(*config.ReloadableLogger); ok {
+			if err := logger.SetEnvironment(environment); err != nil {
+				return err
+			}
+		}
+		agg.settings.Store(settings)
+		return nil
+	})
+	if err != nil {
+		agg.logger.Error("Config reload rejected, keeping the running config", "err", err)
+		return
+	}
+	if len(changes) == 0 {
+		agg.logger.Info("Config reloaded, nothing changed")
+		return
+	}
+	for _, change := range changes {
+		agg.logger.Info("Config field reloaded", "field", change.Field, "old", change.Old, "new", change.New)
+	}
+}
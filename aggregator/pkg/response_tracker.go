@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+const (
+	DefaultResponseTrackerPeriod = 30 * time.Second
+	// Times an aggregated response is sent again after being dropped by a reorg before giving up on it
+	MaxResponseResends = 3
+)
+
+// trackedResponse holds everything needed to send an aggregated response again
+type trackedResponse struct {
+	taskIndex                   uint32
+	batchIdentifierHash         [32]byte
+	batchMerkleRoot             [32]byte
+	senderAddress               [20]byte
+	nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature
+	txHash                      gethcommon.Hash
+	resends                     int
+}
+
+// ResponseTracker keeps the aggregated responses that have been sent but are not final yet
+type ResponseTracker struct {
+	mutex     sync.Mutex
+	responses map[[32]byte]*trackedResponse
+}
+
+func NewResponseTracker() *ResponseTracker {
+	return &ResponseTracker{
+		responses: make(map[[32]byte]*trackedResponse),
+	}
+}
+
+func (t *ResponseTracker) add(response *trackedResponse) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.responses[response.batchIdentifierHash] = response
+}
+
+func (t *ResponseTracker) remove(batchIdentifierHash [32]byte) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.responses, batchIdentifierHash)
+}
+
+func (t *ResponseTracker) snapshot() []*trackedResponse {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	responses := make([]*trackedResponse, 0, len(t.responses))
+	for _, response := range t.responses {
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// Long-lived goroutine that watches the sent aggregated responses until they are final.
+// A response is final once the batch is marked as responded at the finalized block, which is either
+// the one with the 'finalized' tag or the latest block minus ResponseFinalityDepth.
+// If the transaction is dropped by a reorg and the batch is not responded anymore, the response is sent again.
+// It returns once ctx is done.
+func (agg *Aggregator) TrackAggregatedResponses(ctx context.Context) {
+	defer func() {
+		err := recover() //stops panics
+		if err != nil {
+			agg.logger.Error("TrackAggregatedResponses recovered from panic", "err", err)
+		}
+	}()
+
+	period := agg.AggregatorConfig.Aggregator.ResponseTrackerPeriod
+	if period == 0 {
+		period = DefaultResponseTrackerPeriod
+	}
+	agg.logger.Info("Tracking aggregated responses until finality", "period", period,
+		"finalityDepth", agg.AggregatorConfig.Aggregator.ResponseFinalityDepth)
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		responses := agg.responseTracker.snapshot()
+		if len(responses) == 0 {
+			continue
+		}
+
+		finalizedBlock, err := agg.getFinalizedBlockNumber(ctx)
+		if err != nil {
+			agg.logger.Warn("Could not get finalized block, skipping aggregated responses check", "err", err)
+			continue
+		}
+
+		for _, response := range responses {
+			agg.checkTrackedResponse(ctx, response, finalizedBlock)
+		}
+	}
+}
+
+func (agg *Aggregator) getFinalizedBlockNumber(ctx context.Context) (uint64, error) {
+	finalityDepth := agg.AggregatorConfig.Aggregator.ResponseFinalityDepth
+	if finalityDepth == 0 {
+		header, err := agg.avsWriter.HeaderByNumberRetryable(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)), retry.NetworkRetryParams())
+		if err != nil {
+			return 0, err
+		}
+		return header.Number.Uint64(), nil
+	}
+
+	latestBlock, err := agg.avsWriter.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
+	if err != nil {
+		return 0, err
+	}
+	if latestBlock < finalityDepth {
+		return 0, nil
+	}
+	return latestBlock - finalityDepth, nil
+}
+
+func (agg *Aggregator) checkTrackedResponse(ctx context.Context, response *trackedResponse, finalizedBlock uint64) {
+	batchIdentifierHashHex := "0x" + hex.EncodeToString(response.batchIdentifierHash[:])
+
+	finalizedState, err := agg.avsWriter.BatchesStateRetryable(ctx, &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(finalizedBlock)}, response.batchIdentifierHash, retry.NetworkRetryParams())
+	if err != nil {
+		agg.logger.Warn("Could not get finalized batch state", "batchIdentifierHash", batchIdentifierHashHex, "err", err)
+		return
+	}
+	if finalizedState.Responded {
+		agg.logger.Info("Aggregated response is final", "taskIndex", response.taskIndex,
+			"batchIdentifierHash", batchIdentifierHashHex, "txHash", response.txHash.String())
+		agg.recordTaskState(response.taskIndex, TaskStateFinalized, "", nil)
+		agg.responseTracker.remove(response.batchIdentifierHash)
+		return
+	}
+
+	// If the transaction still has a receipt, it is just waiting to be finalized. Only a missing receipt means it
+	// was dropped, the response is checked again on the next tick if the receipt could not be read
+	receipt, err := agg.avsWriter.TransactionReceipt(ctx, response.txHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		agg.logger.Warn("Could not get aggregated response receipt", "batchIdentifierHash", batchIdentifierHashHex,
+			"txHash", response.txHash.String(), "err", err)
+		return
+	}
+	if receipt != nil {
+		return
+	}
+
+	// The batch may have been responded by another transaction
+	latestState, err := agg.avsWriter.BatchesStateRetryable(ctx, &bind.CallOpts{}, response.batchIdentifierHash, retry.NetworkRetryParams())
+	if err != nil {
+		agg.logger.Warn("Could not get batch state", "batchIdentifierHash", batchIdentifierHashHex, "err", err)
+		return
+	}
+	if latestState.Responded {
+		return
+	}
+
+	if response.resends >= MaxResponseResends {
+		agg.logger.Error("Aggregated response was dropped too many times, giving up on it",
+			"taskIndex", response.taskIndex, "batchIdentifierHash", batchIdentifierHashHex, "resends", response.resends)
+		agg.recordTaskState(response.taskIndex, TaskStateFailed, "", nil)
+		agg.responseTracker.remove(response.batchIdentifierHash)
+		return
+	}
+
+	agg.logger.Warn("Aggregated response transaction was dropped by a reorg, sending it again",
+		"taskIndex", response.taskIndex, "batchIdentifierHash", batchIdentifierHashHex, "droppedTxHash", response.txHash.String())
+	agg.metrics.IncResentAggregatedResponses()
+	response.resends++
+
+	receipt, err = agg.sendAggregatedResponse(ctx, response.batchIdentifierHash, response.batchMerkleRoot, response.senderAddress, response.nonSignerStakesAndSignature)
+	if err != nil {
+		agg.logger.Error("Could not send aggregated response again, will retry", "taskIndex", response.taskIndex,
+			"batchIdentifierHash", batchIdentifierHashHex, "err", err)
+		return
+	}
+	if receipt != nil {
+		response.txHash = receipt.TxHash
+		agg.recordTaskState(response.taskIndex, TaskStateResponded, receipt.TxHash.String(), nil)
+	}
+	agg.logger.Info("Aggregated response sent again", "taskIndex", response.taskIndex,
+		"batchIdentifierHash", batchIdentifierHashHex, "txHash", response.txHash.String())
+}
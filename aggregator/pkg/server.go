@@ -54,7 +54,7 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 	// If that's the case, we won't know about the task at this point
 	// so we make GetTaskIndex retryable, waiting for some seconds,
 	// before trying to fetch the task again from the map.
-	taskIndex, err := agg.GetTaskIndexRetryable(signedTaskResponse.BatchIdentifierHash, retry.NetworkRetryParams())
+	taskIndex, err := agg.GetTaskIndexRetryable(agg.ctx, signedTaskResponse.BatchIdentifierHash, retry.NetworkRetryParams())
 
 	if err != nil {
 		agg.logger.Warn("Task not found in the internal map, operator signature will be lost. Batch may not reach quorum")
@@ -63,9 +63,15 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 	}
 	agg.telemetry.LogOperatorResponse(signedTaskResponse.BatchMerkleRoot, signedTaskResponse.OperatorId)
 
+	// The status is missing when the task was already removed from the maps, the signature is not scored then
+	taskStatus, taskStatusExists := agg.getTaskStatus(taskIndex)
+	if taskStatusExists {
+		agg.recordLateSignature(taskIndex, signedTaskResponse.OperatorId)
+	}
+
 	// Don't wait infinitely if it can't answer
 	// Create a context with a timeout of 5 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(agg.ctx, 5*time.Second)
 	defer cancel() // Ensure the cancel function is called to release resources
 
 	// Create a channel to signal when the task is done
@@ -73,8 +79,11 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 
 	agg.logger.Info("Starting bls signature process")
 	go func() {
+		// The signature is only useful until the task expires
+		taskCtx, cancel := agg.taskContext(agg.ctx, taskIndex)
+		defer cancel()
 		err := agg.blsAggregationService.ProcessNewSignature(
-			context.Background(), taskIndex, signedTaskResponse.BatchIdentifierHash,
+			taskCtx, taskIndex, signedTaskResponse.BatchIdentifierHash,
 			&signedTaskResponse.BlsSignature, signedTaskResponse.OperatorId,
 		)
 
@@ -83,6 +92,10 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 			// todo shouldn't we here close the channel with a reply = 1?
 		} else {
 			agg.logger.Info("BLS process succeeded")
+			agg.recordTaskSignature(taskIndex, signedTaskResponse.OperatorId)
+			if taskStatusExists {
+				agg.operatorScoreboard.RecordSignature(signedTaskResponse.OperatorId, time.Since(taskStatus.CreatedAt))
+			}
 		}
 
 		close(done)
@@ -116,8 +129,8 @@ Checks Internal mapping for Signed Task Response, returns its TaskIndex.
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec
 TODO: We should refactor the retry duration considering extending it to a larger time or number of retries, at least somewhere between 1 and 2 blocks
 */
-func (agg *Aggregator) GetTaskIndexRetryable(batchIdentifierHash [32]byte, config *retry.RetryParams) (uint32, error) {
-	getTaskIndex_func := func() (uint32, error) {
+func (agg *Aggregator) GetTaskIndexRetryable(ctx context.Context, batchIdentifierHash [32]byte, config *retry.RetryParams) (uint32, error) {
+	getTaskIndex_func := func(context.Context) (uint32, error) {
 		agg.taskMutex.Lock()
 		taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
 		agg.taskMutex.Unlock()
@@ -128,5 +141,5 @@ func (agg *Aggregator) GetTaskIndexRetryable(batchIdentifierHash [32]byte, confi
 		}
 	}
 
-	return retry.RetryWithData(getTaskIndex_func, config)
+	return retry.RetryWithDataContext(ctx, "GetTaskIndex", getTaskIndex_func, config)
 }
@@ -1,30 +1,51 @@
 package pkg
 
-func (agg *Aggregator) SubscribeToNewTasks() error {
-	err := agg.subscribeToNewTasks()
+import "context"
+
+// SubscribeToNewTasks adds the new batches as tasks, and releases or removes them when they are verified or
+// reorged out. It returns nil once ctx is done
+func (agg *Aggregator) SubscribeToNewTasks(ctx context.Context) error {
+	err := agg.subscribeToNewTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	verifiedBatchSubscriber, err := agg.avsSubscriber.SubscribeToBatchVerified(ctx, agg.VerifiedBatchChan)
 	if err != nil {
 		return err
 	}
 
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case err := <-agg.taskSubscriber:
 			agg.AggregatorConfig.BaseConfig.Logger.Info("Failed to subscribe to new tasks", "err", err)
-			err = agg.subscribeToNewTasks()
+			err = agg.subscribeToNewTasks(ctx)
 			if err != nil {
 				return err
 			}
 		case newBatch := <-agg.NewBatchChan:
+			agg.lastNewBatchBlock = max(agg.lastNewBatchBlock, newBatch.Raw.BlockNumber)
 			agg.AggregatorConfig.BaseConfig.Logger.Info("Adding new task")
 			agg.AddNewTask(newBatch.BatchMerkleRoot, newBatch.SenderAddress, newBatch.TaskCreatedBlock)
+		case batchIdentifierHash := <-agg.avsSubscriber.RemovedBatches():
+			agg.RemoveTask(batchIdentifierHash)
+		case batchIdentifierHash := <-agg.VerifiedBatchChan:
+			agg.ReleaseTask(batchIdentifierHash)
+		case err := <-verifiedBatchSubscriber:
+			agg.AggregatorConfig.BaseConfig.Logger.Warn("Could not renew verified batches subscription", "err", err)
 		}
 	}
 }
 
-func (agg *Aggregator) subscribeToNewTasks() error {
+func (agg *Aggregator) subscribeToNewTasks(ctx context.Context) error {
 	var err error
 
-	agg.taskSubscriber, err = agg.avsSubscriber.SubscribeToNewTasksV3(agg.NewBatchChan)
+	// A new subscription resumes from the block of the latest batch received, so the batches created while it was
+	// down are backfilled. On startup it is 0, and only the latest blocks are scanned, as older tasks would expire
+	// before reaching quorum
+	agg.taskSubscriber, err = agg.avsSubscriber.SubscribeToNewTasksV3(ctx, agg.NewBatchChan, agg.lastNewBatchBlock)
 
 	if err != nil {
 		agg.AggregatorConfig.BaseConfig.Logger.Info("Failed to create task subscriber", "err", err)
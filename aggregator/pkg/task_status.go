@@ -0,0 +1,192 @@
+package pkg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+type TaskState string
+
+const (
+	TaskStatePending       TaskState = "pending"
+	TaskStateQuorumReached TaskState = "quorum_reached"
+	TaskStateResponded     TaskState = "responded"
+	TaskStateFailed        TaskState = "failed"
+	// The aggregated response of the task is part of a finalized block
+	TaskStateFinalized TaskState = "finalized"
+	// The NewBatchV3 log of the task was removed by a reorg
+	TaskStateRemoved TaskState = "removed"
+)
+
+// TaskStatus keeps track of what happened to a task while it lives in the aggregator maps.
+// It is only used to inspect the aggregator from the outside, it is never read to take decisions.
+type TaskStatus struct {
+	TaskIndex           uint32
+	BatchIdentifierHash [32]byte
+	TaskCreatedBlock    uint32
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	State               TaskState
+	SignersOperatorIds  []eigentypes.OperatorId
+	TxHash              string
+	Err                 string
+
+	// Stake of every operator at TaskCreatedBlock.
+	// It is fetched the first time it is needed, as it can't change for a given block.
+	operatorsAvsState map[eigentypes.OperatorId]eigentypes.OperatorAvsState
+	quorumsAvsState   map[eigentypes.QuorumNum]eigentypes.QuorumAvsState
+	// Operators recorded as missing the task, see recordMissedOperator
+	missedOperatorIds map[eigentypes.OperatorId]struct{}
+}
+
+// The following functions must be called while holding the taskMutex
+
+func (agg *Aggregator) initTaskStatus(taskIndex uint32, batchIdentifierHash [32]byte, taskCreatedBlock uint32) {
+	now := time.Now()
+	agg.taskStatusByIdx[taskIndex] = &TaskStatus{
+		TaskIndex:           taskIndex,
+		BatchIdentifierHash: batchIdentifierHash,
+		TaskCreatedBlock:    taskCreatedBlock,
+		CreatedAt:           now,
+		ExpiresAt:           now.Add(agg.Settings().BlsServiceTaskTimeout),
+		State:               TaskStatePending,
+		SignersOperatorIds:  []eigentypes.OperatorId{},
+	}
+}
+
+func (agg *Aggregator) updateTaskStatus(taskIndex uint32, update func(status *TaskStatus)) {
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	if !ok {
+		return
+	}
+	update(status)
+}
+
+// Helpers that take the lock themselves, used from the places where the lock is not already held
+
+// taskContext returns a context for the calls made on behalf of a task, which is done once the task expires.
+// If the task is not in the maps anymore, it gets a whole BlsServiceTaskTimeout
+func (agg *Aggregator) taskContext(ctx context.Context, taskIndex uint32) (context.Context, context.CancelFunc) {
+	status, ok := agg.getTaskStatus(taskIndex)
+	if !ok {
+		return context.WithTimeout(ctx, agg.Settings().BlsServiceTaskTimeout)
+	}
+	return context.WithDeadline(ctx, status.ExpiresAt)
+}
+
+// getTaskStatus returns a copy of the status of the task, without the cached stakes
+func (agg *Aggregator) getTaskStatus(taskIndex uint32) (TaskStatus, bool) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	if !ok {
+		return TaskStatus{}, false
+	}
+	statusCopy := *status
+	statusCopy.SignersOperatorIds = append([]eigentypes.OperatorId{}, status.SignersOperatorIds...)
+	statusCopy.operatorsAvsState = nil
+	statusCopy.quorumsAvsState = nil
+	statusCopy.missedOperatorIds = nil
+	return statusCopy, true
+}
+
+// GetTaskStatusByBatchIdentifierHash returns a copy of the status of the task of a batch, without the cached stakes
+func (agg *Aggregator) GetTaskStatusByBatchIdentifierHash(batchIdentifierHash [32]byte) (TaskStatus, bool) {
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	agg.taskMutex.Unlock()
+	if !ok {
+		return TaskStatus{}, false
+	}
+	return agg.getTaskStatus(taskIndex)
+}
+
+func (agg *Aggregator) recordTaskSignature(taskIndex uint32, operatorId eigentypes.OperatorId) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	agg.updateTaskStatus(taskIndex, func(status *TaskStatus) {
+		status.SignersOperatorIds = append(status.SignersOperatorIds, operatorId)
+	})
+}
+
+func (agg *Aggregator) recordTaskState(taskIndex uint32, state TaskState, txHash string, err error) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	agg.updateTaskStatus(taskIndex, func(status *TaskStatus) {
+		status.State = state
+		if txHash != "" {
+			status.TxHash = txHash
+		}
+		if err != nil {
+			status.Err = err.Error()
+		}
+	})
+}
+
+// signedStakePercentages returns, for every quorum of the task, the percentage of the total stake
+// that has already signed it.
+func (agg *Aggregator) signedStakePercentages(ctx context.Context, taskIndex uint32) (map[eigentypes.QuorumNum]float64, error) {
+	agg.taskMutex.Lock()
+	status, ok := agg.taskStatusByIdx[taskIndex]
+	if !ok {
+		agg.taskMutex.Unlock()
+		return nil, nil
+	}
+	taskCreatedBlock := status.TaskCreatedBlock
+	operatorsAvsState := status.operatorsAvsState
+	quorumsAvsState := status.quorumsAvsState
+	signers := append([]eigentypes.OperatorId{}, status.SignersOperatorIds...)
+	agg.taskMutex.Unlock()
+
+	quorumNums := eigentypes.QuorumNums{eigentypes.QuorumNum(QUORUM_NUMBER)}
+
+	if operatorsAvsState == nil || quorumsAvsState == nil {
+		var err error
+		operatorsAvsState, err = agg.avsRegistryService.GetOperatorsAvsStateAtBlock(ctx, quorumNums, taskCreatedBlock)
+		if err != nil {
+			return nil, err
+		}
+		quorumsAvsState, err = agg.avsRegistryService.GetQuorumsAvsStateAtBlock(ctx, quorumNums, taskCreatedBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		agg.taskMutex.Lock()
+		agg.updateTaskStatus(taskIndex, func(status *TaskStatus) {
+			status.operatorsAvsState = operatorsAvsState
+			status.quorumsAvsState = quorumsAvsState
+		})
+		agg.taskMutex.Unlock()
+	}
+
+	percentages := make(map[eigentypes.QuorumNum]float64)
+	for _, quorumNum := range quorumNums {
+		quorumAvsState, ok := quorumsAvsState[quorumNum]
+		if !ok || quorumAvsState.TotalStake == nil || quorumAvsState.TotalStake.Sign() == 0 {
+			percentages[quorumNum] = 0
+			continue
+		}
+
+		signedStake := big.NewInt(0)
+		for _, operatorId := range signers {
+			operatorAvsState, ok := operatorsAvsState[operatorId]
+			if !ok {
+				continue
+			}
+			if stake, ok := operatorAvsState.StakePerQuorum[quorumNum]; ok && stake != nil {
+				signedStake.Add(signedStake, stake)
+			}
+		}
+
+		percentage, _ := new(big.Float).Quo(
+			new(big.Float).Mul(new(big.Float).SetInt(signedStake), big.NewFloat(100)),
+			new(big.Float).SetInt(quorumAvsState.TotalStake),
+		).Float64()
+		percentages[quorumNum] = percentage
+	}
+
+	return percentages, nil
+}
@@ -0,0 +1,215 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contractMulticall3
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// Multicall3Call3 is an auto generated low-level Go binding around an user-defined struct.
+type Multicall3Call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Multicall3Result is an auto generated low-level Go binding around an user-defined struct.
+type Multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// ContractMulticall3MetaData contains all meta data concerning the ContractMulticall3 contract.
+var ContractMulticall3MetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"aggregate3\",\"inputs\":[{\"name\":\"calls\",\"type\":\"tuple[]\",\"internalType\":\"structMulticall3.Call3[]\",\"components\":[{\"name\":\"target\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"allowFailure\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"callData\",\"type\":\"bytes\",\"internalType\":\"bytes\"}]}],\"outputs\":[{\"name\":\"returnData\",\"type\":\"tuple[]\",\"internalType\":\"structMulticall3.Result[]\",\"components\":[{\"name\":\"success\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"returnData\",\"type\":\"bytes\",\"internalType\":\"bytes\"}]}],\"stateMutability\":\"payable\"}]",
+}
+
+// ContractMulticall3ABI is the input ABI used to generate the binding from.
+// Deprecated: Use ContractMulticall3MetaData.ABI instead.
+var ContractMulticall3ABI = ContractMulticall3MetaData.ABI
+
+// ContractMulticall3 is an auto generated Go binding around an Ethereum contract.
+type ContractMulticall3 struct {
+	ContractMulticall3Caller     // Read-only binding to the contract
+	ContractMulticall3Transactor // Write-only binding to the contract
+	ContractMulticall3Filterer   // Log filterer for contract events
+}
+
+// ContractMulticall3Caller is an auto generated read-only Go binding around an Ethereum contract.
+type ContractMulticall3Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ContractMulticall3Transactor is an auto generated write-only Go binding around an Ethereum contract.
+type ContractMulticall3Transactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ContractMulticall3Filterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ContractMulticall3Filterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ContractMulticall3Session is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ContractMulticall3Session struct {
+	Contract     *ContractMulticall3 // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts       // Call options to use throughout this session
+	TransactOpts bind.TransactOpts   // Transaction auth options to use throughout this session
+}
+
+// ContractMulticall3CallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ContractMulticall3CallerSession struct {
+	Contract *ContractMulticall3Caller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts             // Call options to use throughout this session
+}
+
+// ContractMulticall3TransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ContractMulticall3TransactorSession struct {
+	Contract     *ContractMulticall3Transactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts             // Transaction auth options to use throughout this session
+}
+
+// ContractMulticall3Raw is an auto generated low-level Go binding around an Ethereum contract.
+type ContractMulticall3Raw struct {
+	Contract *ContractMulticall3 // Generic contract binding to access the raw methods on
+}
+
+// ContractMulticall3CallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ContractMulticall3CallerRaw struct {
+	Contract *ContractMulticall3Caller // Generic read-only contract binding to access the raw methods on
+}
+
+// ContractMulticall3TransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ContractMulticall3TransactorRaw struct {
+	Contract *ContractMulticall3Transactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewContractMulticall3 creates a new instance of ContractMulticall3, bound to a specific deployed contract.
+func NewContractMulticall3(address common.Address, backend bind.ContractBackend) (*ContractMulticall3, error) {
+	contract, err := bindContractMulticall3(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractMulticall3{ContractMulticall3Caller: ContractMulticall3Caller{contract: contract}, ContractMulticall3Transactor: ContractMulticall3Transactor{contract: contract}, ContractMulticall3Filterer: ContractMulticall3Filterer{contract: contract}}, nil
+}
+
+// NewContractMulticall3Caller creates a new read-only instance of ContractMulticall3, bound to a specific deployed contract.
+func NewContractMulticall3Caller(address common.Address, caller bind.ContractCaller) (*ContractMulticall3Caller, error) {
+	contract, err := bindContractMulticall3(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractMulticall3Caller{contract: contract}, nil
+}
+
+// NewContractMulticall3Transactor creates a new write-only instance of ContractMulticall3, bound to a specific deployed contract.
+func NewContractMulticall3Transactor(address common.Address, transactor bind.ContractTransactor) (*ContractMulticall3Transactor, error) {
+	contract, err := bindContractMulticall3(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractMulticall3Transactor{contract: contract}, nil
+}
+
+// NewContractMulticall3Filterer creates a new log filterer instance of ContractMulticall3, bound to a specific deployed contract.
+func NewContractMulticall3Filterer(address common.Address, filterer bind.ContractFilterer) (*ContractMulticall3Filterer, error) {
+	contract, err := bindContractMulticall3(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractMulticall3Filterer{contract: contract}, nil
+}
+
+// bindContractMulticall3 binds a generic wrapper to an already deployed contract.
+func bindContractMulticall3(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := ContractMulticall3MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ContractMulticall3 *ContractMulticall3Raw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ContractMulticall3.Contract.ContractMulticall3Caller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ContractMulticall3 *ContractMulticall3Raw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.ContractMulticall3Transactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ContractMulticall3 *ContractMulticall3Raw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.ContractMulticall3Transactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ContractMulticall3 *ContractMulticall3CallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ContractMulticall3.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ContractMulticall3 *ContractMulticall3TransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ContractMulticall3 *ContractMulticall3TransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.contract.Transact(opts, method, params...)
+}
+
+// Aggregate3 is a paid mutator transaction binding the contract method 0x82ad56cb.
+//
+// Solidity: function aggregate3((address,bool,bytes)[] calls) payable returns((bool,bytes)[] returnData)
+func (_ContractMulticall3 *ContractMulticall3Transactor) Aggregate3(opts *bind.TransactOpts, calls []Multicall3Call3) (*types.Transaction, error) {
+	return _ContractMulticall3.contract.Transact(opts, "aggregate3", calls)
+}
+
+// Aggregate3 is a paid mutator transaction binding the contract method 0x82ad56cb.
+//
+// Solidity: function aggregate3((address,bool,bytes)[] calls) payable returns((bool,bytes)[] returnData)
+func (_ContractMulticall3 *ContractMulticall3Session) Aggregate3(calls []Multicall3Call3) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.Aggregate3(&_ContractMulticall3.TransactOpts, calls)
+}
+
+// Aggregate3 is a paid mutator transaction binding the contract method 0x82ad56cb.
+//
+// Solidity: function aggregate3((address,bool,bytes)[] calls) payable returns((bool,bytes)[] returnData)
+func (_ContractMulticall3 *ContractMulticall3TransactorSession) Aggregate3(calls []Multicall3Call3) (*types.Transaction, error) {
+	return _ContractMulticall3.Contract.Aggregate3(&_ContractMulticall3.TransactOpts, calls)
+}
@@ -29,3 +29,4 @@ for contract in $avs_service_contracts; do
 done
 
 create_binding . ERC20Mock ./bindings
+create_binding . Multicall3 ./bindings
@@ -63,5 +63,10 @@ rm -f "script/output/devnet/alignedlayer_deployment_output.temp1.json"
 rm -f "script/output/devnet/alignedlayer_deployment_output.temp2.json"
 
 
+# Place the Multicall3 stand-in at its canonical address, where it already exists on public networks
+cast rpc anvil_setCode 0xcA11bde05977b3631167028862bE2a173976CA11 \
+    "$(forge inspect src/core/Multicall3.sol:Multicall3 deployedBytecode)" \
+    --rpc-url "http://localhost:8545"
+
 # Kill the anvil process to save state
 pkill anvil
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.12;
+
+// Local stand-in for Multicall3 (https://github.com/mds1/multicall), deployed on anvil at the canonical
+// address 0xcA11bde05977b3631167028862bE2a173976CA11, where it already exists on public networks.
+// Only aggregate3 is implemented, which is what the operator and aggregator use to batch view calls.
+contract Multicall3 {
+    struct Call3 {
+        address target;
+        bool allowFailure;
+        bytes callData;
+    }
+
+    struct Result {
+        bool success;
+        bytes returnData;
+    }
+
+    function aggregate3(
+        Call3[] calldata calls
+    ) public payable returns (Result[] memory returnData) {
+        uint256 length = calls.length;
+        returnData = new Result[](length);
+        for (uint256 i = 0; i < length; i++) {
+            Call3 calldata call = calls[i];
+            (bool success, bytes memory result) = call.target.call(
+                call.callData
+            );
+            require(
+                success || call.allowFailure,
+                "Multicall3: call failed"
+            );
+            returnData[i] = Result(success, result);
+        }
+    }
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	contractERC20Mock "github.com/yetanotherco/aligned_layer/contracts/bindings/ERC20Mock"
+	contractMulticall3 "github.com/yetanotherco/aligned_layer/contracts/bindings/Multicall3"
+	retry "github.com/yetanotherco/aligned_layer/core"
 	"github.com/yetanotherco/aligned_layer/core/config"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
@@ -21,14 +24,17 @@ type AvsReader struct {
 	*sdkavsregistry.ChainReader
 	AvsContractBindings            *AvsServiceBindings
 	AlignedLayerServiceManagerAddr ethcommon.Address
-	logger                         logging.Logger
+	// Maximum number of blocks queried in a single eth_getLogs call, it is halved when the provider rejects the range
+	LogsBlockRange uint64
+	multicall3     *contractMulticall3.ContractMulticall3
+	logger         logging.Logger
 }
 
 func NewAvsReaderFromConfig(baseConfig *config.BaseConfig) (*AvsReader, error) {
 
 	buildAllConfig := clients.BuildAllConfig{
 		EthHttpUrl:                 baseConfig.EthRpcUrl,
-		EthWsUrl:                   baseConfig.EthWsUrl,
+		EthWsUrl:                   baseConfig.EthWsUrlOrRpcUrl(),
 		RegistryCoordinatorAddr:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.String(),
 		OperatorStateRetrieverAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.String(),
 		AvsName:                    "AlignedLayer",
@@ -42,27 +48,35 @@ func NewAvsReaderFromConfig(baseConfig *config.BaseConfig) (*AvsReader, error) {
 
 	chainReader := clients.AvsRegistryChainReader
 
-	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcClient, baseConfig.EthRpcClientFallback, baseConfig.Logger)
+	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcPool, baseConfig.Logger)
 	if err != nil {
 		return nil, err
 	}
 
+	multicall3, err := contractMulticall3.NewContractMulticall3(multicall3Address(baseConfig.AlignedLayerDeploymentConfig.Multicall3Addr), baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+
+	logsBlockRange := baseConfig.LogsBlockRange
+	if logsBlockRange == 0 {
+		logsBlockRange = BlockInterval
+	}
+
 	return &AvsReader{
 		ChainReader:                    chainReader,
 		AvsContractBindings:            avsServiceBindings,
 		AlignedLayerServiceManagerAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
+		LogsBlockRange:                 logsBlockRange,
+		multicall3:                     multicall3,
 		logger:                         baseConfig.Logger,
 	}, nil
 }
 
 func (r *AvsReader) GetErc20Mock(tokenAddr ethcommon.Address) (*contractERC20Mock.ContractERC20Mock, error) {
-	erc20Mock, err := contractERC20Mock.NewContractERC20Mock(tokenAddr, &r.AvsContractBindings.ethClient)
+	erc20Mock, err := contractERC20Mock.NewContractERC20Mock(tokenAddr, r.AvsContractBindings.ethClient)
 	if err != nil {
-		// Retry with fallback client
-		erc20Mock, err = contractERC20Mock.NewContractERC20Mock(tokenAddr, &r.AvsContractBindings.ethClientFallback)
-		if err != nil {
-			r.logger.Error("Failed to fetch ERC20Mock contract", "err", err)
-		}
+		r.logger.Error("Failed to fetch ERC20Mock contract", "err", err)
 	}
 	return erc20Mock, nil
 }
@@ -77,46 +91,120 @@ func (r *AvsReader) DisabledVerifiers() (*big.Int, error) {
 
 // Returns all the "NewBatchV3" logs that have not been responded starting from the given block number
 func (r *AvsReader) GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
-	logs, err := r.AvsContractBindings.ServiceManager.FilterNewBatchV3(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil)
+	latestBlock, err := r.AvsContractBindings.ethClient.BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
+	logs, err := r.FilterNewBatchV3InRanges(context.Background(), fromBlock, latestBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	batchIdentifierHashes := make([][32]byte, 0, len(logs))
+	for _, task := range logs {
+		batchIdentifier := append(task.BatchMerkleRoot[:], task.SenderAddress[:]...)
+		batchIdentifierHashes = append(batchIdentifierHashes, *(*[32]byte)(crypto.Keccak256(batchIdentifier)))
+	}
+
+	// now check if they are responded or not before appending
+	states, err := r.BatchesStates(&bind.CallOpts{}, batchIdentifierHashes)
 	if err != nil {
 		return nil, err
 	}
 
 	var tasks []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	for i, task := range logs {
+		// append the task if not responded yet
+		if !states[i].Responded {
+			tasks = append(tasks, task)
+		}
+	}
 
-	for logs.Next() {
-		task, err := r.AvsContractBindings.ServiceManager.ParseNewBatchV3(logs.Event.Raw)
+	return tasks, nil
+}
+
+// FilterNewBatchV3InRanges returns the "NewBatchV3" logs from fromBlock to toBlock, querying at most LogsBlockRange
+// blocks at a time, see FilterInRanges
+func (r *AvsReader) FilterNewBatchV3InRanges(ctx context.Context, fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	return filterNewBatchV3InRanges(ctx, r.AvsContractBindings.ServiceManager, r.LogsBlockRange, fromBlock, toBlock, r.logger)
+}
+
+func filterNewBatchV3InRanges(ctx context.Context, serviceManager *servicemanager.ContractAlignedLayerServiceManager, blockRange uint64, fromBlock uint64, toBlock uint64, logger logging.Logger) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	filter := func(ctx context.Context, start uint64, end uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+		logs, err := serviceManager.FilterNewBatchV3(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil)
 		if err != nil {
 			return nil, err
 		}
+		var tasks []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+		for logs.Next() {
+			tasks = append(tasks, *logs.Event)
+		}
+		return tasks, logs.Error()
+	}
+	return FilterInRanges(ctx, "FilterNewBatchV3", blockRange, fromBlock, toBlock, filter, retry.NetworkRetryParams(), logger)
+}
 
-		// now check if its finalized or not before appending
-		batchIdentifier := append(task.BatchMerkleRoot[:], task.SenderAddress[:]...)
-		batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-		state, err := r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.BatchesState(nil, batchIdentifierHash)
+// FilterInRanges returns the logs filter finds from fromBlock to toBlock, calling it with at most blockRange blocks
+// at a time. Each range is retried with config, labeled with name. When the provider rejects a range as too large,
+// it is halved until it is accepted
+func FilterInRanges[T any](ctx context.Context, name string, blockRange uint64, fromBlock uint64, toBlock uint64, filter func(ctx context.Context, fromBlock uint64, toBlock uint64) ([]T, error), config *retry.RetryParams, logger logging.Logger) ([]T, error) {
+	var logs []T
+
+	for start := fromBlock; start <= toBlock; {
+		end := min(toBlock, start+blockRange-1)
+		rangeLogs, err := retry.RetryWithDataContext(ctx, name, func(ctx context.Context) ([]T, error) {
+			rangeLogs, err := filter(ctx, start, end)
+			// Retrying the same range would fail again, it is split instead
+			if err != nil && isBlockRangeError(err) {
+				err = retry.PermanentError{Inner: err}
+			}
+			return rangeLogs, err
+		}, config)
 
 		if err != nil {
-			return nil, err
+			if !isBlockRangeError(err) || blockRange == 1 {
+				return nil, err
+			}
+			blockRange = max(blockRange/2, 1)
+			logger.Warn("Provider rejected the block range of the logs query, halving it", "fromBlock", start, "toBlock", end, "newBlockRange", blockRange, "err", err)
+			continue
 		}
 
-		// append the task if not responded yet
-		if !state.Responded {
-			tasks = append(tasks, *task)
-		}
+		logs = append(logs, rangeLogs...)
+		start = end + 1
 	}
 
-	return tasks, nil
+	return logs, nil
+}
+
+// Messages returned by the providers when the block range or the number of results of eth_getLogs is too large.
+// They are specific enough not to match rate limits or timeouts, which are retried with the same range
+var blockRangeErrorMessages = []string{
+	"block range",
+	"range too large",
+	"range is too large",
+	"exceed maximum block range",
+	"query returned more than",
+	"too many results",
+	"response size exceeded",
+}
+
+func isBlockRangeError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, rangeMessage := range blockRangeErrorMessages {
+		if strings.Contains(message, rangeMessage) {
+			return true
+		}
+	}
+	return false
 }
 
 // This function is a helper to get a task hash of aproximately nBlocksOld blocks ago
 func (r *AvsReader) GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byte, error) {
 	latestBlock, err := r.AvsContractBindings.ethClient.BlockNumber(context.Background())
 	if err != nil {
-		latestBlock, err = r.AvsContractBindings.ethClientFallback.BlockNumber(context.Background())
-		if err != nil {
-			return nil, fmt.Errorf("failed to get latest block number: %w", err)
-		}
+		return nil, fmt.Errorf("failed to get latest block number: %w", err)
 	}
 
 	if latestBlock < nBlocksOld {
@@ -0,0 +1,135 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+func TestIsBlockRangeError(t *testing.T) {
+	rangeErrors := []string{
+		"query returned more than 10000 results",
+		"eth_getLogs is limited to a 10,000 block range",
+		"Block range is too large",
+		"exceed maximum block range: 5000",
+	}
+	for _, message := range rangeErrors {
+		if !isBlockRangeError(errors.New(message)) {
+			t.Errorf("Expected %q to be a block range error", message)
+		}
+	}
+	transientErrors := []string{
+		"connection refused",
+		"429 Too Many Requests: rate limit exceeded",
+		"daily request limit exceeded",
+		"query timeout exceeded",
+	}
+	for _, message := range transientErrors {
+		if isBlockRangeError(errors.New(message)) {
+			t.Errorf("Expected %q not to be a block range error", message)
+		}
+	}
+}
+
+// testRangeRetryParams retries right away, to keep the tests fast
+func testRangeRetryParams() *retry.RetryParams {
+	return &retry.RetryParams{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		MaxElapsedTime:  time.Second,
+		Multiplier:      retry.NetworkMultiplier,
+		NumRetries:      retry.NetworkNumRetries,
+	}
+}
+
+func TestFilterInRangesHalvesRejectedRanges(t *testing.T) {
+	var ranges [][2]uint64
+	filter := func(ctx context.Context, fromBlock uint64, toBlock uint64) ([]uint64, error) {
+		ranges = append(ranges, [2]uint64{fromBlock, toBlock})
+		if toBlock-fromBlock+1 > 25 {
+			return nil, errors.New("query returned more than 10000 results")
+		}
+		var blocks []uint64
+		for block := fromBlock; block <= toBlock; block++ {
+			blocks = append(blocks, block)
+		}
+		return blocks, nil
+	}
+
+	blocks, err := FilterInRanges(context.Background(), "", 100, 1, 60, filter, testRangeRetryParams(), logging.NewTextSLogger(io.Discard, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 60 || blocks[0] != 1 || blocks[59] != 60 {
+		t.Errorf("Expected every block once, got %v", blocks)
+	}
+	// The rejected range is not retried as it is, it is halved right away
+	expected := [][2]uint64{{1, 60}, {1, 50}, {1, 25}, {26, 50}, {51, 60}}
+	if fmt.Sprint(ranges) != fmt.Sprint(expected) {
+		t.Errorf("Expected the ranges %v, got %v", expected, ranges)
+	}
+}
+
+func TestFilterInRangesRetriesTransientErrors(t *testing.T) {
+	failures := map[uint64]int{11: 2}
+	var ranges [][2]uint64
+	filter := func(ctx context.Context, fromBlock uint64, toBlock uint64) ([]uint64, error) {
+		ranges = append(ranges, [2]uint64{fromBlock, toBlock})
+		if failures[fromBlock] > 0 {
+			failures[fromBlock]--
+			return nil, errors.New("429 Too Many Requests: rate limit exceeded")
+		}
+		return []uint64{fromBlock}, nil
+	}
+
+	blocks, err := FilterInRanges(context.Background(), "", 10, 1, 30, filter, testRangeRetryParams(), logging.NewTextSLogger(io.Discard, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(blocks) != "[1 11 21]" {
+		t.Errorf("Expected the logs of the three ranges, got %v", blocks)
+	}
+	// The rate limited range keeps its size
+	expected := [][2]uint64{{1, 10}, {11, 20}, {11, 20}, {11, 20}, {21, 30}}
+	if fmt.Sprint(ranges) != fmt.Sprint(expected) {
+		t.Errorf("Expected the ranges %v, got %v", expected, ranges)
+	}
+}
+
+func TestFilterInRangesStopsWithItsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	filter := func(ctx context.Context, fromBlock uint64, toBlock uint64) ([]uint64, error) {
+		cancel()
+		return nil, errors.New("connection refused")
+	}
+	if _, err := FilterInRanges(ctx, "", 10, 1, 30, filter, testRangeRetryParams(), logging.NewTextSLogger(io.Discard, nil)); err == nil {
+		t.Errorf("Expected the filter to fail once its context is cancelled")
+	}
+}
+
+func TestDecodeBatchState(t *testing.T) {
+	serviceManagerAbi, err := servicemanager.ContractAlignedLayerServiceManagerMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	returnData, err := serviceManagerAbi.Methods["batchesState"].Outputs.Pack(uint32(42), true, big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := decodeBatchState(serviceManagerAbi, returnData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.TaskCreatedBlock != 42 || !state.Responded || state.RespondToTaskFeeLimit.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("Unexpected batch state: %+v", state)
+	}
+}
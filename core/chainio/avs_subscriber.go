@@ -3,11 +3,13 @@ package chainio
 import (
 	"context"
 	"encoding/hex"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
@@ -24,6 +26,7 @@ const (
 	BlockInterval              uint64 = 1000
 	PollLatestBatchInterval           = 5 * time.Second
 	RemoveBatchFromSetInterval        = 5 * time.Minute
+	RemovedBatchesChanSize            = 100
 )
 
 // NOTE(marian): Leaving this commented code here as it may be useful in the short term.
@@ -40,39 +43,82 @@ const (
 type AvsSubscriber struct {
 	AvsContractBindings            *AvsServiceBindings
 	AlignedLayerServiceManagerAddr ethcommon.Address
-	logger                         sdklogging.Logger
+	// Number of blocks a NewBatchV3 log has to be buried under before it is forwarded.
+	// If 0, logs are forwarded as soon as they are received.
+	ConfirmationDepth uint64
+	// Maximum number of blocks queried in a single eth_getLogs call when scanning for missed NewBatchV3 logs
+	LogsBlockRange uint64
+	// Receives the batch identifier hash of already forwarded batches whose log was removed by a reorg,
+	// so the in-flight processing of the batch can be cancelled.
+	RemovedBatchesChan chan [32]byte
+	// Read by the processing of NewBatchV3 logs. It is the subscriber itself, and a fake chain in tests
+	chain  batchesChain
+	logger sdklogging.Logger
+}
+
+// batchesChain is what the processing of NewBatchV3 logs reads from the chain
+type batchesChain interface {
+	BlockNumberRetryable(ctx context.Context, config *retry.RetryParams) (uint64, error)
+	HeaderByNumberRetryable(ctx context.Context, blockNumber *big.Int, config *retry.RetryParams) (*types.Header, error)
+	BatchesStateRetryable(ctx context.Context, opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
+		TaskCreatedBlock      uint32
+		Responded             bool
+		RespondToTaskFeeLimit *big.Int
+	}, error)
+	FilterNewBatchV3InRanges(ctx context.Context, fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error)
 }
 
 func NewAvsSubscriberFromConfig(baseConfig *config.BaseConfig) (*AvsSubscriber, error) {
 	avsContractBindings, err := NewAvsServiceBindings(
 		baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
 		baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr,
-		baseConfig.EthWsClient, baseConfig.EthWsClientFallback, baseConfig.Logger)
+		baseConfig.EthEventsBackend, baseConfig.Logger)
 
 	if err != nil {
 		baseConfig.Logger.Errorf("Failed to create contract bindings", "err", err)
 		return nil, err
 	}
 
-	return &AvsSubscriber{
+	logsBlockRange := baseConfig.LogsBlockRange
+	if logsBlockRange == 0 {
+		logsBlockRange = BlockInterval
+	}
+
+	subscriber := &AvsSubscriber{
 		AvsContractBindings:            avsContractBindings,
 		AlignedLayerServiceManagerAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
+		ConfirmationDepth:              baseConfig.NewBatchConfirmationDepth,
+		LogsBlockRange:                 logsBlockRange,
+		RemovedBatchesChan:             make(chan [32]byte, RemovedBatchesChanSize),
 		logger:                         baseConfig.Logger,
-	}, nil
+	}
+	subscriber.chain = subscriber
+	return subscriber, nil
+}
+
+// FilterNewBatchV3InRanges returns the "NewBatchV3" logs from fromBlock to toBlock, see AvsReader.FilterNewBatchV3InRanges
+func (s *AvsSubscriber) FilterNewBatchV3InRanges(ctx context.Context, fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	return filterNewBatchV3InRanges(ctx, s.AvsContractBindings.ServiceManager, s.LogsBlockRange, fromBlock, toBlock, s.logger)
+}
+
+// RemovedBatches returns the channel that receives the batch identifier hash of the forwarded batches
+// whose NewBatchV3 log was removed by a reorg
+func (s *AvsSubscriber) RemovedBatches() <-chan [32]byte {
+	return s.RemovedBatchesChan
 }
 
-func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
+func (s *AvsSubscriber) SubscribeToNewTasksV2(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
 	// Create a new channel to receive new tasks
 	internalChannel := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2)
 
 	// Subscribe to new tasks
-	sub, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+	sub, err := SubscribeToNewTasksV2Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Primary failed to subscribe to new AlignedLayer V2 tasks after %d retries", retry.NetworkNumRetries, "err", err)
 		return nil, err
 	}
 
-	subFallback, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+	subFallback, err := SubscribeToNewTasksV2Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Fallback failed to subscribe to new AlignedLayer V2 tasks after %d retries", retry.NetworkNumRetries, "err", err)
 		return nil, err
@@ -91,10 +137,12 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 		batchesSet := make(map[[32]byte]struct{})
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case newBatch := <-internalChannel:
 				s.processNewBatchV2(newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
 			case <-pollLatestBatchTicker.C:
-				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV2()
+				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV2(ctx)
 				if err != nil {
 					s.logger.Debug("Failed to get latest task from blockchain", "err", err)
 					continue
@@ -107,23 +155,27 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 
 	}()
 
-	// Handle errors and resubscribe
+	// Handle errors and resubscribe, until ctx is done
 	go func() {
 		for {
 			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				subFallback.Unsubscribe()
+				return
 			case err := <-sub.Err():
 				s.logger.Warn("Error in new task subscription", "err", err)
 				sub.Unsubscribe()
-				sub, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+				sub, err = SubscribeToNewTasksV2Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					sendSubscriptionError(ctx, errorChannel, err)
 				}
 			case err := <-subFallback.Err():
 				s.logger.Warn("Error in fallback new task subscription", "err", err)
 				subFallback.Unsubscribe()
-				subFallback, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+				subFallback, err = SubscribeToNewTasksV2Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					sendSubscriptionError(ctx, errorChannel, err)
 				}
 			}
 		}
@@ -132,18 +184,37 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 	return errorChannel, nil
 }
 
-func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (chan error, error) {
+// SubscribeToNewTasksV3 forwards every NewBatchV3 log to newTaskCreatedChan once.
+// Logs come from two sources: the live subscriptions, and a block cursor that scans every block with FilterNewBatchV3InRanges.
+// The cursor starts at fromBlock, or BlockInterval blocks behind the head if it is 0, to pick up the batches not
+// responded yet, and only advances over ranges that were fully scanned, so any log missed while the subscriptions
+// were down is backfilled. The cursor lives in memory, callers that resubscribe pass the block to resume from.
+// Logs seen by both sources are deduped by batch identifier hash.
+func (s *AvsSubscriber) SubscribeToNewTasksV3(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, fromBlock uint64) (chan error, error) {
 	// Create a new channel to receive new tasks
 	internalChannel := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
+	// The cursor is set before subscribing, so no block is left between the scanned ones and the subscription
+	latestBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
+	if err != nil {
+		s.logger.Error("Failed to get latest block to start the NewBatchV3 cursor", "err", err)
+		return nil, err
+	}
+	lastScannedBlock := uint64(0)
+	if fromBlock > 0 {
+		lastScannedBlock = fromBlock - 1
+	} else if latestBlock > BlockInterval {
+		lastScannedBlock = latestBlock - BlockInterval
+	}
+
 	// Subscribe to new tasks
-	sub, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+	sub, err := SubscribeToNewTasksV3Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Primary failed to subscribe to new AlignedLayer V3 tasks after %d retries", MaxRetries, "err", err)
 		return nil, err
 	}
 
-	subFallback, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+	subFallback, err := SubscribeToNewTasksV3Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Fallback failed to subscribe to new AlignedLayer V3 tasks after %d retries", MaxRetries, "err", err)
 		return nil, err
@@ -155,46 +226,59 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 
 	pollLatestBatchTicker := time.NewTicker(PollLatestBatchInterval)
 
+	newBatchMutex := &sync.Mutex{}
+	batchesSet := make(map[[32]byte]struct{})
+	pendingBatches := make(map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
+
 	// Forward the new tasks to the provided channel
 	go func() {
-		defer pollLatestBatchTicker.Stop()
-		newBatchMutex := &sync.Mutex{}
-		batchesSet := make(map[[32]byte]struct{})
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case newBatch := <-internalChannel:
-				s.processNewBatchV3(newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
-			case <-pollLatestBatchTicker.C:
-				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV3()
-				if err != nil {
-					s.logger.Debug("Failed to get latest task from blockchain", "err", err)
-					continue
-				}
-				if latestBatch != nil {
-					s.processNewBatchV3(latestBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
-				}
+				s.processNewBatchV3(newBatch, batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
 			}
 		}
+	}()
 
+	// Scan the blocks and dispatch the confirmed batches apart, as their retried calls would otherwise stop
+	// the live logs from being received
+	go func() {
+		defer pollLatestBatchTicker.Stop()
+		lastScannedBlock = s.scanNewBatchesV3(ctx, lastScannedBlock, batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pollLatestBatchTicker.C:
+				s.dispatchConfirmedBatchesV3(ctx, batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
+				lastScannedBlock = s.scanNewBatchesV3(ctx, lastScannedBlock, batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
+			}
+		}
 	}()
 
-	// Handle errors and resubscribe
+	// Handle errors and resubscribe, until ctx is done
 	go func() {
 		for {
 			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				subFallback.Unsubscribe()
+				return
 			case err := <-sub.Err():
 				s.logger.Warn("Error in new task subscription", "err", err)
 				sub.Unsubscribe()
-				sub, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+				sub, err = SubscribeToNewTasksV3Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					sendSubscriptionError(ctx, errorChannel, err)
 				}
 			case err := <-subFallback.Err():
 				s.logger.Warn("Error in fallback new task subscription", "err", err)
 				subFallback.Unsubscribe()
-				subFallback, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+				subFallback, err = SubscribeToNewTasksV3Retryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					sendSubscriptionError(ctx, errorChannel, err)
 				}
 			}
 		}
@@ -203,6 +287,111 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 	return errorChannel, nil
 }
 
+// SubscribeToVerifierStatusChanges forwards the VerifierDisabled and VerifierEnabled events of the AVS contract
+// to the given channels. Subscriptions are renewed when they fail, see keepSubscribed.
+func (s *AvsSubscriber) SubscribeToVerifierStatusChanges(ctx context.Context, verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled, verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled) (chan error, error) {
+	subDisabled, err := SubscribeToVerifierDisabledRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, verifierDisabledChan, retry.NetworkRetryParams())
+	if err != nil {
+		s.logger.Error("Failed to subscribe to VerifierDisabled events", "err", err)
+		return nil, err
+	}
+
+	subEnabled, err := SubscribeToVerifierEnabledRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, verifierEnabledChan, retry.NetworkRetryParams())
+	if err != nil {
+		subDisabled.Unsubscribe()
+		s.logger.Error("Failed to subscribe to VerifierEnabled events", "err", err)
+		return nil, err
+	}
+	s.logger.Info("Subscribed to verifier status changes")
+
+	errorChannel := make(chan error)
+
+	go s.keepSubscribed(ctx, "VerifierDisabled", subDisabled, func() (event.Subscription, error) {
+		return SubscribeToVerifierDisabledRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, verifierDisabledChan, retry.ResubscribeRetryParams())
+	}, errorChannel)
+	go s.keepSubscribed(ctx, "VerifierEnabled", subEnabled, func() (event.Subscription, error) {
+		return SubscribeToVerifierEnabledRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, verifierEnabledChan, retry.ResubscribeRetryParams())
+	}, errorChannel)
+
+	return errorChannel, nil
+}
+
+// keepSubscribed renews the subscription every time it fails, until ctx is done. The failed subscription is only
+// replaced once resubscribe succeeds, so resubscribe is expected to retry on its own. If it gives up, the error is
+// sent to errorChannel and the subscription is not renewed anymore
+func (s *AvsSubscriber) keepSubscribed(ctx context.Context, name string, sub event.Subscription, resubscribe func() (event.Subscription, error), errorChannel chan<- error) {
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return
+		case err = <-sub.Err():
+		}
+		s.logger.Warn("Error in subscription, renewing it", "subscription", name, "err", err)
+		sub.Unsubscribe()
+
+		newSub, err := resubscribe()
+		if err != nil {
+			s.logger.Error("Could not renew subscription", "subscription", name, "err", err)
+			sendSubscriptionError(ctx, errorChannel, err)
+			return
+		}
+		sub = newSub
+	}
+}
+
+// sendSubscriptionError reports a subscription that could not be renewed, unless ctx is done,
+// as nobody reads errorChannel anymore then
+func sendSubscriptionError(ctx context.Context, errorChannel chan<- error, err error) {
+	select {
+	case errorChannel <- err:
+	case <-ctx.Done():
+	}
+}
+
+// SubscribeToBatchVerified sends the identifier hash of every batch responded in the AVS contract to the given channel,
+// so the work still pending on it can be dropped. Events removed by a reorg are ignored, the batch is not responded anymore.
+// The subscription is renewed when it fails, see keepSubscribed.
+func (s *AvsSubscriber) SubscribeToBatchVerified(ctx context.Context, batchVerifiedChan chan [32]byte) (chan error, error) {
+	internalChannel := make(chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified, 100)
+
+	sub, err := SubscribeToBatchVerifiedRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, retry.NetworkRetryParams())
+	if err != nil {
+		s.logger.Error("Failed to subscribe to BatchVerified events", "err", err)
+		return nil, err
+	}
+	s.logger.Info("Subscribed to verified batches")
+
+	errorChannel := make(chan error)
+
+	go func() {
+		for {
+			var verifiedBatch *servicemanager.ContractAlignedLayerServiceManagerBatchVerified
+			select {
+			case <-ctx.Done():
+				return
+			case verifiedBatch = <-internalChannel:
+			}
+			if verifiedBatch.Raw.Removed {
+				continue
+			}
+			batchIdentifier := append(verifiedBatch.BatchMerkleRoot[:], verifiedBatch.SenderAddress[:]...)
+			select {
+			case batchVerifiedChan <- *(*[32]byte)(crypto.Keccak256(batchIdentifier)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go s.keepSubscribed(ctx, "BatchVerified", sub, func() (event.Subscription, error) {
+		return SubscribeToBatchVerifiedRetryable(ctx, &bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, retry.ResubscribeRetryParams())
+	}, errorChannel)
+
+	return errorChannel, nil
+}
+
 func (s *AvsSubscriber) processNewBatchV2(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
 	newBatchMutex.Lock()
 	defer newBatchMutex.Unlock()
@@ -229,85 +418,242 @@ func (s *AvsSubscriber) processNewBatchV2(batch *servicemanager.ContractAlignedL
 	}
 }
 
-func (s *AvsSubscriber) processNewBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+func (s *AvsSubscriber) processNewBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchesSet map[[32]byte]struct{}, pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
 	newBatchMutex.Lock()
 	defer newBatchMutex.Unlock()
 
 	batchIdentifier := append(batch.BatchMerkleRoot[:], batch.SenderAddress[:]...)
 	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
 
-	if _, ok := batchesSet[batchIdentifierHash]; !ok {
-		s.logger.Info("Received new task",
-			"batchMerkleRoot", hex.EncodeToString(batch.BatchMerkleRoot[:]),
-			"senderAddress", hex.EncodeToString(batch.SenderAddress[:]),
-			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+	if batch.Raw.Removed {
+		s.removeBatchV3(batch, batchIdentifierHash, batchesSet, pendingBatches)
+		return
+	}
 
-		batchesSet[batchIdentifierHash] = struct{}{}
-		newTaskCreatedChan <- batch
+	if _, ok := batchesSet[batchIdentifierHash]; ok {
+		return
+	}
 
-		// Remove the batch from the set after RemoveBatchFromSetInterval time
-		go func() {
-			time.Sleep(RemoveBatchFromSetInterval)
-			newBatchMutex.Lock()
-			delete(batchesSet, batchIdentifierHash)
-			newBatchMutex.Unlock()
-		}()
+	if s.ConfirmationDepth > 0 {
+		// The batch is kept until it has enough confirmations, see dispatchConfirmedBatchesV3.
+		// If it was already pending, it is replaced, as the new log may come from a different block after a reorg
+		if _, ok := pendingBatches[batchIdentifierHash]; !ok {
+			s.logger.Info("Received new task, waiting for confirmations",
+				"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+				"blockNumber", batch.Raw.BlockNumber,
+				"confirmationDepth", s.ConfirmationDepth)
+		}
+		pendingBatches[batchIdentifierHash] = batch
+		return
 	}
+
+	s.forwardBatchV3(batch, batchIdentifierHash, batchesSet, newBatchMutex, newTaskCreatedChan)
 }
 
-// getLatestNotRespondedTaskFromEthereum queries the blockchain for the latest not responded task using the FilterNewBatch method.
-func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, error) {
+// scanNewBatchesV3 processes the NewBatchV3 logs of every block after lastScannedBlock up to the latest one,
+// and returns the last scanned block. If the logs can't be fetched, the same blocks are scanned again on the next call.
+func (s *AvsSubscriber) scanNewBatchesV3(ctx context.Context, lastScannedBlock uint64, batchesSet map[[32]byte]struct{}, pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) uint64 {
+	latestBlock, err := s.chain.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
+	if err != nil {
+		s.logger.Warn("Failed to get latest block to scan NewBatchV3 logs", "err", err)
+		return lastScannedBlock
+	}
+	if lastScannedBlock >= latestBlock {
+		return lastScannedBlock
+	}
 
-	latestBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+	logs, err := s.chain.FilterNewBatchV3InRanges(ctx, lastScannedBlock+1, latestBlock)
 	if err != nil {
-		return nil, err
+		s.logger.Warn("Failed to scan NewBatchV3 logs, will retry", "fromBlock", lastScannedBlock+1, "toBlock", latestBlock, "err", err)
+		return lastScannedBlock
+	}
+	for i := range logs {
+		s.backfillNewBatchV3(ctx, &logs[i], batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
 	}
 
-	var fromBlock uint64
+	return latestBlock
+}
 
-	if latestBlock < BlockInterval {
-		fromBlock = 0
-	} else {
-		fromBlock = latestBlock - BlockInterval
+// backfillNewBatchV3 processes a log found by the block cursor. Logs already received from the subscriptions
+// are skipped, and the others are only processed if the batch has not been responded yet
+func (s *AvsSubscriber) backfillNewBatchV3(ctx context.Context, batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchesSet map[[32]byte]struct{}, pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	batchIdentifier := append(batch.BatchMerkleRoot[:], batch.SenderAddress[:]...)
+	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+
+	newBatchMutex.Lock()
+	_, seen := batchesSet[batchIdentifierHash]
+	_, pending := pendingBatches[batchIdentifierHash]
+	newBatchMutex.Unlock()
+	if seen || pending {
+		return
 	}
 
-	logs, err := s.FilterBatchV2Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil, retry.NetworkRetryParams())
+	state, err := s.chain.BatchesStateRetryable(ctx, nil, batchIdentifierHash, retry.NetworkRetryParams())
 	if err != nil {
-		return nil, err
+		s.logger.Warn("Failed to get state of backfilled task, processing it anyway",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+	} else if state.Responded {
+		return
 	}
 
-	var lastLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
+	s.logger.Info("Backfilling task missed by the subscriptions",
+		"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+		"blockNumber", batch.Raw.BlockNumber)
+	s.processNewBatchV3(batch, batchesSet, pendingBatches, newBatchMutex, newTaskCreatedChan)
+}
 
-	// Iterate over the logs until the end
-	for logs.Next() {
-		lastLog = logs.Event
+// removeBatchV3 handles a NewBatchV3 log that was removed from the chain because of a reorg.
+// If the batch was still waiting for confirmations it is just dropped, otherwise its processing is cancelled.
+// Must be called while holding newBatchMutex
+func (s *AvsSubscriber) removeBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchIdentifierHash [32]byte, batchesSet map[[32]byte]struct{}, pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	if pendingBatch, ok := pendingBatches[batchIdentifierHash]; ok {
+		if pendingBatch.Raw.BlockHash == batch.Raw.BlockHash {
+			s.logger.Warn("Pending task removed by a reorg, dropping it",
+				"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+				"blockNumber", batch.Raw.BlockNumber)
+			delete(pendingBatches, batchIdentifierHash)
+		}
+		return
 	}
 
-	if err := logs.Error(); err != nil {
-		return nil, err
+	if _, ok := batchesSet[batchIdentifierHash]; !ok {
+		return
 	}
 
-	if lastLog == nil {
-		return nil, nil
+	s.logger.Warn("Task removed by a reorg after being processed, cancelling it",
+		"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+		"blockNumber", batch.Raw.BlockNumber)
+
+	// Allow the batch to be processed again if it is included in another block
+	delete(batchesSet, batchIdentifierHash)
+
+	select {
+	case s.RemovedBatchesChan <- batchIdentifierHash:
+	default:
+		s.logger.Warn("Removed batches channel is full, batch processing will not be cancelled",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+	}
+}
+
+// dispatchConfirmedBatchesV3 forwards the pending batches that have at least ConfirmationDepth confirmations.
+// Before forwarding a batch it checks that its block is still part of the canonical chain,
+// and that the TaskCreatedBlock matches the one stored in the contract.
+func (s *AvsSubscriber) dispatchConfirmedBatchesV3(ctx context.Context, batchesSet map[[32]byte]struct{}, pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	if s.ConfirmationDepth == 0 {
+		return
 	}
 
-	batchIdentifier := append(lastLog.BatchMerkleRoot[:], lastLog.SenderAddress[:]...)
-	batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-	state, err := s.BatchesStateRetryable(nil, batchIdentifierHash, retry.NetworkRetryParams())
+	newBatchMutex.Lock()
+	candidates := make(map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, len(pendingBatches))
+	for batchIdentifierHash, batch := range pendingBatches {
+		candidates[batchIdentifierHash] = batch
+	}
+	newBatchMutex.Unlock()
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	latestBlock, err := s.chain.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
-		return nil, err
+		s.logger.Warn("Failed to get latest block to check pending tasks confirmations", "err", err)
+		return
+	}
+
+	for batchIdentifierHash, batch := range candidates {
+		if batch.Raw.BlockNumber+s.ConfirmationDepth > latestBlock {
+			continue
+		}
+
+		canonical, err := s.validateBatchOnCanonicalChainV3(ctx, batch, batchIdentifierHash)
+		if err != nil {
+			s.logger.Warn("Failed to validate pending task against the canonical chain, will retry",
+				"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+			continue
+		}
+
+		newBatchMutex.Lock()
+		// The batch may have been removed or replaced while validating it
+		if pendingBatches[batchIdentifierHash] != batch {
+			newBatchMutex.Unlock()
+			continue
+		}
+		delete(pendingBatches, batchIdentifierHash)
+		if canonical {
+			s.forwardBatchV3(batch, batchIdentifierHash, batchesSet, newBatchMutex, newTaskCreatedChan)
+		}
+		newBatchMutex.Unlock()
+	}
+}
+
+// validateBatchOnCanonicalChainV3 checks that the block of the log is still the canonical one at its height,
+// and fixes the TaskCreatedBlock of the batch with the value stored in the contract if they differ.
+// Returns false if the batch should be dropped.
+func (s *AvsSubscriber) validateBatchOnCanonicalChainV3(ctx context.Context, batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchIdentifierHash [32]byte) (bool, error) {
+	header, err := s.chain.HeaderByNumberRetryable(ctx, new(big.Int).SetUint64(batch.Raw.BlockNumber), retry.NetworkRetryParams())
+	if err != nil {
+		return false, err
+	}
+	if header.Hash() != batch.Raw.BlockHash {
+		s.logger.Warn("Pending task block is no longer canonical, dropping it",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+			"blockNumber", batch.Raw.BlockNumber,
+			"logBlockHash", batch.Raw.BlockHash.Hex(),
+			"canonicalBlockHash", header.Hash().Hex())
+		return false, nil
 	}
 
+	state, err := s.chain.BatchesStateRetryable(ctx, nil, batchIdentifierHash, retry.NetworkRetryParams())
+	if err != nil {
+		return false, err
+	}
+	if state.TaskCreatedBlock == 0 {
+		s.logger.Warn("Pending task does not exist in the contract, dropping it",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+		return false, nil
+	}
 	if state.Responded {
-		return nil, nil
+		s.logger.Info("Pending task has already been responded, dropping it",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+		return false, nil
+	}
+	if state.TaskCreatedBlock != batch.TaskCreatedBlock {
+		s.logger.Warn("Task created block differs from the one stored in the contract, using the contract one",
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+			"logTaskCreatedBlock", batch.TaskCreatedBlock,
+			"contractTaskCreatedBlock", state.TaskCreatedBlock)
+		batch.TaskCreatedBlock = state.TaskCreatedBlock
 	}
 
-	return lastLog, nil
+	return true, nil
+}
+
+// forwardBatchV3 sends the batch to newTaskCreatedChan and remembers it to avoid processing it twice.
+// Must be called while holding newBatchMutex
+func (s *AvsSubscriber) forwardBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchIdentifierHash [32]byte, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	if _, ok := batchesSet[batchIdentifierHash]; !ok {
+		s.logger.Info("Received new task",
+			"batchMerkleRoot", hex.EncodeToString(batch.BatchMerkleRoot[:]),
+			"senderAddress", hex.EncodeToString(batch.SenderAddress[:]),
+			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+
+		batchesSet[batchIdentifierHash] = struct{}{}
+		newTaskCreatedChan <- batch
+
+		// Remove the batch from the set after RemoveBatchFromSetInterval time
+		go func() {
+			time.Sleep(RemoveBatchFromSetInterval)
+			newBatchMutex.Lock()
+			delete(batchesSet, batchIdentifierHash)
+			newBatchMutex.Unlock()
+		}()
+	}
 }
 
 // getLatestNotRespondedTaskFromEthereum queries the blockchain for the latest not responded task using the FilterNewBatch method.
-func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
-	latestBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2(ctx context.Context) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, error) {
+
+	latestBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -320,12 +666,12 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanag
 		fromBlock = latestBlock - BlockInterval
 	}
 
-	logs, err := s.FilterBatchV3Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil, retry.NetworkRetryParams())
+	logs, err := s.FilterBatchV2Retryable(ctx, &bind.FilterOpts{Start: fromBlock, End: nil}, nil, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
 
-	var lastLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	var lastLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
 
 	// Iterate over the logs until the end
 	for logs.Next() {
@@ -342,7 +688,7 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanag
 
 	batchIdentifier := append(lastLog.BatchMerkleRoot[:], lastLog.SenderAddress[:]...)
 	batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-	state, err := s.BatchesStateRetryable(nil, batchIdentifierHash, retry.NetworkRetryParams())
+	state, err := s.BatchesStateRetryable(ctx, nil, batchIdentifierHash, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -354,8 +700,8 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanag
 	return lastLog, nil
 }
 
-func (s *AvsSubscriber) WaitForOneBlock(startBlock uint64) error {
-	currentBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+func (s *AvsSubscriber) WaitForOneBlock(ctx context.Context, startBlock uint64) error {
+	currentBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
 		return err
 	}
@@ -363,14 +709,25 @@ func (s *AvsSubscriber) WaitForOneBlock(startBlock uint64) error {
 	if currentBlock <= startBlock { // should really be == but just in case
 		// Subscribe to new head
 		c := make(chan *types.Header)
-		sub, err := s.SubscribeNewHeadRetryable(context.Background(), c, retry.NetworkRetryParams())
+		sub, err := s.SubscribeNewHeadRetryable(ctx, c, retry.NetworkRetryParams())
 		if err != nil {
 			return err
 		}
 
-		// Read channel for the new block
-		<-c
-		(sub).Unsubscribe()
+		defer sub.Unsubscribe()
+
+		// Read channel until a block after startBlock is seen. When polling over http,
+		// the first header delivered may be the one that was already the latest
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case header := <-c:
+				if header.Number.Uint64() > startBlock {
+					return nil
+				}
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,249 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+)
+
+func submitBatch(chain *fake.Chain, batchMerkleRoot [32]byte) *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3 {
+	return chain.SubmitBatch(batchMerkleRoot, common.HexToAddress("0x1"), "http://localhost/batch.json", big.NewInt(1e15))
+}
+
+func expectForwarded(t *testing.T, batches *chainio.NewBatchesV3, expected ...*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	t.Helper()
+	for _, batch := range expected {
+		select {
+		case forwarded := <-batches.Forwarded:
+			if forwarded.BatchMerkleRoot != batch.BatchMerkleRoot {
+				t.Fatalf("Expected batch %x to be forwarded, got %x", batch.BatchMerkleRoot, forwarded.BatchMerkleRoot)
+			}
+		default:
+			t.Fatalf("Expected batch %x to be forwarded", batch.BatchMerkleRoot)
+		}
+	}
+	select {
+	case forwarded := <-batches.Forwarded:
+		t.Fatalf("Expected no other batch to be forwarded, got %x", forwarded.BatchMerkleRoot)
+	default:
+	}
+}
+
+func TestNewBatchHeldUntilConfirmationDepth(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 3)
+
+	batch := submitBatch(chain, [32]byte{1})
+	batches.Receive(batch)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches)
+
+	chain.MineBlocks(2)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches)
+
+	chain.MineBlocks(1)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches, batch)
+	if batches.Pending() != 0 {
+		t.Errorf("Expected the forwarded batch not to be pending, got %d pending batches", batches.Pending())
+	}
+
+	// The same log received again is not forwarded twice
+	batches.Receive(batch)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches)
+}
+
+func TestNewBatchForwardedRightAwayWithoutConfirmationDepth(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 0)
+
+	batch := submitBatch(chain, [32]byte{1})
+	batches.Receive(batch)
+	expectForwarded(t, batches, batch)
+}
+
+func TestNewBatchDroppedWhenBlockHashChanges(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 3)
+
+	batch := submitBatch(chain, [32]byte{1})
+	batches.Receive(batch)
+
+	chain.ReplaceBlock(batch.Raw.BlockNumber)
+	chain.MineBlocks(3)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches)
+	if batches.Pending() != 0 {
+		t.Errorf("Expected the batch of the replaced block to be dropped, got %d pending batches", batches.Pending())
+	}
+}
+
+func TestRemovedLogDropsPendingBatch(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 3)
+
+	batch := submitBatch(chain, [32]byte{1})
+	other := submitBatch(chain, [32]byte{2})
+	batches.Receive(batch)
+	batches.Receive(other)
+
+	removed := *batch
+	removed.Raw.Removed = true
+	batches.Receive(&removed)
+	if batches.Pending() != 1 {
+		t.Fatalf("Expected only the other batch to be pending, got %d pending batches", batches.Pending())
+	}
+
+	chain.MineBlocks(3)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches, other)
+	select {
+	case <-batches.RemovedBatches():
+		t.Errorf("Expected a batch removed before being forwarded not to be cancelled")
+	default:
+	}
+}
+
+func TestScanBackfillsBatchesAfterLongDowntime(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 0)
+
+	batch := submitBatch(chain, [32]byte{1})
+	chain.MineBlocks(5 * chainio.BlockInterval)
+
+	latestBlock, _ := chain.BlockNumberRetryable(context.Background(), nil)
+	if lastScannedBlock := batches.Scan(batch.Raw.BlockNumber - 1); lastScannedBlock != latestBlock {
+		t.Errorf("Expected the cursor to reach the latest block %d, got %d", latestBlock, lastScannedBlock)
+	}
+	expectForwarded(t, batches, batch)
+}
+
+func TestScanRescansFailedRange(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 0)
+
+	batch := submitBatch(chain, [32]byte{1})
+	chain.SetFilterLogsError(errors.New("connection lost"))
+	if lastScannedBlock := batches.Scan(0); lastScannedBlock != 0 {
+		t.Fatalf("Expected the cursor not to advance over a failed range, got %d", lastScannedBlock)
+	}
+	expectForwarded(t, batches)
+
+	chain.SetFilterLogsError(nil)
+	if lastScannedBlock := batches.Scan(0); lastScannedBlock != batch.Raw.BlockNumber {
+		t.Errorf("Expected the cursor to advance to block %d, got %d", batch.Raw.BlockNumber, lastScannedBlock)
+	}
+	expectForwarded(t, batches, batch)
+}
+
+func TestScanDedupesSeenAndPendingBatches(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 3)
+
+	batch := submitBatch(chain, [32]byte{1})
+	batches.Receive(batch)
+	batches.Scan(0)
+	if batches.Pending() != 1 {
+		t.Fatalf("Expected the pending batch not to be added again, got %d pending batches", batches.Pending())
+	}
+
+	chain.MineBlocks(3)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches, batch)
+
+	// Once forwarded, the batch is not backfilled again
+	batches.Scan(0)
+	batches.DispatchConfirmed()
+	expectForwarded(t, batches)
+	if batches.Pending() != 0 {
+		t.Errorf("Expected the forwarded batch not to be pending again, got %d pending batches", batches.Pending())
+	}
+}
+
+func TestScanSkipsRespondedBatches(t *testing.T) {
+	chain := fake.NewChain()
+	batches := chainio.NewTestNewBatchesV3(chain, 0)
+
+	responded := submitBatch(chain, [32]byte{1})
+	notResponded := submitBatch(chain, [32]byte{2})
+	_, err := chain.SendAggregatedResponse(context.Background(), fake.BatchIdentifierHash(responded.BatchMerkleRoot, responded.SenderAddress),
+		responded.BatchMerkleRoot, responded.SenderAddress, servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{}, 0, 0, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batches.Scan(0)
+	expectForwarded(t, batches, notResponded)
+}
+
+// failingSubscription fails as soon as fail is closed
+func failingSubscription(fail <-chan struct{}) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case <-fail:
+			return errors.New("connection lost")
+		case <-quit:
+			return nil
+		}
+	})
+}
+
+func TestSubscriptionRenewedUntilResubscribeFails(t *testing.T) {
+	subscriber := chainio.NewTestAvsSubscriber(fake.NewChain(), 0)
+
+	failFirst := make(chan struct{})
+	failSecond := make(chan struct{})
+	resubscribeErr := errors.New("could not resubscribe")
+	resubscriptions := 0
+	resubscribe := func() (event.Subscription, error) {
+		resubscriptions++
+		if resubscriptions == 1 {
+			return failingSubscription(failSecond), nil
+		}
+		return nil, resubscribeErr
+	}
+
+	errorChannel := make(chan error, 1)
+	stopped := make(chan struct{})
+	go func() {
+		chainio.KeepSubscribed(subscriber, context.Background(), "test", failingSubscription(failFirst), resubscribe, errorChannel)
+		close(stopped)
+	}()
+
+	// The first failure is renewed with a working subscription
+	close(failFirst)
+	select {
+	case err := <-errorChannel:
+		t.Fatalf("Expected the subscription to be renewed, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The renewed subscription is the one watched, and a failed renewal is reported instead of used
+	close(failSecond)
+	select {
+	case err := <-errorChannel:
+		if !errors.Is(err, resubscribeErr) {
+			t.Errorf("Expected the resubscribe error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the failed renewal to be reported")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the subscription not to be renewed after a failed renewal")
+	}
+	if resubscriptions != 2 {
+		t.Errorf("Expected 2 resubscriptions, got %d", resubscriptions)
+	}
+}
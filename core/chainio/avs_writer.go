@@ -9,7 +9,6 @@ import (
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/signer"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -18,6 +17,7 @@ import (
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	retry "github.com/yetanotherco/aligned_layer/core"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/rpcpool"
 	"github.com/yetanotherco/aligned_layer/core/utils"
 	"github.com/yetanotherco/aligned_layer/metrics"
 )
@@ -27,8 +27,7 @@ type AvsWriter struct {
 	AvsContractBindings *AvsServiceBindings
 	logger              logging.Logger
 	Signer              signer.Signer
-	Client              eth.InstrumentedClient
-	ClientFallback      eth.InstrumentedClient
+	Client              *rpcpool.Pool
 	metrics             *metrics.Metrics
 }
 
@@ -36,7 +35,7 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 
 	buildAllConfig := clients.BuildAllConfig{
 		EthHttpUrl:                 baseConfig.EthRpcUrl,
-		EthWsUrl:                   baseConfig.EthWsUrl,
+		EthWsUrl:                   baseConfig.EthWsUrlOrRpcUrl(),
 		RegistryCoordinatorAddr:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.String(),
 		OperatorStateRetrieverAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.String(),
 		AvsName:                    "AlignedLayer",
@@ -50,7 +49,7 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 		return nil, err
 	}
 
-	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcClient, baseConfig.EthRpcClientFallback, baseConfig.Logger)
+	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcPool, baseConfig.Logger)
 
 	if err != nil {
 		baseConfig.Logger.Error("Cannot create avs service bindings", "err", err)
@@ -70,8 +69,7 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 		AvsContractBindings: avsServiceBindings,
 		logger:              baseConfig.Logger,
 		Signer:              privateKeySigner,
-		Client:              baseConfig.EthRpcClient,
-		ClientFallback:      baseConfig.EthRpcClientFallback,
+		Client:              baseConfig.EthRpcPool,
 		metrics:             metrics,
 	}, nil
 }
@@ -89,11 +87,14 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 //   - If no receipt is found, but the batch state indicates the response has already been processed, it exits
 //     without an error (returning `nil, nil`).
 //   - An error if the process encounters a fatal issue (e.g., permanent failure in verifying balances or state).
-func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, onGasPriceBumped func(*big.Int)) (*types.Receipt, error) {
+func (w *AvsWriter) SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, onGasPriceBumped func(*big.Int)) (*types.Receipt, error) {
 	txOpts := *w.Signer.GetTxOpts()
 	txOpts.NoSend = true // simulate the transaction
-	simTx, err := w.RespondToTaskV2Retryable(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature, retry.SendToChainRetryParams())
+	simTx, err := w.RespondToTaskV2Retryable(ctx, &txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature, retry.SendToChainRetryParams())
 	if err != nil {
+		if w.isBatchAlreadyResponded(err, batchMerkleRoot) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -108,8 +109,8 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 
 	batchMerkleRootHashString := hex.EncodeToString(batchMerkleRoot[:])
 
-	respondToTaskV2Func := func() (*types.Receipt, error) {
-		gasPrice, err := utils.GetGasPriceRetryable(w.Client, w.ClientFallback, retry.NetworkRetryParams())
+	respondToTaskV2Func := func(ctx context.Context) (*types.Receipt, error) {
+		gasPrice, err := utils.GetGasPriceRetryable(ctx, w.Client, retry.NetworkRetryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -144,17 +145,14 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 		if i > 0 {
 			w.logger.Infof("Trying to get old sent transaction receipt before sending a new transaction", "merkle root", batchMerkleRootHashString)
 			for _, tx := range sentTxs {
-				receipt, _ := w.Client.TransactionReceipt(context.Background(), tx.Hash())
-				if receipt == nil {
-					receipt, _ = w.ClientFallback.TransactionReceipt(context.Background(), tx.Hash())
-					if receipt != nil {
-						w.checkIfAggregatorHadToPaidForBatcher(tx, batchIdentifierHash)
-						return receipt, nil
-					}
+				receipt, _ := w.Client.TransactionReceipt(ctx, tx.Hash())
+				if receipt != nil {
+					w.checkIfAggregatorHadToPaidForBatcher(tx, batchIdentifierHash)
+					return receipt, nil
 				}
 			}
 			w.logger.Infof("Receipts for old transactions not found, will check if the batch state has been responded", "merkle root", batchMerkleRootHashString)
-			batchState, _ := w.BatchesStateRetryable(&bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
+			batchState, _ := w.BatchesStateRetryable(ctx, &bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
 			if batchState.Responded {
 				w.logger.Infof("Batch state has been already responded", "merkle root", batchMerkleRootHashString)
 				return nil, nil
@@ -166,22 +164,29 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 
 		// We compare both Aggregator funds and Batcher balance in Aligned against respondToTaskFeeLimit
 		// Both are required to have some balance, more details inside the function
-		err = w.checkAggAndBatcherHaveEnoughBalance(simTx, txOpts, batchIdentifierHash, senderAddress)
+		err = w.checkAggAndBatcherHaveEnoughBalance(ctx, simTx, txOpts, batchIdentifierHash, senderAddress)
 		if err != nil {
 			w.logger.Errorf("Permanent error when checking aggregator and batcher balances, err %v", err, "merkle root", batchMerkleRootHashString)
 			return nil, retry.PermanentError{Inner: err}
 		}
 
 		w.logger.Infof("Sending RespondToTask transaction with a gas price of %v", txOpts.GasPrice, "merkle root", batchMerkleRootHashString)
-		realTx, err := w.RespondToTaskV2Retryable(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature, retry.SendToChainRetryParams())
+		realTx, err := w.RespondToTaskV2Retryable(ctx, &txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature, retry.SendToChainRetryParams())
 		if err != nil {
+			if w.isBatchAlreadyResponded(err, batchMerkleRoot) {
+				return nil, nil
+			}
 			w.logger.Errorf("Respond to task transaction err, %v", err, "merkle root", batchMerkleRootHashString)
+			// Stop bumping the fee when the revert can not be fixed by retrying
+			if revertErr, ok := DecodeRevert(err); ok && revertErr.Permanent {
+				return nil, retry.PermanentError{Inner: err}
+			}
 			return nil, err
 		}
 		sentTxs = append(sentTxs, realTx)
 
 		w.logger.Infof("Transaction sent, waiting for receipt", "merkle root", batchMerkleRootHashString)
-		receipt, err := utils.WaitForTransactionReceiptRetryable(w.Client, w.ClientFallback, realTx.Hash(), retry.WaitForTxRetryParams(timeToWaitBeforeBump))
+		receipt, err := utils.WaitForTransactionReceiptRetryable(ctx, w.Client, realTx.Hash(), retry.WaitForTxRetryParams(timeToWaitBeforeBump))
 		if receipt != nil {
 			w.checkIfAggregatorHadToPaidForBatcher(realTx, batchIdentifierHash)
 			return receipt, nil
@@ -201,14 +206,36 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 	// This just retries the bump of a fee in case of a timeout
 	// The wait is done before on WaitForTransactionReceiptRetryable, and all the functions are retriable,
 	// so this retry doesn't need to wait more time
-	return retry.RetryWithData(respondToTaskV2Func, retry.RespondToTaskV2())
+	return retry.RetryWithDataContext(ctx, "SendAggregatedResponse", respondToTaskV2Func, retry.RespondToTaskV2())
+}
+
+// TransactionReceipt returns the receipt of a transaction, or an error if it is not included in a block
+func (w *AvsWriter) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return w.Client.TransactionReceipt(ctx, txHash)
+}
+
+// isBatchAlreadyResponded tells whether the respond to task transaction reverted because the batch was already responded,
+// for example by a previous transaction of the fee bump, in which case there is nothing left to do.
+// The decoded reason of any revert is logged
+func (w *AvsWriter) isBatchAlreadyResponded(err error, batchMerkleRoot [32]byte) bool {
+	revertErr, ok := DecodeRevert(err)
+	if !ok {
+		return false
+	}
+	w.logger.Warn("Respond to task transaction reverted", "reason", revertErr.Reason, "permanent", revertErr.Permanent,
+		"merkle root", hex.EncodeToString(batchMerkleRoot[:]))
+	if revertErr.Name != "BatchAlreadyResponded" {
+		return false
+	}
+	w.logger.Infof("Batch state has been already responded", "merkle root", hex.EncodeToString(batchMerkleRoot[:]))
+	return true
 }
 
 // Calculates the transaction cost from the receipt and compares it with the batcher respondToTaskFeeLimit
 // if the tx cost was higher, then it means the aggregator has paid the difference for the batcher (txCost - respondToTaskFeeLimit) and so metrics are updated accordingly.
 // otherwise nothing is done.
 func (w *AvsWriter) checkIfAggregatorHadToPaidForBatcher(tx *types.Transaction, batchIdentifierHash [32]byte) {
-	batchState, err := w.BatchesStateRetryable(&bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
+	batchState, err := w.BatchesStateRetryable(context.Background(), &bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
 	if err != nil {
 		return
 	}
@@ -226,7 +253,7 @@ func (w *AvsWriter) checkIfAggregatorHadToPaidForBatcher(tx *types.Transaction,
 	}
 }
 
-func (w *AvsWriter) checkAggAndBatcherHaveEnoughBalance(tx *types.Transaction, txOpts bind.TransactOpts, batchIdentifierHash [32]byte, senderAddress [20]byte) error {
+func (w *AvsWriter) checkAggAndBatcherHaveEnoughBalance(ctx context.Context, tx *types.Transaction, txOpts bind.TransactOpts, batchIdentifierHash [32]byte, senderAddress [20]byte) error {
 	w.logger.Info("Checking if aggregator and batcher have enough balance for the transaction")
 	aggregatorAddress := txOpts.From
 	txGasAsBigInt := new(big.Int).SetUint64(tx.Gas())
@@ -234,32 +261,32 @@ func (w *AvsWriter) checkAggAndBatcherHaveEnoughBalance(tx *types.Transaction, t
 	txCost := new(big.Int).Mul(txGasAsBigInt, txGasPrice)
 	w.logger.Info("Transaction cost", "cost", txCost)
 
-	batchState, err := w.BatchesStateRetryable(&bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
+	batchState, err := w.BatchesStateRetryable(ctx, &bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
 	if err != nil {
 		w.logger.Error("Failed to get batch state", "error", err)
 		w.logger.Info("Proceeding to check balances against transaction cost")
-		return w.compareBalances(txCost, aggregatorAddress, senderAddress)
+		return w.compareBalances(ctx, txCost, aggregatorAddress, senderAddress)
 	}
 	respondToTaskFeeLimit := batchState.RespondToTaskFeeLimit
 	w.logger.Info("Checking balance against Batch RespondToTaskFeeLimit", "RespondToTaskFeeLimit", respondToTaskFeeLimit)
 	// Note: we compare both Aggregator funds and Batcher balance in Aligned against respondToTaskFeeLimit
 	// Batcher will pay up to respondToTaskFeeLimit, for this he needs that amount of funds in Aligned
 	// Aggregator will pay any extra cost, for this he needs at least respondToTaskFeeLimit in his balance
-	return w.compareBalances(respondToTaskFeeLimit, aggregatorAddress, senderAddress)
+	return w.compareBalances(ctx, respondToTaskFeeLimit, aggregatorAddress, senderAddress)
 }
 
-func (w *AvsWriter) compareBalances(amount *big.Int, aggregatorAddress common.Address, senderAddress [20]byte) error {
-	if err := w.compareAggregatorBalance(amount, aggregatorAddress); err != nil {
+func (w *AvsWriter) compareBalances(ctx context.Context, amount *big.Int, aggregatorAddress common.Address, senderAddress [20]byte) error {
+	if err := w.compareAggregatorBalance(ctx, amount, aggregatorAddress); err != nil {
 		return err
 	}
-	if err := w.compareBatcherBalance(amount, senderAddress); err != nil {
+	if err := w.compareBatcherBalance(ctx, amount, senderAddress); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (w *AvsWriter) compareAggregatorBalance(amount *big.Int, aggregatorAddress common.Address) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (w *AvsWriter) compareAggregatorBalance(ctx context.Context, amount *big.Int, aggregatorAddress common.Address) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	aggregatorBalance, err := w.BalanceAtRetryable(ctx, aggregatorAddress, nil, retry.NetworkRetryParams())
@@ -275,9 +302,9 @@ func (w *AvsWriter) compareAggregatorBalance(amount *big.Int, aggregatorAddress
 	return nil
 }
 
-func (w *AvsWriter) compareBatcherBalance(amount *big.Int, senderAddress [20]byte) error {
+func (w *AvsWriter) compareBatcherBalance(ctx context.Context, amount *big.Int, senderAddress [20]byte) error {
 	// Get batcher balance
-	batcherBalance, err := w.BatcherBalancesRetryable(&bind.CallOpts{}, senderAddress, retry.NetworkRetryParams())
+	batcherBalance, err := w.BatcherBalancesRetryable(ctx, &bind.CallOpts{}, senderAddress, retry.NetworkRetryParams())
 	if err != nil {
 		// Ignore and continue.
 		w.logger.Error("Failed to get batcherBalance", "error", err)
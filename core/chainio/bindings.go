@@ -1,30 +1,38 @@
 package chainio
 
 import (
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
 
 	csservicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/rpcpool"
 )
 
 type AvsServiceBindings struct {
-	ServiceManager         *csservicemanager.ContractAlignedLayerServiceManager
+	// Bound to the whole pool, so every call goes to the healthiest endpoint
+	ServiceManager *csservicemanager.ContractAlignedLayerServiceManager
+	// Bound to the pool starting from its second healthiest endpoint.
+	// Used to keep a redundant event subscription on a different endpoint than ServiceManager.
+	// With a log poller there are no subscriptions per endpoint, so it is bound to the same poller
 	ServiceManagerFallback *csservicemanager.ContractAlignedLayerServiceManager
-	ethClient              eth.InstrumentedClient
-	ethClientFallback      eth.InstrumentedClient
+	ethClient              rpcpool.Backend
 	logger                 logging.Logger
 }
 
-func NewAvsServiceBindings(serviceManagerAddr, blsOperatorStateRetrieverAddr gethcommon.Address, ethClient eth.InstrumentedClient, ethClientFallback eth.InstrumentedClient, logger logging.Logger) (*AvsServiceBindings, error) {
-	contractServiceManager, err := csservicemanager.NewContractAlignedLayerServiceManager(serviceManagerAddr, &ethClient)
+func NewAvsServiceBindings(serviceManagerAddr, blsOperatorStateRetrieverAddr gethcommon.Address, ethClient rpcpool.Backend, logger logging.Logger) (*AvsServiceBindings, error) {
+	contractServiceManager, err := csservicemanager.NewContractAlignedLayerServiceManager(serviceManagerAddr, ethClient)
 	if err != nil {
 		logger.Error("Failed to fetch AlignedLayerServiceManager contract", "err", err)
 		return nil, err
 	}
 
-	contractServiceManagerFallback, err := csservicemanager.NewContractAlignedLayerServiceManager(serviceManagerAddr, &ethClientFallback)
+	fallbackClient := ethClient
+	if pool, ok := ethClient.(*rpcpool.Pool); ok {
+		fallbackClient = pool.WithOffset(1)
+	}
+
+	contractServiceManagerFallback, err := csservicemanager.NewContractAlignedLayerServiceManager(serviceManagerAddr, fallbackClient)
 	if err != nil {
 		logger.Error("Failed to fetch AlignedLayerServiceManager contract", "err", err)
 		return nil, err
@@ -34,7 +42,6 @@ func NewAvsServiceBindings(serviceManagerAddr, blsOperatorStateRetrieverAddr get
 		ServiceManager:         contractServiceManager,
 		ServiceManagerFallback: contractServiceManagerFallback,
 		ethClient:              ethClient,
-		ethClientFallback:      ethClientFallback,
 		logger:                 logger,
 	}, nil
 }
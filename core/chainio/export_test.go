@@ -0,0 +1,66 @@
+package chainio
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+var KeepSubscribed = (*AvsSubscriber).keepSubscribed
+
+// NewTestAvsSubscriber returns a subscriber that reads the given chain, without contract bindings
+func NewTestAvsSubscriber(chain batchesChain, confirmationDepth uint64) *AvsSubscriber {
+	return &AvsSubscriber{
+		ConfirmationDepth:  confirmationDepth,
+		RemovedBatchesChan: make(chan [32]byte, RemovedBatchesChanSize),
+		chain:              chain,
+		logger:             logging.NewTextSLogger(io.Discard, nil),
+	}
+}
+
+// NewBatchesV3 runs the processing of the NewBatchV3 logs of a subscriber that reads the given chain,
+// as SubscribeToNewTasksV3 does, and sends the forwarded batches to Forwarded
+type NewBatchesV3 struct {
+	subscriber     *AvsSubscriber
+	batchesSet     map[[32]byte]struct{}
+	pendingBatches map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	mutex          *sync.Mutex
+	Forwarded      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+}
+
+func NewTestNewBatchesV3(chain batchesChain, confirmationDepth uint64) *NewBatchesV3 {
+	return &NewBatchesV3{
+		subscriber:     NewTestAvsSubscriber(chain, confirmationDepth),
+		batchesSet:     make(map[[32]byte]struct{}),
+		pendingBatches: make(map[[32]byte]*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3),
+		mutex:          &sync.Mutex{},
+		Forwarded:      make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, 100),
+	}
+}
+
+// Receive processes a log as received from the subscriptions
+func (b *NewBatchesV3) Receive(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	b.subscriber.processNewBatchV3(batch, b.batchesSet, b.pendingBatches, b.mutex, b.Forwarded)
+}
+
+// Scan processes the logs found by the block cursor after lastScannedBlock, and returns the new cursor
+func (b *NewBatchesV3) Scan(lastScannedBlock uint64) uint64 {
+	return b.subscriber.scanNewBatchesV3(context.Background(), lastScannedBlock, b.batchesSet, b.pendingBatches, b.mutex, b.Forwarded)
+}
+
+func (b *NewBatchesV3) DispatchConfirmed() {
+	b.subscriber.dispatchConfirmedBatchesV3(context.Background(), b.batchesSet, b.pendingBatches, b.mutex, b.Forwarded)
+}
+
+func (b *NewBatchesV3) Pending() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.pendingBatches)
+}
+
+func (b *NewBatchesV3) RemovedBatches() <-chan [32]byte {
+	return b.subscriber.RemovedBatchesChan
+}
@@ -0,0 +1,502 @@
+/*
+Package fake is an in-memory chain with an AlignedLayerServiceManager, to unit test the aggregator and the operator
+without an ethereum node.
+
+A Chain implements the AvsReader, AvsWriter and AvsSubscriber interfaces of both the aggregator and the operator.
+Tests drive it with SubmitBatch, RemoveBatch, DropResponse, ReplaceBlock and MineBlocks, and check the aggregated responses sent
+with RespondToTaskV2Calls. Events are delivered to the subscribed channels before the driving call returns.
+
+A StakingChain is an in-memory EigenLayer, to unit test the operator staking transactions. Tests drive it with SetBalance,
+SetAllowance, RegisterOperator, SetWithdrawalDelay and MineBlocks, and check the transactions sent with SentTxs.
+*/
+package fake
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+// RespondToTaskV2Call is an aggregated response included in the chain
+type RespondToTaskV2Call struct {
+	BatchMerkleRoot             [32]byte
+	SenderAddress               [20]byte
+	NonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature
+	TxHash                      common.Hash
+	BlockNumber                 uint64
+}
+
+type batch struct {
+	event *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	// Block the batch was responded at, 0 if it was not responded
+	respondedBlock uint64
+	txHash         common.Hash
+}
+
+type Chain struct {
+	mutex       sync.Mutex
+	blockNumber uint64
+	// Block returned for the 'finalized' tag, see Finalize
+	finalizedBlockNumber uint64
+	// Number of times each block was replaced, it changes the hash of its header, see ReplaceBlock
+	replacedBlocks map[uint64]uint64
+
+	batches map[[32]byte]*batch
+	// Batch identifier hashes in the order the batches were submitted
+	batchOrder           [][32]byte
+	receipts             map[common.Hash]*types.Receipt
+	respondToTaskV2Calls []RespondToTaskV2Call
+	respondToTaskV2Err   error
+	filterLogsErr        error
+	receiptErr           error
+	txCount              uint64
+
+	registeredOperators map[common.Address]bool
+	disabledVerifiers   *big.Int
+
+	newBatchV3Chans       []chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	newBatchV3ErrChans    []chan error
+	newBatchV3FromBlocks  []uint64
+	batchVerifiedChans    []chan [32]byte
+	verifierDisabledChans []chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled
+	verifierEnabledChans  []chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled
+	removedBatchesChan    chan [32]byte
+}
+
+func NewChain() *Chain {
+	return &Chain{
+		blockNumber:         1,
+		replacedBlocks:      make(map[uint64]uint64),
+		batches:             make(map[[32]byte]*batch),
+		receipts:            make(map[common.Hash]*types.Receipt),
+		registeredOperators: make(map[common.Address]bool),
+		disabledVerifiers:   big.NewInt(0),
+		removedBatchesChan:  make(chan [32]byte, chainio.RemovedBatchesChanSize),
+	}
+}
+
+// BatchIdentifierHash is the key of a batch in the AlignedLayerServiceManager
+func BatchIdentifierHash(batchMerkleRoot [32]byte, senderAddress [20]byte) [32]byte {
+	return [32]byte(crypto.Keccak256(batchMerkleRoot[:], senderAddress[:]))
+}
+
+// |---DRIVING THE CHAIN---|
+
+// SubmitBatch includes a createNewTask transaction in a new block, and emits its NewBatchV3 event
+func (c *Chain) SubmitBatch(batchMerkleRoot [32]byte, senderAddress common.Address, batchDataPointer string, respondToTaskFeeLimit *big.Int) *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3 {
+	c.mutex.Lock()
+	c.blockNumber++
+	txHash := c.nextTxHash()
+	event := &servicemanager.ContractAlignedLayerServiceManagerNewBatchV3{
+		BatchMerkleRoot:       batchMerkleRoot,
+		SenderAddress:         senderAddress,
+		TaskCreatedBlock:      uint32(c.blockNumber),
+		BatchDataPointer:      batchDataPointer,
+		RespondToTaskFeeLimit: respondToTaskFeeLimit,
+		Raw:                   types.Log{BlockNumber: c.blockNumber, BlockHash: c.header(c.blockNumber).Hash(), TxHash: txHash},
+	}
+	batchIdentifierHash := BatchIdentifierHash(batchMerkleRoot, senderAddress)
+	if _, ok := c.batches[batchIdentifierHash]; !ok {
+		c.batchOrder = append(c.batchOrder, batchIdentifierHash)
+	}
+	c.batches[batchIdentifierHash] = &batch{event: event}
+	newBatchV3Chans := c.newBatchV3Chans
+	c.mutex.Unlock()
+
+	for _, newBatchV3Chan := range newBatchV3Chans {
+		newBatchV3Chan <- event
+	}
+	return event
+}
+
+// RemoveBatch drops a batch as a reorg would, and sends its identifier hash to the removed batches channel
+func (c *Chain) RemoveBatch(batchIdentifierHash [32]byte) {
+	c.mutex.Lock()
+	if batch, ok := c.batches[batchIdentifierHash]; ok {
+		delete(c.receipts, batch.txHash)
+		delete(c.batches, batchIdentifierHash)
+	}
+	c.mutex.Unlock()
+
+	c.removedBatchesChan <- batchIdentifierHash
+}
+
+// DropResponse drops the transaction that responded a batch, as a reorg would
+func (c *Chain) DropResponse(batchIdentifierHash [32]byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if batch, ok := c.batches[batchIdentifierHash]; ok {
+		delete(c.receipts, batch.txHash)
+		batch.respondedBlock = 0
+		batch.txHash = common.Hash{}
+	}
+}
+
+// SetRespondToTaskV2Error makes the following aggregated responses fail with err, until it is set to nil
+func (c *Chain) SetRespondToTaskV2Error(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.respondToTaskV2Err = err
+}
+
+// SetFilterLogsError makes the following logs queries fail with err, until it is set to nil
+func (c *Chain) SetFilterLogsError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.filterLogsErr = err
+}
+
+// SetTransactionReceiptError makes the following receipt queries fail with err, until it is set to nil
+func (c *Chain) SetTransactionReceiptError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.receiptErr = err
+}
+
+// RespondToTaskV2Calls returns the aggregated responses included so far, in order
+func (c *Chain) RespondToTaskV2Calls() []RespondToTaskV2Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]RespondToTaskV2Call{}, c.respondToTaskV2Calls...)
+}
+
+// NewBatchV3Subscribers returns the number of channels subscribed to NewBatchV3 events,
+// to wait for a consumer to be listening before submitting batches
+func (c *Chain) NewBatchV3Subscribers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.newBatchV3Chans)
+}
+
+// NewBatchV3FromBlocks returns the block every NewBatchV3 subscription asked to be backfilled from, in order
+func (c *Chain) NewBatchV3FromBlocks() []uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]uint64{}, c.newBatchV3FromBlocks...)
+}
+
+// FailNewBatchV3Subscriptions sends err to the error channel of the current NewBatchV3 subscriptions,
+// which stop receiving events
+func (c *Chain) FailNewBatchV3Subscriptions(err error) {
+	c.mutex.Lock()
+	errChans := c.newBatchV3ErrChans
+	c.newBatchV3Chans = nil
+	c.newBatchV3ErrChans = nil
+	c.mutex.Unlock()
+
+	for _, errChan := range errChans {
+		errChan <- err
+	}
+}
+
+// ReplaceBlock replaces a block by another one at the same height, as a reorg would, so its hash changes.
+// The logs of the block are kept and no removed log is emitted, as when the subscriptions miss the reorg
+func (c *Chain) ReplaceBlock(blockNumber uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.replacedBlocks[blockNumber]++
+}
+
+func (c *Chain) MineBlocks(n uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.blockNumber += n
+}
+
+// Finalize marks the latest block as finalized
+func (c *Chain) Finalize() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.finalizedBlockNumber = c.blockNumber
+}
+
+func (c *Chain) RegisterOperator(address common.Address) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.registeredOperators[address] = true
+}
+
+// DisableVerifier sets the bit of the verifier in the disabled verifiers bitmap, and emits a VerifierDisabled event
+func (c *Chain) DisableVerifier(verifierIdx uint8) {
+	c.mutex.Lock()
+	c.blockNumber++
+	c.disabledVerifiers = new(big.Int).SetBit(c.disabledVerifiers, int(verifierIdx), 1)
+	event := &servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled{
+		VerifierIdx: verifierIdx,
+		Raw:         types.Log{BlockNumber: c.blockNumber},
+	}
+	verifierDisabledChans := c.verifierDisabledChans
+	c.mutex.Unlock()
+
+	for _, verifierDisabledChan := range verifierDisabledChans {
+		verifierDisabledChan <- event
+	}
+}
+
+// EnableVerifier clears the bit of the verifier in the disabled verifiers bitmap, and emits a VerifierEnabled event
+func (c *Chain) EnableVerifier(verifierIdx uint8) {
+	c.mutex.Lock()
+	c.blockNumber++
+	c.disabledVerifiers = new(big.Int).SetBit(c.disabledVerifiers, int(verifierIdx), 0)
+	event := &servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled{
+		VerifierIdx: verifierIdx,
+		Raw:         types.Log{BlockNumber: c.blockNumber},
+	}
+	verifierEnabledChans := c.verifierEnabledChans
+	c.mutex.Unlock()
+
+	for _, verifierEnabledChan := range verifierEnabledChans {
+		verifierEnabledChan <- event
+	}
+}
+
+// |---AVS READER---|
+
+func (c *Chain) IsOperatorRegistered(address common.Address) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.registeredOperators[address], nil
+}
+
+func (c *Chain) DisabledVerifiersRetryable(ctx context.Context, opts *bind.CallOpts, config *retry.RetryParams) (*big.Int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return new(big.Int).Set(c.disabledVerifiers), nil
+}
+
+// GetOldTaskHash returns the identifier hash of a batch created between nBlocksOld+interval and nBlocksOld blocks ago
+func (c *Chain) GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.blockNumber < nBlocksOld {
+		return nil, fmt.Errorf("latest block is less than nBlocksOld")
+	}
+	toBlock := c.blockNumber - nBlocksOld
+	fromBlock := uint64(0)
+	if toBlock > interval {
+		fromBlock = toBlock - interval
+	}
+	for _, batchIdentifierHash := range c.batchOrder {
+		batch, ok := c.batches[batchIdentifierHash]
+		if !ok {
+			continue
+		}
+		taskCreatedBlock := uint64(batch.event.TaskCreatedBlock)
+		if taskCreatedBlock >= fromBlock && taskCreatedBlock <= toBlock {
+			oldTaskHash := batchIdentifierHash
+			return &oldTaskHash, nil
+		}
+	}
+	return nil, nil
+}
+
+// |---AVS WRITER---|
+
+// SendAggregatedResponse includes a respondToTaskV2 transaction in a new block, and emits a BatchVerified event.
+// As the real writer, it returns no receipt and no error if the batch was already responded.
+func (c *Chain) SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, onGasPriceBumped func(*big.Int)) (*types.Receipt, error) {
+	c.mutex.Lock()
+	if c.respondToTaskV2Err != nil {
+		err := c.respondToTaskV2Err
+		c.mutex.Unlock()
+		return nil, err
+	}
+	batch, ok := c.batches[BatchIdentifierHash(batchMerkleRoot, senderAddress)]
+	if !ok {
+		c.mutex.Unlock()
+		return nil, &chainio.RevertError{
+			Name:   "BatchDoesNotExist",
+			Reason: fmt.Sprintf("BatchDoesNotExist(0x%s)", hex.EncodeToString(batchIdentifierHash[:])),
+		}
+	}
+	if batch.respondedBlock != 0 {
+		c.mutex.Unlock()
+		return nil, nil
+	}
+
+	c.blockNumber++
+	txHash := c.nextTxHash()
+	batch.respondedBlock = c.blockNumber
+	batch.txHash = txHash
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		BlockNumber: new(big.Int).SetUint64(c.blockNumber),
+	}
+	c.receipts[txHash] = receipt
+	c.respondToTaskV2Calls = append(c.respondToTaskV2Calls, RespondToTaskV2Call{
+		BatchMerkleRoot:             batchMerkleRoot,
+		SenderAddress:               senderAddress,
+		NonSignerStakesAndSignature: nonSignerStakesAndSignature,
+		TxHash:                      txHash,
+		BlockNumber:                 c.blockNumber,
+	})
+	batchVerifiedChans := c.batchVerifiedChans
+	c.mutex.Unlock()
+
+	for _, batchVerifiedChan := range batchVerifiedChans {
+		batchVerifiedChan <- batchIdentifierHash
+	}
+	return receipt, nil
+}
+
+// BatchesStateRetryable returns the state of a batch at opts.BlockNumber, or at the latest block if it is nil
+func (c *Chain) BatchesStateRetryable(ctx context.Context, opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
+	TaskCreatedBlock      uint32
+	Responded             bool
+	RespondToTaskFeeLimit *big.Int
+}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state := struct {
+		TaskCreatedBlock      uint32
+		Responded             bool
+		RespondToTaskFeeLimit *big.Int
+	}{RespondToTaskFeeLimit: big.NewInt(0)}
+
+	blockNumber := c.blockNumber
+	if opts != nil && opts.BlockNumber != nil {
+		blockNumber = opts.BlockNumber.Uint64()
+	}
+	batch, ok := c.batches[arg0]
+	if !ok || uint64(batch.event.TaskCreatedBlock) > blockNumber {
+		return state, nil
+	}
+	state.TaskCreatedBlock = batch.event.TaskCreatedBlock
+	state.Responded = batch.respondedBlock != 0 && batch.respondedBlock <= blockNumber
+	state.RespondToTaskFeeLimit = batch.event.RespondToTaskFeeLimit
+	return state, nil
+}
+
+func (c *Chain) BlockNumberRetryable(ctx context.Context, config *retry.RetryParams) (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.blockNumber, nil
+}
+
+// HeaderByNumberRetryable returns the header of a block, or of the latest or finalized block for their tags
+func (c *Chain) HeaderByNumberRetryable(ctx context.Context, blockNumber *big.Int, config *retry.RetryParams) (*types.Header, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	number := c.blockNumber
+	switch {
+	case blockNumber == nil:
+	case blockNumber.Int64() == int64(rpc.FinalizedBlockNumber):
+		number = c.finalizedBlockNumber
+	case blockNumber.Sign() < 0:
+	case blockNumber.Uint64() > c.blockNumber:
+		return nil, ethereum.NotFound
+	default:
+		number = blockNumber.Uint64()
+	}
+	return c.header(number), nil
+}
+
+// FilterNewBatchV3InRanges returns the NewBatchV3 events of the batches submitted from fromBlock to toBlock
+func (c *Chain) FilterNewBatchV3InRanges(ctx context.Context, fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.filterLogsErr != nil {
+		return nil, c.filterLogsErr
+	}
+	var logs []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	for _, batchIdentifierHash := range c.batchOrder {
+		batch, ok := c.batches[batchIdentifierHash]
+		if ok && batch.event.Raw.BlockNumber >= fromBlock && batch.event.Raw.BlockNumber <= toBlock {
+			logs = append(logs, *batch.event)
+		}
+	}
+	return logs, nil
+}
+
+func (c *Chain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.receiptErr != nil {
+		return nil, c.receiptErr
+	}
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// |---AVS SUBSCRIBER---|
+
+// SubscribeToNewTasksV2 only keeps the subscription open, as NewBatchV2 events are not emitted
+func (c *Chain) SubscribeToNewTasksV2(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
+	return make(chan error), nil
+}
+
+// SubscribeToNewTasksV3 delivers the batches submitted after subscribing, fromBlock is ignored
+func (c *Chain) SubscribeToNewTasksV3(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, fromBlock uint64) (chan error, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	errChan := make(chan error)
+	c.newBatchV3Chans = append(c.newBatchV3Chans, newTaskCreatedChan)
+	c.newBatchV3ErrChans = append(c.newBatchV3ErrChans, errChan)
+	c.newBatchV3FromBlocks = append(c.newBatchV3FromBlocks, fromBlock)
+	return errChan, nil
+}
+
+func (c *Chain) SubscribeToVerifierStatusChanges(ctx context.Context, verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled, verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled) (chan error, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.verifierDisabledChans = append(c.verifierDisabledChans, verifierDisabledChan)
+	c.verifierEnabledChans = append(c.verifierEnabledChans, verifierEnabledChan)
+	return make(chan error), nil
+}
+
+func (c *Chain) SubscribeToBatchVerified(ctx context.Context, batchVerifiedChan chan [32]byte) (chan error, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.batchVerifiedChans = append(c.batchVerifiedChans, batchVerifiedChan)
+	return make(chan error), nil
+}
+
+// RemovedBatches returns the channel RemoveBatch sends to. It is shared by all the consumers of the chain
+func (c *Chain) RemovedBatches() <-chan [32]byte {
+	return c.removedBatchesChan
+}
+
+// WaitForOneBlock mines the blocks up to the one after startBlock, instead of waiting for them
+func (c *Chain) WaitForOneBlock(ctx context.Context, startBlock uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.blockNumber <= startBlock {
+		c.blockNumber = startBlock + 1
+	}
+	return nil
+}
+
+// header returns the header of a block, whose hash changes every time the block is replaced.
+// Must be called with the mutex held
+func (c *Chain) header(blockNumber uint64) *types.Header {
+	return &types.Header{
+		Number: new(big.Int).SetUint64(blockNumber),
+		Extra:  binary.BigEndian.AppendUint64(nil, c.replacedBlocks[blockNumber]),
+	}
+}
+
+// nextTxHash returns a unique transaction hash. Must be called with the mutex held
+func (c *Chain) nextTxHash() common.Hash {
+	c.txCount++
+	return common.BytesToHash(crypto.Keccak256(binary.BigEndian.AppendUint64(nil, c.txCount)))
+}
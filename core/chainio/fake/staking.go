@@ -0,0 +1,565 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	erc20 "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IERC20"
+	istrategy "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IStrategy"
+	strategymanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StrategyManager"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Addresses of the contracts of a StakingChain
+var (
+	DelegationManagerAddr = common.HexToAddress("0xd1")
+	StrategyManagerAddr   = common.HexToAddress("0xd2")
+	SlasherAddr           = common.HexToAddress("0xd3")
+	AVSDirectoryAddr      = common.HexToAddress("0xd4")
+	StrategyAddr          = common.HexToAddress("0xd5")
+	TokenAddr             = common.HexToAddress("0xd6")
+)
+
+// StakingTokenSymbol and StakingTokenDecimals describe the token of the strategy of a StakingChain
+const (
+	StakingTokenSymbol   = "WETH"
+	StakingTokenDecimals = 18
+)
+
+// SentTx is a transaction sent through the TxManager of a StakingChain
+type SentTx struct {
+	From        common.Address
+	To          common.Address
+	Data        []byte
+	BlockNumber uint64
+}
+
+type allowanceKey struct {
+	owner   common.Address
+	spender common.Address
+}
+
+type queuedWithdrawal struct {
+	withdrawal delegationmanager.IDelegationManagerWithdrawal
+	pending    bool
+}
+
+// StakingChain is an in-memory chain with the EigenLayer DelegationManager and StrategyManager, and a single
+// strategy whose shares are worth one token each. It implements the eth.HttpBackend the contract bindings are
+// built with, and its TxManager includes each transaction in a new block. Calls that the contracts would revert
+// return an error carrying the revert string, as nodes do.
+type StakingChain struct {
+	mutex       sync.Mutex
+	blockNumber uint64
+
+	balances        map[common.Address]*big.Int
+	allowances      map[allowanceKey]*big.Int
+	shares          map[common.Address]*big.Int
+	operators       map[common.Address]bool
+	delegatedTo     map[common.Address]common.Address
+	nonces          map[common.Address]int64
+	withdrawalDelay uint64
+	withdrawals     map[common.Hash]*queuedWithdrawal
+	logs            []types.Log
+	sentTxs         []SentTx
+}
+
+func NewStakingChain() *StakingChain {
+	return &StakingChain{
+		blockNumber: 1,
+		balances:    make(map[common.Address]*big.Int),
+		allowances:  make(map[allowanceKey]*big.Int),
+		shares:      make(map[common.Address]*big.Int),
+		operators:   make(map[common.Address]bool),
+		delegatedTo: make(map[common.Address]common.Address),
+		nonces:      make(map[common.Address]int64),
+		withdrawals: make(map[common.Hash]*queuedWithdrawal),
+	}
+}
+
+// |---DRIVING THE CHAIN---|
+
+// SetBalance sets the balance of the strategy token of the owner
+func (c *StakingChain) SetBalance(owner common.Address, amount *big.Int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.balances[owner] = new(big.Int).Set(amount)
+}
+
+// SetAllowance sets the amount of the strategy token of the owner the spender can take
+func (c *StakingChain) SetAllowance(owner common.Address, spender common.Address, amount *big.Int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.allowances[allowanceKey{owner, spender}] = new(big.Int).Set(amount)
+}
+
+// RegisterOperator registers the address as an EigenLayer operator, without a delegation approver
+func (c *StakingChain) RegisterOperator(address common.Address) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.operators[address] = true
+}
+
+// SetWithdrawalDelay sets the number of blocks a queued withdrawal waits before it can be completed
+func (c *StakingChain) SetWithdrawalDelay(blocks uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.withdrawalDelay = blocks
+}
+
+// MineBlocks adds n empty blocks to the chain
+func (c *StakingChain) MineBlocks(n uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.blockNumber += n
+}
+
+// SentTxs returns the transactions sent through the TxManager, in order
+func (c *StakingChain) SentTxs() []SentTx {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]SentTx(nil), c.sentTxs...)
+}
+
+// TxManager returns a txmgr.TxManager that sends the transactions from the sender
+func (c *StakingChain) TxManager(sender common.Address) txmgr.TxManager {
+	return &stakingTxManager{chain: c, sender: sender}
+}
+
+// |---eth.HttpBackend---|
+
+func (c *StakingChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.blockNumber, nil
+}
+
+func (c *StakingChain) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(header), nil
+}
+
+func (c *StakingChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if number == nil || number.Sign() < 0 {
+		return &types.Header{Number: new(big.Int).SetUint64(c.blockNumber)}, nil
+	}
+	if number.Uint64() > c.blockNumber {
+		return nil, ethereum.NotFound
+	}
+	return &types.Header{Number: new(big.Int).Set(number)}, nil
+}
+
+func (c *StakingChain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if _, ok := stakingContractAbi(contract); ok {
+		return []byte{0x1}, nil
+	}
+	return nil, nil
+}
+
+func (c *StakingChain) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return c.CodeAt(ctx, account, nil)
+}
+
+func (c *StakingChain) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.execute(call.From, call.To, call.Data, false)
+}
+
+func (c *StakingChain) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, err := c.execute(call.From, call.To, call.Data, false); err != nil {
+		return 0, err
+	}
+	return stakingTxGas(call.Data), nil
+}
+
+func (c *StakingChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (c *StakingChain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000_000_000), nil
+}
+
+func (c *StakingChain) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000_000_000), nil
+}
+
+func (c *StakingChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return errors.New("fake: transactions are sent through the TxManager of the StakingChain")
+}
+
+func (c *StakingChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var logs []types.Log
+	for _, log := range c.logs {
+		if query.FromBlock != nil && log.BlockNumber < query.FromBlock.Uint64() {
+			continue
+		}
+		if query.ToBlock != nil && log.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Addresses) > 0 && !slices.Contains(query.Addresses, log.Address) {
+			continue
+		}
+		if len(query.Topics) > 0 && len(query.Topics[0]) > 0 && !slices.Contains(query.Topics[0], log.Topics[0]) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func (c *StakingChain) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("fake: log subscriptions are not supported by the StakingChain")
+}
+
+// |---CONTRACTS---|
+
+// execute runs the call on the contracts, and applies its effects if commit is set. Must be called with the mutex held
+func (c *StakingChain) execute(from common.Address, to *common.Address, data []byte, commit bool) ([]byte, error) {
+	if to == nil {
+		return nil, errors.New("fake: contract creation is not supported by the StakingChain")
+	}
+	contractAbi, ok := stakingContractAbi(*to)
+	if !ok || len(data) < 4 {
+		// Calls to accounts without code succeed without output
+		return nil, nil
+	}
+	method, err := contractAbi.MethodById(data[:4])
+	if err != nil {
+		return nil, newRevertError("")
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, newRevertError("")
+	}
+
+	var outputs []interface{}
+	switch *to {
+	case TokenAddr:
+		outputs, err = c.executeToken(from, method.Name, args, commit)
+	case StrategyAddr:
+		outputs, err = c.executeStrategy(method.Name, args)
+	case StrategyManagerAddr:
+		outputs, err = c.executeStrategyManager(from, method.Name, args, commit)
+	case DelegationManagerAddr:
+		outputs, err = c.executeDelegationManager(from, method.Name, args, commit)
+	default:
+		err = fmt.Errorf("fake: %s is not implemented", method.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(outputs...)
+}
+
+func (c *StakingChain) executeToken(from common.Address, method string, args []interface{}, commit bool) ([]interface{}, error) {
+	switch method {
+	case "symbol":
+		return []interface{}{StakingTokenSymbol}, nil
+	case "decimals":
+		return []interface{}{uint8(StakingTokenDecimals)}, nil
+	case "balanceOf":
+		return []interface{}{valueOf(c.balances, args[0].(common.Address))}, nil
+	case "allowance":
+		return []interface{}{valueOf(c.allowances, allowanceKey{args[0].(common.Address), args[1].(common.Address)})}, nil
+	case "approve":
+		if commit {
+			c.allowances[allowanceKey{from, args[0].(common.Address)}] = new(big.Int).Set(args[1].(*big.Int))
+		}
+		return []interface{}{true}, nil
+	}
+	return nil, fmt.Errorf("fake: IERC20.%s is not implemented", method)
+}
+
+func (c *StakingChain) executeStrategy(method string, args []interface{}) ([]interface{}, error) {
+	switch method {
+	case "underlyingToken":
+		return []interface{}{TokenAddr}, nil
+	case "sharesToUnderlyingView", "underlyingToSharesView":
+		return []interface{}{new(big.Int).Set(args[0].(*big.Int))}, nil
+	}
+	return nil, fmt.Errorf("fake: IStrategy.%s is not implemented", method)
+}
+
+func (c *StakingChain) executeStrategyManager(from common.Address, method string, args []interface{}, commit bool) ([]interface{}, error) {
+	switch method {
+	case "stakerStrategyShares":
+		if args[1].(common.Address) != StrategyAddr {
+			return []interface{}{big.NewInt(0)}, nil
+		}
+		return []interface{}{valueOf(c.shares, args[0].(common.Address))}, nil
+	case "depositIntoStrategy":
+		if args[0].(common.Address) != StrategyAddr || args[1].(common.Address) != TokenAddr {
+			return nil, newRevertError("StrategyManager.onlyStrategiesWhitelistedForDeposit: strategy not whitelisted")
+		}
+		amount := args[2].(*big.Int)
+		allowance := valueOf(c.allowances, allowanceKey{from, StrategyManagerAddr})
+		if allowance.Cmp(amount) < 0 {
+			return nil, newRevertError("ERC20: insufficient allowance")
+		}
+		balance := valueOf(c.balances, from)
+		if balance.Cmp(amount) < 0 {
+			return nil, newRevertError("ERC20: transfer amount exceeds balance")
+		}
+		if commit {
+			c.allowances[allowanceKey{from, StrategyManagerAddr}] = new(big.Int).Sub(allowance, amount)
+			c.balances[from] = new(big.Int).Sub(balance, amount)
+			c.shares[from] = new(big.Int).Add(valueOf(c.shares, from), amount)
+		}
+		return []interface{}{new(big.Int).Set(amount)}, nil
+	}
+	return nil, fmt.Errorf("fake: StrategyManager.%s is not implemented", method)
+}
+
+func (c *StakingChain) executeDelegationManager(from common.Address, method string, args []interface{}, commit bool) ([]interface{}, error) {
+	switch method {
+	case "slasher":
+		return []interface{}{SlasherAddr}, nil
+	case "strategyManager":
+		return []interface{}{StrategyManagerAddr}, nil
+	case "delegatedTo":
+		return []interface{}{c.delegatedTo[args[0].(common.Address)]}, nil
+	case "isOperator":
+		return []interface{}{c.operators[args[0].(common.Address)]}, nil
+	case "delegationApprover":
+		return []interface{}{common.Address{}}, nil
+	case "pendingWithdrawals":
+		queued, ok := c.withdrawals[common.Hash(args[0].([32]byte))]
+		return []interface{}{ok && queued.pending}, nil
+	case "getWithdrawalDelay":
+		return []interface{}{new(big.Int).SetUint64(c.withdrawalDelay)}, nil
+	case "delegateTo":
+		operator := args[0].(common.Address)
+		if c.delegatedTo[from] != (common.Address{}) {
+			return nil, newRevertError("DelegationManager._delegate: staker is already actively delegated")
+		}
+		if !c.operators[operator] {
+			return nil, newRevertError("DelegationManager._delegate: operator is not registered in EigenLayer")
+		}
+		if commit {
+			c.delegatedTo[from] = operator
+		}
+		return nil, nil
+	case "undelegate":
+		staker := args[0].(common.Address)
+		if c.delegatedTo[staker] == (common.Address{}) {
+			return nil, newRevertError("DelegationManager.undelegate: staker must be delegated to undelegate")
+		}
+		if staker != from {
+			return nil, newRevertError("DelegationManager.undelegate: caller cannot undelegate staker")
+		}
+		var roots [][32]byte
+		if commit {
+			if shares := valueOf(c.shares, staker); shares.Sign() > 0 {
+				roots = append(roots, c.queueWithdrawal(staker, staker, shares))
+			}
+			delete(c.delegatedTo, staker)
+		}
+		return []interface{}{roots}, nil
+	case "queueWithdrawals":
+		params := *abi.ConvertType(args[0], new([]delegationmanager.IDelegationManagerQueuedWithdrawalParams)).(*[]delegationmanager.IDelegationManagerQueuedWithdrawalParams)
+		shares := new(big.Int)
+		for _, param := range params {
+			if len(param.Strategies) != 1 || param.Strategies[0] != StrategyAddr || len(param.Shares) != 1 {
+				return nil, newRevertError("DelegationManager.queueWithdrawal: input length mismatch")
+			}
+			if param.Withdrawer != from {
+				return nil, newRevertError("DelegationManager.queueWithdrawal: withdrawer must be staker")
+			}
+			shares.Add(shares, param.Shares[0])
+		}
+		if shares.Cmp(valueOf(c.shares, from)) > 0 {
+			return nil, newRevertError("StrategyManager._removeShares: shareAmount too high")
+		}
+		roots := make([][32]byte, 0, len(params))
+		if commit {
+			for _, param := range params {
+				roots = append(roots, c.queueWithdrawal(from, param.Withdrawer, param.Shares[0]))
+			}
+		}
+		return []interface{}{roots}, nil
+	case "completeQueuedWithdrawal":
+		withdrawal := *abi.ConvertType(args[0], new(delegationmanager.IDelegationManagerWithdrawal)).(*delegationmanager.IDelegationManagerWithdrawal)
+		receiveAsTokens := args[3].(bool)
+		root, err := withdrawalRoot(withdrawal)
+		if err != nil {
+			return nil, err
+		}
+		queued, ok := c.withdrawals[root]
+		if !ok || !queued.pending {
+			return nil, newRevertError("DelegationManager._completeQueuedWithdrawal: action is not in queue")
+		}
+		if from != withdrawal.Withdrawer {
+			return nil, newRevertError("DelegationManager._completeQueuedWithdrawal: only withdrawer can complete action")
+		}
+		if uint64(withdrawal.StartBlock)+c.withdrawalDelay > c.blockNumber {
+			return nil, newRevertError("DelegationManager._completeQueuedWithdrawal: minWithdrawalDelayBlocks period has not yet passed")
+		}
+		if receiveAsTokens && len(args[1].([]common.Address)) != len(withdrawal.Strategies) {
+			return nil, newRevertError("DelegationManager._completeQueuedWithdrawal: input length mismatch")
+		}
+		if commit {
+			queued.pending = false
+			if receiveAsTokens {
+				c.balances[from] = new(big.Int).Add(valueOf(c.balances, from), withdrawal.Shares[0])
+			} else {
+				c.shares[from] = new(big.Int).Add(valueOf(c.shares, from), withdrawal.Shares[0])
+			}
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("fake: DelegationManager.%s is not implemented", method)
+}
+
+// queueWithdrawal removes the shares of the staker and emits the WithdrawalQueued event. Must be called with the mutex held
+func (c *StakingChain) queueWithdrawal(staker common.Address, withdrawer common.Address, shares *big.Int) [32]byte {
+	c.shares[staker] = new(big.Int).Sub(valueOf(c.shares, staker), shares)
+	withdrawal := delegationmanager.IDelegationManagerWithdrawal{
+		Staker:      staker,
+		DelegatedTo: c.delegatedTo[staker],
+		Withdrawer:  withdrawer,
+		Nonce:       big.NewInt(c.nonces[staker]),
+		StartBlock:  uint32(c.blockNumber),
+		Strategies:  []common.Address{StrategyAddr},
+		Shares:      []*big.Int{new(big.Int).Set(shares)},
+	}
+	c.nonces[staker]++
+
+	// The withdrawal is built from valid values, so neither the root nor the event can fail to be packed
+	root, _ := withdrawalRoot(withdrawal)
+	c.withdrawals[root] = &queuedWithdrawal{withdrawal: withdrawal, pending: true}
+	event := delegationManagerAbi().Events["WithdrawalQueued"]
+	data, _ := event.Inputs.NonIndexed().Pack(root, withdrawal)
+	c.logs = append(c.logs, types.Log{
+		Address:     DelegationManagerAddr,
+		Topics:      []common.Hash{event.ID},
+		Data:        data,
+		BlockNumber: c.blockNumber,
+	})
+	return root
+}
+
+type stakingTxManager struct {
+	chain  *StakingChain
+	sender common.Address
+}
+
+// Send includes the transaction in a new block, or returns the revert of the call without including it
+func (m *stakingTxManager) Send(ctx context.Context, tx *types.Transaction, waitForReceipt bool) (*types.Receipt, error) {
+	c := m.chain
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// The block the transaction is included in
+	c.blockNumber++
+	if _, err := c.execute(m.sender, tx.To(), tx.Data(), true); err != nil {
+		c.blockNumber--
+		return nil, err
+	}
+	c.sentTxs = append(c.sentTxs, SentTx{From: m.sender, To: *tx.To(), Data: tx.Data(), BlockNumber: c.blockNumber})
+	return &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      common.BytesToHash(crypto.Keccak256(tx.Data(), new(big.Int).SetUint64(c.blockNumber).Bytes())),
+		BlockNumber: new(big.Int).SetUint64(c.blockNumber),
+		GasUsed:     stakingTxGas(tx.Data()),
+	}, nil
+}
+
+func (m *stakingTxManager) GetNoSendTxOpts() (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From:   m.sender,
+		NoSend: true,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+	}, nil
+}
+
+// stakingRevertError is returned for the calls the contracts revert, like the errors of the nodes
+type stakingRevertError struct {
+	reason string
+	data   string
+}
+
+func newRevertError(reason string) stakingRevertError {
+	// Error(string) selector, followed by the ABI encoded reason
+	data := append([]byte{0x08, 0xc3, 0x79, 0xa0}, encodeString(reason)...)
+	return stakingRevertError{reason: reason, data: hexutil.Encode(data)}
+}
+
+func (e stakingRevertError) Error() string          { return "execution reverted: " + e.reason }
+func (e stakingRevertError) ErrorCode() int         { return 3 }
+func (e stakingRevertError) ErrorData() interface{} { return e.data }
+
+func encodeString(value string) []byte {
+	stringType, _ := abi.NewType("string", "", nil)
+	encoded, _ := abi.Arguments{{Type: stringType}}.Pack(value)
+	return encoded
+}
+
+func stakingContractAbi(address common.Address) (*abi.ABI, bool) {
+	var metaData *bind.MetaData
+	switch address {
+	case TokenAddr:
+		metaData = erc20.ContractIERC20MetaData
+	case StrategyAddr:
+		metaData = istrategy.ContractIStrategyMetaData
+	case StrategyManagerAddr:
+		metaData = strategymanager.ContractStrategyManagerMetaData
+	case DelegationManagerAddr:
+		metaData = delegationmanager.ContractDelegationManagerMetaData
+	default:
+		return nil, false
+	}
+	contractAbi, err := metaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return contractAbi, true
+}
+
+func delegationManagerAbi() *abi.ABI {
+	contractAbi, _ := stakingContractAbi(DelegationManagerAddr)
+	return contractAbi
+}
+
+// withdrawalRoot is the keccak256 of the ABI encoded withdrawal, as computed by calculateWithdrawalRoot
+func withdrawalRoot(withdrawal delegationmanager.IDelegationManagerWithdrawal) (common.Hash, error) {
+	encoded, err := delegationManagerAbi().Methods["calculateWithdrawalRoot"].Inputs.Pack(withdrawal)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+func stakingTxGas(data []byte) uint64 {
+	return 21_000 + 16*uint64(len(data))
+}
+
+func valueOf[K comparable](values map[K]*big.Int, key K) *big.Int {
+	if value, ok := values[key]; ok {
+		return new(big.Int).Set(value)
+	}
+	return big.NewInt(0)
+}
@@ -0,0 +1,122 @@
+package chainio
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	contractMulticall3 "github.com/yetanotherco/aligned_layer/contracts/bindings/Multicall3"
+)
+
+const (
+	// Address of Multicall3 on every public network. On anvil, the stand-in in contracts/src/core/Multicall3.sol
+	// is placed at this address by the deployment script
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+	// Calls aggregated in a single multicall
+	MulticallBatchSize = 100
+)
+
+// BatchState is the value stored in the batchesState mapping of the AVS contract
+type BatchState struct {
+	TaskCreatedBlock      uint32
+	Responded             bool
+	RespondToTaskFeeLimit *big.Int
+}
+
+// BatchesStates returns the state of every batch, fetching up to MulticallBatchSize states per call through Multicall3.
+// If a multicall fails, for example because Multicall3 is not deployed, the states of that chunk are fetched one by one
+func (r *AvsReader) BatchesStates(opts *bind.CallOpts, batchIdentifierHashes [][32]byte) ([]BatchState, error) {
+	serviceManagerAbi, err := servicemanager.ContractAlignedLayerServiceManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]BatchState, 0, len(batchIdentifierHashes))
+	for start := 0; start < len(batchIdentifierHashes); start += MulticallBatchSize {
+		end := min(start+MulticallBatchSize, len(batchIdentifierHashes))
+		chunk := batchIdentifierHashes[start:end]
+
+		chunkStates, err := r.multicallBatchesStates(opts, serviceManagerAbi, chunk)
+		if err != nil {
+			r.logger.Warn("Failed to get batches states through Multicall3, getting them one by one", "err", err)
+			chunkStates, err = r.batchesStatesOneByOne(opts, chunk)
+			if err != nil {
+				return nil, err
+			}
+		}
+		states = append(states, chunkStates...)
+	}
+
+	return states, nil
+}
+
+func (r *AvsReader) multicallBatchesStates(opts *bind.CallOpts, serviceManagerAbi *abi.ABI, batchIdentifierHashes [][32]byte) ([]BatchState, error) {
+	calls := make([]contractMulticall3.Multicall3Call3, 0, len(batchIdentifierHashes))
+	for _, batchIdentifierHash := range batchIdentifierHashes {
+		callData, err := serviceManagerAbi.Pack("batchesState", batchIdentifierHash)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, contractMulticall3.Multicall3Call3{
+			Target:       r.AlignedLayerServiceManagerAddr,
+			AllowFailure: false,
+			CallData:     callData,
+		})
+	}
+
+	// aggregate3 is payable, so the binding only has a transactor for it, but it can be called as a view function
+	var out []interface{}
+	caller := contractMulticall3.ContractMulticall3CallerRaw{Contract: &r.multicall3.ContractMulticall3Caller}
+	if err := caller.Call(opts, &out, "aggregate3", calls); err != nil {
+		return nil, err
+	}
+	results := *abi.ConvertType(out[0], new([]contractMulticall3.Multicall3Result)).(*[]contractMulticall3.Multicall3Result)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+	}
+
+	states := make([]BatchState, 0, len(results))
+	for _, result := range results {
+		state, err := decodeBatchState(serviceManagerAbi, result.ReturnData)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (r *AvsReader) batchesStatesOneByOne(opts *bind.CallOpts, batchIdentifierHashes [][32]byte) ([]BatchState, error) {
+	states := make([]BatchState, 0, len(batchIdentifierHashes))
+	for _, batchIdentifierHash := range batchIdentifierHashes {
+		state, err := r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.BatchesState(opts, batchIdentifierHash)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, BatchState(state))
+	}
+	return states, nil
+}
+
+func decodeBatchState(serviceManagerAbi *abi.ABI, returnData []byte) (BatchState, error) {
+	out, err := serviceManagerAbi.Unpack("batchesState", returnData)
+	if err != nil {
+		return BatchState{}, err
+	}
+	return BatchState{
+		TaskCreatedBlock:      *abi.ConvertType(out[0], new(uint32)).(*uint32),
+		Responded:             *abi.ConvertType(out[1], new(bool)).(*bool),
+		RespondToTaskFeeLimit: *abi.ConvertType(out[2], new(*big.Int)).(**big.Int),
+	}, nil
+}
+
+// multicall3Address returns the configured Multicall3 address, or the canonical one if none is configured
+func multicall3Address(configured ethcommon.Address) ethcommon.Address {
+	if configured == (ethcommon.Address{}) {
+		return ethcommon.HexToAddress(Multicall3Address)
+	}
+	return configured
+}
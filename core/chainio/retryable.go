@@ -18,22 +18,17 @@ import (
 /*
 RespondToTaskV2Retryable
 Send a transaction to the AVS contract to respond to a task.
-- All errors are considered Transient Errors
+- Reverts are decoded and classified with ClassifyContractError: permanent ones, such as BatchAlreadyResponded or an invalid signature, are `PermanentError`'s
+- Every other error is considered a Transient Error
 - Retry times (3 retries): 12 sec (1 Blocks), 24 sec (2 Blocks), 48 sec (4 Blocks)
-- NOTE: Contract call reverts are not considered `PermanentError`'s as block reorg's may lead to contract call revert in which case the aggregator should retry.
+- NOTE: Reverts that a block reorg may undo, such as BatchDoesNotExist, are not considered `PermanentError`'s, in which case the aggregator should retry.
 */
-func (w *AvsWriter) RespondToTaskV2Retryable(opts *bind.TransactOpts, batchMerkleRoot [32]byte, senderAddress common.Address, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, config *retry.RetryParams) (*types.Transaction, error) {
-	respondToTaskV2_func := func() (*types.Transaction, error) {
-		// Try with main connection
-		tx, err := w.AvsContractBindings.ServiceManager.RespondToTaskV2(opts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
-		if err != nil {
-			// If error try with fallback
-			tx, err = w.AvsContractBindings.ServiceManagerFallback.RespondToTaskV2(opts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
-		}
-
-		return tx, err
+func (w *AvsWriter) RespondToTaskV2Retryable(ctx context.Context, opts *bind.TransactOpts, batchMerkleRoot [32]byte, senderAddress common.Address, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, config *retry.RetryParams) (*types.Transaction, error) {
+	respondToTaskV2_func := func(ctx context.Context) (*types.Transaction, error) {
+		tx, err := w.AvsContractBindings.ServiceManager.RespondToTaskV2(transactOptsWithContext(opts, ctx), batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+		return tx, ClassifyContractError(err)
 	}
-	return retry.RetryWithData(respondToTaskV2_func, config)
+	return retry.RetryWithDataContext(ctx, "RespondToTaskV2", respondToTaskV2_func, config)
 }
 
 /*
@@ -42,26 +37,20 @@ Get the state of a batch from the AVS contract.
 - All errors are considered Transient Errors
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec
 */
-func (w *AvsWriter) BatchesStateRetryable(opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
+func (w *AvsWriter) BatchesStateRetryable(ctx context.Context, opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
 	TaskCreatedBlock      uint32
 	Responded             bool
 	RespondToTaskFeeLimit *big.Int
 }, error) {
 
-	batchesState_func := func() (struct {
+	batchesState_func := func(ctx context.Context) (struct {
 		TaskCreatedBlock      uint32
 		Responded             bool
 		RespondToTaskFeeLimit *big.Int
 	}, error) {
-		// Try with main connection
-		state, err := w.AvsContractBindings.ServiceManager.BatchesState(opts, arg0)
-		if err != nil {
-			// If error try with fallback connection
-			state, err = w.AvsContractBindings.ServiceManagerFallback.BatchesState(opts, arg0)
-		}
-		return state, err
+		return w.AvsContractBindings.ServiceManager.BatchesState(callOptsWithContext(opts, ctx), arg0)
 	}
-	return retry.RetryWithData(batchesState_func, config)
+	return retry.RetryWithDataContext(ctx, "BatchesState", batchesState_func, config)
 }
 
 /*
@@ -70,17 +59,11 @@ Get the balance of a batcher from the AVS contract.
 - All errors are considered Transient Errors
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec
 */
-func (w *AvsWriter) BatcherBalancesRetryable(opts *bind.CallOpts, senderAddress common.Address, config *retry.RetryParams) (*big.Int, error) {
-	batcherBalances_func := func() (*big.Int, error) {
-		// Try with main connection
-		batcherBalance, err := w.AvsContractBindings.ServiceManager.BatchersBalances(opts, senderAddress)
-		if err != nil {
-			// If error try with fallback connection
-			batcherBalance, err = w.AvsContractBindings.ServiceManagerFallback.BatchersBalances(opts, senderAddress)
-		}
-		return batcherBalance, err
+func (w *AvsWriter) BatcherBalancesRetryable(ctx context.Context, opts *bind.CallOpts, senderAddress common.Address, config *retry.RetryParams) (*big.Int, error) {
+	batcherBalances_func := func(ctx context.Context) (*big.Int, error) {
+		return w.AvsContractBindings.ServiceManager.BatchersBalances(callOptsWithContext(opts, ctx), senderAddress)
 	}
-	return retry.RetryWithData(batcherBalances_func, config)
+	return retry.RetryWithDataContext(ctx, "BatcherBalances", batcherBalances_func, config)
 }
 
 /*
@@ -92,16 +75,52 @@ TODO: it gets the balance from an Address, not necessarily an aggregator. The na
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
 func (w *AvsWriter) BalanceAtRetryable(ctx context.Context, aggregatorAddress common.Address, blockNumber *big.Int, config *retry.RetryParams) (*big.Int, error) {
-	balanceAt_func := func() (*big.Int, error) {
-		// Try with main connection
-		aggregatorBalance, err := w.Client.BalanceAt(ctx, aggregatorAddress, blockNumber)
-		if err != nil {
-			// If error try with fallback connection
-			aggregatorBalance, err = w.ClientFallback.BalanceAt(ctx, aggregatorAddress, blockNumber)
-		}
-		return aggregatorBalance, err
+	balanceAt_func := func(ctx context.Context) (*big.Int, error) {
+		return w.Client.BalanceAt(ctx, aggregatorAddress, blockNumber)
+	}
+	return retry.RetryWithDataContext(ctx, "BalanceAt", balanceAt_func, config)
+}
+
+/*
+BlockNumberRetryable
+Get the latest block number from Ethereum
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func (w *AvsWriter) BlockNumberRetryable(ctx context.Context, config *retry.RetryParams) (uint64, error) {
+	latestBlock_func := func(ctx context.Context) (uint64, error) {
+		return w.Client.BlockNumber(ctx)
+	}
+	return retry.RetryWithDataContext(ctx, "BlockNumber", latestBlock_func, config)
+}
+
+/*
+HeaderByNumberRetryable
+Get the header of the block at blockNumber from Ethereum.
+blockNumber can also be one of the rpc block tags, such as rpc.FinalizedBlockNumber.
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func (w *AvsWriter) HeaderByNumberRetryable(ctx context.Context, blockNumber *big.Int, config *retry.RetryParams) (*types.Header, error) {
+	headerByNumber_func := func(ctx context.Context) (*types.Header, error) {
+		return w.Client.HeaderByNumber(ctx, blockNumber)
+	}
+	return retry.RetryWithDataContext(ctx, "HeaderByNumber", headerByNumber_func, config)
+}
+
+// |---AVS_READER---|
+
+/*
+DisabledVerifiersRetryable
+Get the bitmap of disabled verifiers from the AVS contract.
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func (r *AvsReader) DisabledVerifiersRetryable(ctx context.Context, opts *bind.CallOpts, config *retry.RetryParams) (*big.Int, error) {
+	disabledVerifiers_func := func(ctx context.Context) (*big.Int, error) {
+		return r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.DisabledVerifiers(callOptsWithContext(opts, ctx))
 	}
-	return retry.RetryWithData(balanceAt_func, config)
+	return retry.RetryWithDataContext(ctx, "DisabledVerifiers", disabledVerifiers_func, config)
 }
 
 // |---AVS_SUBSCRIBER---|
@@ -113,16 +132,23 @@ Get the latest block number from Ethereum
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
 func (s *AvsSubscriber) BlockNumberRetryable(ctx context.Context, config *retry.RetryParams) (uint64, error) {
-	latestBlock_func := func() (uint64, error) {
-		// Try with main connection
-		latestBlock, err := s.AvsContractBindings.ethClient.BlockNumber(ctx)
-		if err != nil {
-			// If error try with fallback connection
-			latestBlock, err = s.AvsContractBindings.ethClientFallback.BlockNumber(ctx)
-		}
-		return latestBlock, err
+	latestBlock_func := func(ctx context.Context) (uint64, error) {
+		return s.AvsContractBindings.ethClient.BlockNumber(ctx)
 	}
-	return retry.RetryWithData(latestBlock_func, config)
+	return retry.RetryWithDataContext(ctx, "BlockNumber", latestBlock_func, config)
+}
+
+/*
+HeaderByNumberRetryable
+Get the header of the canonical block at blockNumber from Ethereum
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func (s *AvsSubscriber) HeaderByNumberRetryable(ctx context.Context, blockNumber *big.Int, config *retry.RetryParams) (*types.Header, error) {
+	headerByNumber_func := func(ctx context.Context) (*types.Header, error) {
+		return s.AvsContractBindings.ethClient.HeaderByNumber(ctx, blockNumber)
+	}
+	return retry.RetryWithDataContext(ctx, "HeaderByNumber", headerByNumber_func, config)
 }
 
 /*
@@ -131,11 +157,11 @@ Get NewBatchV2 logs from the AVS contract.
 - All errors are considered Transient Errors
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
-func (s *AvsSubscriber) FilterBatchV2Retryable(opts *bind.FilterOpts, batchMerkleRoot [][32]byte, config *retry.RetryParams) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2Iterator, error) {
-	filterNewBatchV2_func := func() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2Iterator, error) {
-		return s.AvsContractBindings.ServiceManager.FilterNewBatchV2(opts, batchMerkleRoot)
+func (s *AvsSubscriber) FilterBatchV2Retryable(ctx context.Context, opts *bind.FilterOpts, batchMerkleRoot [][32]byte, config *retry.RetryParams) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2Iterator, error) {
+	filterNewBatchV2_func := func(ctx context.Context) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2Iterator, error) {
+		return s.AvsContractBindings.ServiceManager.FilterNewBatchV2(filterOptsWithContext(opts, ctx), batchMerkleRoot)
 	}
-	return retry.RetryWithData(filterNewBatchV2_func, config)
+	return retry.RetryWithDataContext(ctx, "FilterBatchV2", filterNewBatchV2_func, config)
 }
 
 /*
@@ -144,11 +170,11 @@ Get NewBatchV3 logs from the AVS contract.
 - All errors are considered Transient Errors
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
-func (s *AvsSubscriber) FilterBatchV3Retryable(opts *bind.FilterOpts, batchMerkleRoot [][32]byte, config *retry.RetryParams) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3Iterator, error) {
-	filterNewBatchV2_func := func() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3Iterator, error) {
-		return s.AvsContractBindings.ServiceManager.FilterNewBatchV3(opts, batchMerkleRoot)
+func (s *AvsSubscriber) FilterBatchV3Retryable(ctx context.Context, opts *bind.FilterOpts, batchMerkleRoot [][32]byte, config *retry.RetryParams) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3Iterator, error) {
+	filterNewBatchV2_func := func(ctx context.Context) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3Iterator, error) {
+		return s.AvsContractBindings.ServiceManager.FilterNewBatchV3(filterOptsWithContext(opts, ctx), batchMerkleRoot)
 	}
-	return retry.RetryWithData(filterNewBatchV2_func, config)
+	return retry.RetryWithDataContext(ctx, "FilterBatchV3", filterNewBatchV2_func, config)
 }
 
 /*
@@ -157,20 +183,20 @@ Get the state of a batch from the AVS contract.
 - All errors are considered Transient Errors
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec
 */
-func (s *AvsSubscriber) BatchesStateRetryable(opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
+func (s *AvsSubscriber) BatchesStateRetryable(ctx context.Context, opts *bind.CallOpts, arg0 [32]byte, config *retry.RetryParams) (struct {
 	TaskCreatedBlock      uint32
 	Responded             bool
 	RespondToTaskFeeLimit *big.Int
 }, error) {
-	batchState_func := func() (struct {
+	batchState_func := func(ctx context.Context) (struct {
 		TaskCreatedBlock      uint32
 		Responded             bool
 		RespondToTaskFeeLimit *big.Int
 	}, error) {
-		return s.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.BatchesState(opts, arg0)
+		return s.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.BatchesState(callOptsWithContext(opts, ctx), arg0)
 	}
 
-	return retry.RetryWithData(batchState_func, config)
+	return retry.RetryWithDataContext(ctx, "BatchesState", batchState_func, config)
 }
 
 /*
@@ -180,16 +206,10 @@ Subscribe to new heads from the Ethereum node.
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
 func (s *AvsSubscriber) SubscribeNewHeadRetryable(ctx context.Context, c chan<- *types.Header, config *retry.RetryParams) (ethereum.Subscription, error) {
-	subscribeNewHead_func := func() (ethereum.Subscription, error) {
-		// Try with main connection
-		sub, err := s.AvsContractBindings.ethClient.SubscribeNewHead(ctx, c)
-		if err != nil {
-			// If error try with fallback connection
-			sub, err = s.AvsContractBindings.ethClientFallback.SubscribeNewHead(ctx, c)
-		}
-		return sub, err
+	subscribeNewHead_func := func(ctx context.Context) (ethereum.Subscription, error) {
+		return s.AvsContractBindings.ethClient.SubscribeNewHead(ctx, c)
 	}
-	return retry.RetryWithData(subscribeNewHead_func, config)
+	return retry.RetryWithDataContext(ctx, "SubscribeNewHead", subscribeNewHead_func, config)
 }
 
 /*
@@ -199,16 +219,17 @@ Subscribe to NewBatchV2 logs from the AVS contract.
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
 func SubscribeToNewTasksV2Retryable(
+	ctx context.Context,
 	opts *bind.WatchOpts,
 	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
 	newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2,
 	batchMerkleRoot [][32]byte,
 	config *retry.RetryParams,
 ) (event.Subscription, error) {
-	subscribe_func := func() (event.Subscription, error) {
-		return serviceManager.WatchNewBatchV2(opts, newTaskCreatedChan, batchMerkleRoot)
+	subscribe_func := func(ctx context.Context) (event.Subscription, error) {
+		return serviceManager.WatchNewBatchV2(watchOptsWithContext(opts, ctx), newTaskCreatedChan, batchMerkleRoot)
 	}
-	return retry.RetryWithData(subscribe_func, config)
+	return retry.RetryWithDataContext(ctx, "SubscribeToNewTasksV2", subscribe_func, config)
 }
 
 /*
@@ -218,14 +239,102 @@ Subscribe to NewBatchV3 logs from the AVS contract.
 - Retry times (3 retries): 1 sec, 2 sec, 4 sec.
 */
 func SubscribeToNewTasksV3Retryable(
+	ctx context.Context,
 	opts *bind.WatchOpts,
 	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
 	newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3,
 	batchMerkleRoot [][32]byte,
 	config *retry.RetryParams,
 ) (event.Subscription, error) {
-	subscribe_func := func() (event.Subscription, error) {
-		return serviceManager.WatchNewBatchV3(opts, newTaskCreatedChan, batchMerkleRoot)
+	subscribe_func := func(ctx context.Context) (event.Subscription, error) {
+		return serviceManager.WatchNewBatchV3(watchOptsWithContext(opts, ctx), newTaskCreatedChan, batchMerkleRoot)
+	}
+	return retry.RetryWithDataContext(ctx, "SubscribeToNewTasksV3", subscribe_func, config)
+}
+
+/*
+SubscribeToVerifierDisabledRetryable
+Subscribe to VerifierDisabled logs from the AVS contract.
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func SubscribeToVerifierDisabledRetryable(
+	ctx context.Context,
+	opts *bind.WatchOpts,
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+	config *retry.RetryParams,
+) (event.Subscription, error) {
+	subscribe_func := func(ctx context.Context) (event.Subscription, error) {
+		return serviceManager.WatchVerifierDisabled(watchOptsWithContext(opts, ctx), verifierDisabledChan, nil)
+	}
+	return retry.RetryWithDataContext(ctx, "SubscribeToVerifierDisabled", subscribe_func, config)
+}
+
+/*
+SubscribeToVerifierEnabledRetryable
+Subscribe to VerifierEnabled logs from the AVS contract.
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func SubscribeToVerifierEnabledRetryable(
+	ctx context.Context,
+	opts *bind.WatchOpts,
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+	config *retry.RetryParams,
+) (event.Subscription, error) {
+	subscribe_func := func(ctx context.Context) (event.Subscription, error) {
+		return serviceManager.WatchVerifierEnabled(watchOptsWithContext(opts, ctx), verifierEnabledChan, nil)
+	}
+	return retry.RetryWithDataContext(ctx, "SubscribeToVerifierEnabled", subscribe_func, config)
+}
+
+/*
+SubscribeToBatchVerifiedRetryable
+Subscribe to BatchVerified logs from the AVS contract.
+- All errors are considered Transient Errors
+- Retry times (3 retries): 1 sec, 2 sec, 4 sec.
+*/
+func SubscribeToBatchVerifiedRetryable(
+	ctx context.Context,
+	opts *bind.WatchOpts,
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	batchVerifiedChan chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified,
+	config *retry.RetryParams,
+) (event.Subscription, error) {
+	subscribe_func := func(ctx context.Context) (event.Subscription, error) {
+		return serviceManager.WatchBatchVerified(watchOptsWithContext(opts, ctx), batchVerifiedChan, nil)
 	}
-	return retry.RetryWithData(subscribe_func, config)
+	return retry.RetryWithDataContext(ctx, "SubscribeToBatchVerified", subscribe_func, config)
+}
+
+// The contract calls of the retryables run with the context of each attempt, so the helpers below
+// return a copy of the given opts with that context
+
+func callOptsWithContext(opts *bind.CallOpts, ctx context.Context) *bind.CallOpts {
+	withContext := bind.CallOpts{}
+	if opts != nil {
+		withContext = *opts
+	}
+	withContext.Context = ctx
+	return &withContext
+}
+
+func transactOptsWithContext(opts *bind.TransactOpts, ctx context.Context) *bind.TransactOpts {
+	withContext := *opts
+	withContext.Context = ctx
+	return &withContext
+}
+
+func filterOptsWithContext(opts *bind.FilterOpts, ctx context.Context) *bind.FilterOpts {
+	withContext := *opts
+	withContext.Context = ctx
+	return &withContext
+}
+
+func watchOptsWithContext(opts *bind.WatchOpts, ctx context.Context) *bind.WatchOpts {
+	withContext := *opts
+	withContext.Context = ctx
+	return &withContext
 }
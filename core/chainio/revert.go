@@ -0,0 +1,152 @@
+package chainio
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+// RevertError is the revert of a contract call, decoded with the AlignedLayerServiceManager ABI
+type RevertError struct {
+	// Name of the custom error, or "Error" for a revert string
+	Name string
+	// The custom error with its arguments, or the revert string
+	Reason string
+	// Whether retrying the call can not make it succeed, see revertClassification
+	Permanent bool
+	Inner     error
+}
+
+func (e *RevertError) Error() string { return fmt.Sprintf("execution reverted: %s", e.Reason) }
+func (e *RevertError) Unwrap() error { return e.Inner }
+
+const revertStringName = "Error"
+
+// Whether each custom error of the AlignedLayerServiceManager is permanent.
+// Errors that a block reorg may undo are transient, as the call may succeed once the chain settles.
+var revertClassification = map[string]bool{
+	// Responded by a previous transaction. If it is dropped by a reorg, the response tracker sends it again
+	"BatchAlreadyResponded": true,
+	"BatchAlreadySubmitted": true,
+	// The NewBatchV3 transaction may have been dropped by a reorg and be included again
+	"BatchDoesNotExist":      false,
+	"InsufficientFunds":      true,
+	"InvalidAddress":         true,
+	"InvalidDepositAmount":   true,
+	"InvalidQuorumThreshold": true,
+	"SenderIsNotAggregator":  true,
+}
+
+// Revert strings of the contracts the AlignedLayerServiceManager inherits, matched by substring.
+// Revert strings not listed here are transient.
+var revertStringClassification = []struct {
+	substring string
+	permanent bool
+}{
+	{"checkSignatures: signature is invalid", true},
+	{"checkSignatures: empty quorum input", true},
+	{"checkSignatures: input quorum length mismatch", true},
+	{"checkSignatures: input nonsigner length mismatch", true},
+	{"checkSignatures: nonSignerPubkeys not sorted", true},
+	// The task block may not be behind the current block yet, or may have been reorged
+	{"checkSignatures: invalid reference block", false},
+	{"Pausable: index is paused", false},
+}
+
+// ClassifyContractError decodes the revert of a contract call, and wraps it in a retry.PermanentError when
+// retrying can not make the call succeed. Errors that are not reverts are returned unchanged, as transient errors.
+func ClassifyContractError(err error) error {
+	revertErr, ok := DecodeRevert(err)
+	if !ok {
+		return err
+	}
+	if revertErr.Permanent {
+		return retry.PermanentError{Inner: revertErr}
+	}
+	return revertErr
+}
+
+// DecodeRevert returns the decoded revert of a contract call error, or false if the error is not a revert
+func DecodeRevert(err error) (*RevertError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return revertErr, true
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil && len(data) >= 4 {
+				return decodeRevertData(data, err), true
+			}
+		}
+	}
+
+	// Some nodes only return the revert string in the error message
+	if _, reason, found := strings.Cut(err.Error(), "execution reverted: "); found {
+		return newRevertStringError(reason, err), true
+	}
+	return nil, false
+}
+
+func decodeRevertData(data []byte, inner error) *RevertError {
+	serviceManagerAbi, err := servicemanager.ContractAlignedLayerServiceManagerMetaData.GetAbi()
+	if err == nil {
+		if abiError, err := serviceManagerAbi.ErrorByID([4]byte(data[:4])); err == nil {
+			if args, err := abiError.Inputs.Unpack(data[4:]); err == nil {
+				return &RevertError{
+					Name:      abiError.Name,
+					Reason:    fmt.Sprintf("%s(%s)", abiError.Name, formatRevertArgs(args)),
+					Permanent: revertClassification[abiError.Name],
+					Inner:     inner,
+				}
+			}
+		}
+	}
+
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return newRevertStringError(reason, inner)
+	}
+	return &RevertError{Name: "Unknown", Reason: hexutil.Encode(data), Inner: inner}
+}
+
+func newRevertStringError(reason string, inner error) *RevertError {
+	permanent := false
+	for _, classification := range revertStringClassification {
+		if strings.Contains(reason, classification.substring) {
+			permanent = classification.permanent
+			break
+		}
+	}
+	return &RevertError{Name: revertStringName, Reason: reason, Permanent: permanent, Inner: inner}
+}
+
+func formatRevertArgs(args []interface{}) string {
+	formatted := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case [32]byte:
+			formatted = append(formatted, hexutil.Encode(value[:]))
+		case common.Address:
+			formatted = append(formatted, value.Hex())
+		case *big.Int:
+			formatted = append(formatted, value.String())
+		case string:
+			formatted = append(formatted, fmt.Sprintf("%q", value))
+		default:
+			formatted = append(formatted, fmt.Sprintf("%v", value))
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
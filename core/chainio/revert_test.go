@@ -0,0 +1,98 @@
+package chainio
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+// revertDataError is the error returned by the node when a call reverts, with the revert data
+type revertDataError struct {
+	data string
+}
+
+func (e revertDataError) Error() string          { return "execution reverted" }
+func (e revertDataError) ErrorCode() int         { return 3 }
+func (e revertDataError) ErrorData() interface{} { return e.data }
+
+func packCustomError(t *testing.T, name string, args ...interface{}) error {
+	serviceManagerAbi, err := servicemanager.ContractAlignedLayerServiceManagerMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	abiError := serviceManagerAbi.Errors[name]
+	data, err := abiError.Inputs.Pack(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return revertDataError{data: hexutil.Encode(append(abiError.ID[:4], data...))}
+}
+
+func packRevertString(t *testing.T, reason string) error {
+	stringType, _ := abi.NewType("string", "", nil)
+	data, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Error(string) selector
+	return revertDataError{data: "0x08c379a0" + hexutil.Encode(data)[2:]}
+}
+
+func TestClassifyContractErrorCustomErrors(t *testing.T) {
+	batchIdentifierHash := [32]byte{1}
+
+	err := ClassifyContractError(packCustomError(t, "BatchAlreadyResponded", batchIdentifierHash))
+	if !errors.Is(err, retry.PermanentError{}) {
+		t.Fatalf("Expected BatchAlreadyResponded to be permanent, got %v", err)
+	}
+	revertErr, ok := DecodeRevert(err)
+	if !ok || revertErr.Name != "BatchAlreadyResponded" {
+		t.Fatalf("Expected BatchAlreadyResponded revert, got %v", err)
+	}
+	expectedReason := fmt.Sprintf("BatchAlreadyResponded(%s)", hexutil.Encode(batchIdentifierHash[:]))
+	if revertErr.Reason != expectedReason {
+		t.Errorf("Expected reason %s, got %s", expectedReason, revertErr.Reason)
+	}
+
+	// A reorg may drop the batch and include it again, so it is retried
+	err = ClassifyContractError(packCustomError(t, "BatchDoesNotExist", batchIdentifierHash))
+	if errors.Is(err, retry.PermanentError{}) {
+		t.Errorf("Expected BatchDoesNotExist to be transient, got %v", err)
+	}
+	if revertErr, ok := DecodeRevert(err); !ok || revertErr.Name != "BatchDoesNotExist" {
+		t.Errorf("Expected BatchDoesNotExist revert, got %v", err)
+	}
+}
+
+func TestClassifyContractErrorRevertStrings(t *testing.T) {
+	err := ClassifyContractError(packRevertString(t, "BLSSignatureChecker.checkSignatures: signature is invalid"))
+	if !errors.Is(err, retry.PermanentError{}) {
+		t.Errorf("Expected invalid signature to be permanent, got %v", err)
+	}
+
+	err = ClassifyContractError(packRevertString(t, "Pausable: index is paused"))
+	if errors.Is(err, retry.PermanentError{}) {
+		t.Errorf("Expected paused contract to be transient, got %v", err)
+	}
+
+	// Nodes that do not return the revert data
+	err = ClassifyContractError(errors.New("execution reverted: BLSSignatureChecker.checkSignatures: signature is invalid"))
+	if !errors.Is(err, retry.PermanentError{}) {
+		t.Errorf("Expected invalid signature from the error message to be permanent, got %v", err)
+	}
+}
+
+func TestClassifyContractErrorKeepsOtherErrors(t *testing.T) {
+	connectionErr := errors.New("connection refused")
+	if err := ClassifyContractError(connectionErr); err != connectionErr {
+		t.Errorf("Expected errors that are not reverts to be returned unchanged, got %v", err)
+	}
+	if err := ClassifyContractError(nil); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+A CircuitBreaker guards a single RPC endpoint, so a degraded endpoint stops receiving calls instead of
+being hit harder by every goroutine retrying on its own.
+
+Closed: calls go through. After `FailureThreshold` consecutive failures the circuit opens.
+Open: calls are rejected, and the caller falls back to another endpoint. After `OpenTimeout` the circuit becomes half-open.
+Half-open: a single trial call goes through. If it succeeds the circuit closes, otherwise it opens again.
+*/
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitHalfOpen
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+const (
+	BreakerFailureThreshold = 5               // Consecutive failures after which a circuit opens.
+	BreakerOpenTimeout      = 5 * time.Second // Time a circuit stays open before letting a trial call through. Shorter than the network retries, so a retried call gets to try the endpoint again.
+)
+
+// Returned when every endpoint able to serve a call has its circuit open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerParams struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+type CircuitBreaker struct {
+	name   string
+	params BreakerParams
+
+	mutex               sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+var (
+	breakersMutex sync.Mutex
+	breakers      []*CircuitBreaker
+)
+
+// NewCircuitBreaker returns a closed breaker. Its state changes are reported to the Observer under name,
+// so name must not contain secrets such as the api key of an endpoint url
+func NewCircuitBreaker(name string, params BreakerParams) *CircuitBreaker {
+	if params.FailureThreshold == 0 {
+		params.FailureThreshold = BreakerFailureThreshold
+	}
+	if params.OpenTimeout == 0 {
+		params.OpenTimeout = BreakerOpenTimeout
+	}
+	breaker := &CircuitBreaker{name: name, params: params}
+
+	breakersMutex.Lock()
+	breakers = append(breakers, breaker)
+	breakersMutex.Unlock()
+
+	if o := getObserver(); o != nil {
+		o.ObserveCircuitState(name, CircuitClosed)
+	}
+	return breaker
+}
+
+// Close unregisters the breaker, so it is not reported to the Observer set afterwards anymore.
+// It must be called once the endpoint it guards is not used anymore
+func (b *CircuitBreaker) Close() {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+	for i, breaker := range breakers {
+		if breaker == b {
+			breakers = append(breakers[:i], breakers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+func (b *CircuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+// Allow tells whether a call may go through. When it returns true, the result of the call must be
+// reported with Record, so a half-open circuit can let the next trial call through
+func (b *CircuitBreaker) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.params.OpenTimeout {
+			return false
+		}
+		b.setState(CircuitHalfOpen)
+		b.trialInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	}
+	return true
+}
+
+// Record updates the circuit with the result of a call let through by Allow
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.trialInFlight = false
+		if failed {
+			b.open()
+		} else {
+			b.consecutiveFailures = 0
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+
+	if !failed {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	if b.state == CircuitClosed && b.consecutiveFailures >= b.params.FailureThreshold {
+		b.open()
+	}
+}
+
+// Cancel reports that a call let through by Allow was cut short by its caller. It says nothing about the guarded
+// endpoint so it is not counted, but it lets the next trial call through if the circuit is half-open
+func (b *CircuitBreaker) Cancel() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.trialInFlight = false
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.openedAt = time.Now()
+	b.setState(CircuitOpen)
+}
+
+// setState must be called with the mutex held
+func (b *CircuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if o := getObserver(); o != nil {
+		o.ObserveCircuitState(b.name, state)
+	}
+}
+
+func registeredBreakers() []*CircuitBreaker {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+	return append([]*CircuitBreaker{}, breakers...)
+}
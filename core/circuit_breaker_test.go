@@ -0,0 +1,164 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	breaker := retry.NewCircuitBreaker("test", retry.BreakerParams{FailureThreshold: 2, OpenTimeout: 10 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		if !breaker.Allow() {
+			t.Fatalf("Expected closed circuit to allow calls")
+		}
+		breaker.Record(true)
+	}
+	if breaker.State() != retry.CircuitOpen || breaker.Allow() {
+		t.Fatalf("Expected circuit to open after 2 failures, got %s", breaker.State())
+	}
+
+	// After the open timeout a single trial call goes through
+	time.Sleep(20 * time.Millisecond)
+	if !breaker.Allow() || breaker.State() != retry.CircuitHalfOpen {
+		t.Fatalf("Expected half-open circuit to allow a trial call, got %s", breaker.State())
+	}
+	if breaker.Allow() {
+		t.Errorf("Expected half-open circuit to allow a single trial call")
+	}
+
+	// A failed trial opens the circuit again
+	breaker.Record(true)
+	if breaker.State() != retry.CircuitOpen {
+		t.Fatalf("Expected circuit to open after a failed trial, got %s", breaker.State())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	breaker.Allow()
+	breaker.Record(false)
+	if breaker.State() != retry.CircuitClosed || !breaker.Allow() {
+		t.Errorf("Expected circuit to close after a successful trial, got %s", breaker.State())
+	}
+}
+
+func TestRetryBudgetCapsRetries(t *testing.T) {
+	budget := retry.NewRetryBudget(0.5, 0, 2)
+	if !budget.Withdraw() || !budget.Withdraw() {
+		t.Fatalf("Expected a new budget to allow 2 retries")
+	}
+	if budget.Withdraw() {
+		t.Fatalf("Expected an empty budget to deny retries")
+	}
+
+	// Two requests pay for one retry
+	budget.Deposit()
+	budget.Deposit()
+	if !budget.Withdraw() || budget.Withdraw() {
+		t.Errorf("Expected 2 requests to allow a single retry")
+	}
+}
+
+func TestRetrySkipsAttemptsWithoutBudget(t *testing.T) {
+	calls := 0
+	function := func() error {
+		calls++
+		_, err := DummyFunction(41)
+		return err
+	}
+	config := &retry.RetryParams{
+		InitialInterval:     time.Millisecond,
+		MaxInterval:         time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          retry.NetworkMultiplier,
+		NumRetries:          retry.NetworkNumRetries,
+		Budget:              retry.NewRetryBudget(0, 0, 1),
+	}
+	err := retry.Retry(function, config)
+	if err != retry.ErrRetryBudgetExhausted {
+		t.Errorf("Expected retry budget exhausted error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the first attempt and a single retry, got %d calls", calls)
+	}
+}
+
+func TestRetryGivesUpRightAwayWhenBudgetIsExhausted(t *testing.T) {
+	calls := 0
+	function := func() error {
+		calls++
+		_, err := DummyFunction(41)
+		return err
+	}
+	config := &retry.RetryParams{
+		InitialInterval:     100 * time.Millisecond,
+		MaxInterval:         100 * time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          retry.NetworkMultiplier,
+		NumRetries:          10,
+		Budget:              retry.NewRetryBudget(0, 0, 0),
+	}
+	start := time.Now()
+	err := retry.Retry(function, config)
+	if err != retry.ErrRetryBudgetExhausted {
+		t.Errorf("Expected retry budget exhausted error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected only the first attempt, got %d calls", calls)
+	}
+	// A single backoff interval is waited before the retry that finds the budget empty
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the call to give up without waiting for the remaining retries, took %v", elapsed)
+	}
+}
+
+type circuitsObserver struct {
+	names []string
+}
+
+func (o *circuitsObserver) ObserveRetryAttempt(name string, attempt uint64, duration time.Duration, err error) {
+}
+
+func (o *circuitsObserver) ObserveRetryGiveUp(name string, attempts uint64, err error) {}
+
+func (o *circuitsObserver) ObserveCircuitState(name string, state retry.CircuitState) {
+	o.names = append(o.names, name)
+}
+
+func TestClosedCircuitBreakerIsNotReported(t *testing.T) {
+	kept := retry.NewCircuitBreaker("kept", retry.BreakerParams{})
+	defer kept.Close()
+	closed := retry.NewCircuitBreaker("closed", retry.BreakerParams{})
+	closed.Close()
+
+	observer := &circuitsObserver{}
+	retry.SetObserver(observer)
+	defer retry.SetObserver(nil)
+
+	reported := map[string]bool{}
+	for _, name := range observer.names {
+		reported[name] = true
+	}
+	if !reported["kept"] || reported["closed"] {
+		t.Errorf("Expected only the breaker not closed to be reported, got %v", observer.names)
+	}
+}
+
+func TestCancelledTrialLetsNextTrialThrough(t *testing.T) {
+	breaker := retry.NewCircuitBreaker("cancelled", retry.BreakerParams{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	defer breaker.Close()
+	breaker.Record(true)
+	time.Sleep(5 * time.Millisecond)
+
+	if !breaker.Allow() {
+		t.Fatalf("Expected a trial call once the circuit timed out")
+	}
+	breaker.Cancel()
+	if state := breaker.State(); state != retry.CircuitHalfOpen {
+		t.Errorf("Expected a cancelled trial not to change the circuit, got %s", state)
+	}
+	if !breaker.Allow() {
+		t.Errorf("Expected a new trial call after the cancelled one")
+	}
+}
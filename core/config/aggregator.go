@@ -1,13 +1,12 @@
 package config
 
 import (
-	"errors"
-	"log"
-	"os"
+	"crypto/ecdsa"
+	"fmt"
 	"time"
 
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type AggregatorConfig struct {
@@ -29,6 +28,9 @@ type AggregatorConfig struct {
 		GasBumpIncrementalPercentage  uint
 		GasBumpPercentageLimit        uint
 		TimeToWaitBeforeBump          time.Duration
+		AdminIpPortAddress            string
+		ResponseFinalityDepth         uint64
+		ResponseTrackerPeriod         time.Duration
 	}
 }
 
@@ -48,34 +50,29 @@ type AggregatorConfigFromYaml struct {
 		GasBumpIncrementalPercentage  uint           `yaml:"gas_bump_incremental_percentage"`
 		GasBumpPercentageLimit        uint           `yaml:"gas_bump_percentage_limit"`
 		TimeToWaitBeforeBump          time.Duration  `yaml:"time_to_wait_before_bump"`
+		AdminIpPortAddress            string         `yaml:"admin_ip_port_address"`
+		ResponseFinalityDepth         uint64         `yaml:"response_finality_depth"`
+		ResponseTrackerPeriod         time.Duration  `yaml:"response_tracker_period"`
 	} `yaml:"aggregator"`
 }
 
-func NewAggregatorConfig(configFilePath string) *AggregatorConfig {
-
-	if _, err := os.Stat(configFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup config file does not exist")
-	}
-
-	baseConfig := NewBaseConfig(configFilePath)
-	if baseConfig == nil {
-		log.Fatal("Error reading base config: ")
-	}
-
-	ecdsaConfig := NewEcdsaConfig(configFilePath, baseConfig.ChainId)
-	if ecdsaConfig == nil {
-		log.Fatal("Error reading ecdsa config: ")
+func NewAggregatorConfig(configFilePath string) (*AggregatorConfig, error) {
+	problems := validationErrors{}
+	aggregatorConfigFromYaml, ecdsaKeyPair, blsConfig := checkAggregatorConfig(configFilePath, &problems)
+	if aggregatorConfigFromYaml == nil {
+		return nil, problems.err()
 	}
 
-	blsConfig := NewBlsConfig(configFilePath)
-	if blsConfig == nil {
-		log.Fatal("Error reading bls config: ")
+	baseConfig, err := newBaseConfig(configFilePath, &problems)
+	if err != nil {
+		return nil, err
 	}
 
-	var aggregatorConfigFromYaml AggregatorConfigFromYaml
-	err := utils.ReadYamlConfig(configFilePath, &aggregatorConfigFromYaml)
+	// The signer needs the chain id of the endpoints
+	ecdsaConfig, err := newEcdsaConfig(ecdsaKeyPair, baseConfig.ChainId)
 	if err != nil {
-		log.Fatal("Error reading aggregator config: ", err)
+		baseConfig.Close()
+		return nil, err
 	}
 
 	return &AggregatorConfig{
@@ -97,6 +94,119 @@ func NewAggregatorConfig(configFilePath string) *AggregatorConfig {
 			GasBumpIncrementalPercentage  uint
 			GasBumpPercentageLimit        uint
 			TimeToWaitBeforeBump          time.Duration
+			AdminIpPortAddress            string
+			ResponseFinalityDepth         uint64
+			ResponseTrackerPeriod         time.Duration
 		}(aggregatorConfigFromYaml.Aggregator),
+	}, nil
+}
+
+// checkAggregatorConfig adds the problems of the aggregator section of the config file to problems.
+// It returns a nil config if the file can not be read
+func checkAggregatorConfig(configFilePath string, problems *validationErrors) (*AggregatorConfigFromYaml, *ecdsa.PrivateKey, *BlsConfig) {
+	var aggregatorConfigFromYaml AggregatorConfigFromYaml
+	if err := readYamlFile(configFilePath, &aggregatorConfigFromYaml); err != nil {
+		problems.addErr(err)
+		return nil, nil, nil
+	}
+
+	ecdsaKeyPair, err := readEcdsaKey(configFilePath)
+	if err != nil {
+		problems.addErr(err)
+	}
+
+	blsConfig, err := NewBlsConfig(configFilePath)
+	if err != nil {
+		problems.addErr(err)
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.ServerIpPortAddress == "" {
+		problems.add(configFilePath, "aggregator.server_ip_port_address", ErrEmptyField)
+	}
+	if aggregatorConfigFromYaml.Aggregator.EnableMetrics && aggregatorConfigFromYaml.Aggregator.MetricsIpPortAddress == "" {
+		problems.add(configFilePath, "aggregator.metrics_ip_port_address", fmt.Errorf("%w, but metrics are enabled", ErrEmptyField))
 	}
+
+	return &aggregatorConfigFromYaml, ecdsaKeyPair, blsConfig
+}
+
+// AggregatorSettings are the fields of the aggregator config that can be changed while it runs, see AggregatorReloader
+type AggregatorSettings struct {
+	GasBaseBumpPercentage         uint
+	GasBumpIncrementalPercentage  uint
+	GasBumpPercentageLimit        uint
+	TimeToWaitBeforeBump          time.Duration
+	BlsServiceTaskTimeout         time.Duration
+	GarbageCollectorPeriod        time.Duration
+	GarbageCollectorTasksAge      uint64
+	GarbageCollectorTasksInterval uint64
+}
+
+// Settings returns the fields of the config that can be reloaded
+func (c *AggregatorConfig) Settings() *AggregatorSettings {
+	return &AggregatorSettings{
+		GasBaseBumpPercentage:         c.Aggregator.GasBaseBumpPercentage,
+		GasBumpIncrementalPercentage:  c.Aggregator.GasBumpIncrementalPercentage,
+		GasBumpPercentageLimit:        c.Aggregator.GasBumpPercentageLimit,
+		TimeToWaitBeforeBump:          c.Aggregator.TimeToWaitBeforeBump,
+		BlsServiceTaskTimeout:         c.Aggregator.BlsServiceTaskTimeout,
+		GarbageCollectorPeriod:        c.Aggregator.GarbageCollectorPeriod,
+		GarbageCollectorTasksAge:      c.Aggregator.GarbageCollectorTasksAge,
+		GarbageCollectorTasksInterval: c.Aggregator.GarbageCollectorTasksInterval,
+	}
+}
+
+// AggregatorReloadableFields are the fields of the aggregator config file that can be reloaded
+var AggregatorReloadableFields = []string{
+	"environment",
+	"aggregator.gas_base_bump_percentage",
+	"aggregator.gas_bump_incremental_percentage",
+	"aggregator.gas_bump_percentage_limit",
+	"aggregator.time_to_wait_before_bump",
+	"aggregator.bls_service_task_timeout",
+	"aggregator.garbage_collector_period",
+	"aggregator.garbage_collector_tasks_age",
+	"aggregator.garbage_collector_tasks_interval",
+}
+
+// aggregatorConfigFile has every section of the config file read by the aggregator
+type aggregatorConfigFile struct {
+	Base       BaseConfigFromYaml       `yaml:",inline"`
+	Aggregator AggregatorConfigFromYaml `yaml:",inline"`
+	Ecdsa      EcdsaConfigFromYaml      `yaml:",inline"`
+	Bls        BlsConfigFromYaml        `yaml:",inline"`
+}
+
+// AggregatorReloader re-reads the config file of a running aggregator
+type AggregatorReloader struct {
+	reloader *reloader
+}
+
+// NewAggregatorReloader reads the config file the following reloads are compared with
+func NewAggregatorReloader(configFilePath string) (*AggregatorReloader, error) {
+	reloader, err := newReloader(configFilePath, &aggregatorConfigFile{}, AggregatorReloadableFields)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregatorReloader{reloader: reloader}, nil
+}
+
+// Reload re-reads the config file and calls apply with the environment and settings in it.
+// Nothing is applied if a field that is not one of the AggregatorReloadableFields changed, or a changed field is
+// invalid, returning the problems in a ValidationError. It returns the changed fields
+func (r *AggregatorReloader) Reload(apply func(environment sdklogging.LogLevel, settings *AggregatorSettings) error) ([]FieldChange, error) {
+	next := &aggregatorConfigFile{}
+	return r.reloader.reload(next, r.reloader.checkReloadedField, func() error {
+		aggregator := next.Aggregator.Aggregator
+		return apply(next.Base.Environment, &AggregatorSettings{
+			GasBaseBumpPercentage:         aggregator.GasBaseBumpPercentage,
+			GasBumpIncrementalPercentage:  aggregator.GasBumpIncrementalPercentage,
+			GasBumpPercentageLimit:        aggregator.GasBumpPercentageLimit,
+			TimeToWaitBeforeBump:          aggregator.TimeToWaitBeforeBump,
+			BlsServiceTaskTimeout:         aggregator.BlsServiceTaskTimeout,
+			GarbageCollectorPeriod:        aggregator.GarbageCollectorPeriod,
+			GarbageCollectorTasksAge:      aggregator.GarbageCollectorTasksAge,
+			GarbageCollectorTasksInterval: aggregator.GarbageCollectorTasksInterval,
+		})
+	})
 }
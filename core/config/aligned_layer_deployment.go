@@ -1,18 +1,22 @@
 package config
 
 import (
-	"errors"
-	"log"
-	"os"
-
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type AlignedLayerDeploymentConfig struct {
 	AlignedLayerServiceManagerAddr         common.Address
 	AlignedLayerRegistryCoordinatorAddr    common.Address
 	AlignedLayerOperatorStateRetrieverAddr common.Address
+	// Optional, read from the service manager when empty
+	AlignedLayerStakeRegistryAddr  common.Address
+	AlignedLayerBlsApkRegistryAddr common.Address
+	// Optional, the canonical Multicall3 address is used if empty
+	Multicall3Addr common.Address
+	// Chain the contracts were deployed to, 0 if the file does not say
+	ChainId uint64
+	// File or network preset the deployment was read from, to report where a mismatch with the chain comes from
+	source string
 }
 
 type AlignedLayerDeploymentConfigFromJson struct {
@@ -20,37 +24,50 @@ type AlignedLayerDeploymentConfigFromJson struct {
 		AlignedLayerServiceManagerAddr         common.Address `json:"alignedLayerServiceManager"`
 		AlignedLayerRegistryCoordinatorAddr    common.Address `json:"registryCoordinator"`
 		AlignedLayerOperatorStateRetrieverAddr common.Address `json:"operatorStateRetriever"`
+		AlignedLayerStakeRegistryAddr          common.Address `json:"stakeRegistry"`
+		AlignedLayerBlsApkRegistryAddr         common.Address `json:"blsApkRegistry"`
+		Multicall3Addr                         common.Address `json:"multicall3"`
 	} `json:"addresses"`
+	ChainInfo struct {
+		ChainId uint64 `json:"chainId"`
+	} `json:"chainInfo"`
 }
 
-func NewAlignedLayerDeploymentConfig(alignedLayerDeploymentFilePath string) *AlignedLayerDeploymentConfig {
-
-	if _, err := os.Stat(alignedLayerDeploymentFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup aligned layer deployment file does not exist")
-	}
-
+func NewAlignedLayerDeploymentConfig(alignedLayerDeploymentFilePath string) (*AlignedLayerDeploymentConfig, error) {
 	var alignedLayerDeploymentConfigFromJson AlignedLayerDeploymentConfigFromJson
-	err := utils.ReadJsonConfig(alignedLayerDeploymentFilePath, &alignedLayerDeploymentConfigFromJson)
-
-	if err != nil {
-		log.Fatal("Error reading aligned layer deployment config: ", err)
+	if err := readJsonFile(alignedLayerDeploymentFilePath, &alignedLayerDeploymentConfigFromJson); err != nil {
+		return nil, err
 	}
+	return newAlignedLayerDeploymentConfig(alignedLayerDeploymentFilePath, &alignedLayerDeploymentConfigFromJson)
+}
 
+// newAlignedLayerDeploymentConfig checks a deployment parsed from source, a file or a network preset
+func newAlignedLayerDeploymentConfig(source string, alignedLayerDeploymentConfigFromJson *AlignedLayerDeploymentConfigFromJson) (*AlignedLayerDeploymentConfig, error) {
+	problems := validationErrors{}
 	if alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerServiceManagerAddr == common.HexToAddress("") {
-		log.Fatal("Aligned layer service manager address is empty")
+		problems.add(source, "addresses.alignedLayerServiceManager", ErrEmptyField)
 	}
 
 	if alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerRegistryCoordinatorAddr == common.HexToAddress("") {
-		log.Fatal("Aligned layer registry coordinator address is empty")
+		problems.add(source, "addresses.registryCoordinator", ErrEmptyField)
 	}
 
 	if alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerOperatorStateRetrieverAddr == common.HexToAddress("") {
-		log.Fatal("Aligned layer operator state retriever address is empty")
+		problems.add(source, "addresses.operatorStateRetriever", ErrEmptyField)
+	}
+
+	if !problems.empty() {
+		return nil, problems.err()
 	}
 
 	return &AlignedLayerDeploymentConfig{
 		AlignedLayerServiceManagerAddr:         alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerServiceManagerAddr,
 		AlignedLayerRegistryCoordinatorAddr:    alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerRegistryCoordinatorAddr,
 		AlignedLayerOperatorStateRetrieverAddr: alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerOperatorStateRetrieverAddr,
-	}
+		AlignedLayerStakeRegistryAddr:          alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerStakeRegistryAddr,
+		AlignedLayerBlsApkRegistryAddr:         alignedLayerDeploymentConfigFromJson.Addresses.AlignedLayerBlsApkRegistryAddr,
+		Multicall3Addr:                         alignedLayerDeploymentConfigFromJson.Addresses.Multicall3Addr,
+		ChainId:                                alignedLayerDeploymentConfigFromJson.ChainInfo.ChainId,
+		source:                                 source,
+	}, nil
 }
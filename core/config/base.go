@@ -2,17 +2,14 @@ package config
 
 import (
 	"context"
-	"errors"
-	"log"
+	"fmt"
 	"math/big"
-	"os"
+	"time"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
-	rpccalls "github.com/Layr-Labs/eigensdk-go/metrics/collectors/rpc_calls"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
-	"github.com/yetanotherco/aligned_layer/core/utils"
+	"github.com/yetanotherco/aligned_layer/core/rpcpool"
 )
 
 var (
@@ -21,6 +18,21 @@ var (
 		Required: true,
 		Usage:    "Load base configurations from `FILE`",
 	}
+	WatchConfigFileFlag = &cli.BoolFlag{
+		Name:  "watch-config",
+		Usage: "Reload the config file when it changes. It is always reloaded on SIGHUP",
+	}
+)
+
+// Timeout of the calls reading the deployment from the service manager at startup
+const deploymentReadTimeout = 30 * time.Second
+
+// Sources of the events the services subscribe to
+const (
+	// Subscriptions through the websocket endpoints
+	EventSourceWs = "ws"
+	// eth_getLogs polling through the http endpoints, for deployments without websocket endpoints
+	EventSourceHttp = "http"
 )
 
 type BaseConfig struct {
@@ -29,131 +41,301 @@ type BaseConfig struct {
 	Logger                       sdklogging.Logger
 	EthRpcUrl                    string
 	EthWsUrl                     string
-	EthRpcClient                 eth.InstrumentedClient
-	EthRpcClientFallback         eth.InstrumentedClient
-	EthWsClient                  eth.InstrumentedClient
-	EthWsClientFallback          eth.InstrumentedClient
-	EthRpcUrlFallback            string
-	EthWsUrlFallback             string
-	EigenMetricsIpPortAddress    string
-	ChainId                      *big.Int
+	// Pools with every configured endpoint. Calls are routed to the healthiest endpoint of the pool.
+	// EthWsPool is nil when the event source is http
+	EthRpcPool *rpcpool.Pool
+	EthWsPool  *rpcpool.Pool
+	// Used to subscribe to events: the ws pool, or a log poller over the rpc pool when the event source is http
+	EthEventsBackend          rpcpool.Backend
+	EventSource               string
+	EthRpcUrlFallback         string
+	EthWsUrlFallback          string
+	EigenMetricsIpPortAddress string
+	ChainId                   *big.Int
+	NewBatchConfirmationDepth uint64
+	// Maximum number of blocks queried in a single eth_getLogs call when scanning past logs
+	LogsBlockRange uint64
 }
 
 type BaseConfigFromYaml struct {
-	AlignedLayerDeploymentConfigFilePath string              `yaml:"aligned_layer_deployment_config_file_path"`
-	EigenLayerDeploymentConfigFilePath   string              `yaml:"eigen_layer_deployment_config_file_path"`
-	Environment                          sdklogging.LogLevel `yaml:"environment"`
-	EthRpcUrl                            string              `yaml:"eth_rpc_url"`
-	EthRpcUrlFallback                    string              `yaml:"eth_rpc_url_fallback"`
-	EthWsUrl                             string              `yaml:"eth_ws_url"`
-	EthWsUrlFallback                     string              `yaml:"eth_ws_url_fallback"`
-	EigenMetricsIpPortAddress            string              `yaml:"eigen_metrics_ip_port_address"`
+	AlignedLayerDeploymentConfigFilePath string `yaml:"aligned_layer_deployment_config_file_path"`
+	EigenLayerDeploymentConfigFilePath   string `yaml:"eigen_layer_deployment_config_file_path"`
+	// Instead of the deployment config files, one of the Networks, or the address of the service manager
+	// to read the rest of the deployment from
+	Network                                   string              `yaml:"network"`
+	AlignedLayerServiceManagerAddress         common.Address      `yaml:"aligned_layer_service_manager_address"`
+	AlignedLayerOperatorStateRetrieverAddress common.Address      `yaml:"aligned_layer_operator_state_retriever_address"`
+	Environment                               sdklogging.LogLevel `yaml:"environment"`
+	EthRpcUrl                                 string              `yaml:"eth_rpc_url"`
+	EthRpcUrlFallback                         string              `yaml:"eth_rpc_url_fallback"`
+	EthWsUrl                                  string              `yaml:"eth_ws_url"`
+	EthWsUrlFallback                          string              `yaml:"eth_ws_url_fallback"`
+	EthRpcUrls                                []string            `yaml:"eth_rpc_urls"`
+	EthWsUrls                                 []string            `yaml:"eth_ws_urls"`
+	EthMaxHeadLag                             uint64              `yaml:"eth_max_head_lag"`
+	EthHealthCheckPeriod                      time.Duration       `yaml:"eth_health_check_period"`
+	EventSource                               string              `yaml:"event_source"`
+	EventPollInterval                         time.Duration       `yaml:"event_poll_interval"`
+	EventPollBlockRange                       uint64              `yaml:"event_poll_block_range"`
+	EventCursorFilePath                       string              `yaml:"event_cursor_file_path"`
+	EigenMetricsIpPortAddress                 string              `yaml:"eigen_metrics_ip_port_address"`
+	NewBatchConfirmationDepth                 uint64              `yaml:"new_batch_confirmation_depth"`
+	LogsBlockRange                            uint64              `yaml:"logs_block_range"`
 }
 
-func NewBaseConfig(configFilePath string) *BaseConfig {
+// NewBaseConfig loads the base config. Every problem of the file is checked before dialing the endpoints,
+// and returned in a ValidationError
+func NewBaseConfig(configFilePath string) (*BaseConfig, error) {
+	return newBaseConfig(configFilePath, &validationErrors{})
+}
+
+// staticBaseConfig is the base config read from the file, before dialing the endpoints
+type staticBaseConfig struct {
+	fromYaml                     BaseConfigFromYaml
+	alignedLayerDeploymentConfig *AlignedLayerDeploymentConfig
+	eigenLayerDeploymentConfig   *EigenLayerDeploymentConfig
+	logger                       sdklogging.Logger
+	ethRpcUrls                   []string
+	ethWsUrls                    []string
+	eventSource                  string
+}
 
-	if _, err := os.Stat(configFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup base config file does not exist")
+// newBaseConfig adds the problems of the base config to the ones found by the caller,
+// and only dials the endpoints if there are none
+func newBaseConfig(configFilePath string, problems *validationErrors) (*BaseConfig, error) {
+	static := checkBaseConfig(configFilePath, problems)
+	if !problems.empty() {
+		return nil, problems.err()
 	}
+	baseConfigFromYaml := static.fromYaml
+	logger := static.logger
+	ethRpcUrls := static.ethRpcUrls
+	ethWsUrls := static.ethWsUrls
+	eventSource := static.eventSource
 
-	var baseConfigFromYaml BaseConfigFromYaml
+	poolParams := rpcpool.PoolParams{
+		MaxHeadLag:        baseConfigFromYaml.EthMaxHeadLag,
+		HealthCheckPeriod: baseConfigFromYaml.EthHealthCheckPeriod,
+	}
 
-	err := utils.ReadYamlConfig(configFilePath, &baseConfigFromYaml)
+	ethRpcPool, err := rpcpool.NewPool("ethRpc", ethRpcUrls, poolParams, logger)
 	if err != nil {
-		log.Fatal("Error reading setup config: ", err)
+		return nil, fmt.Errorf("error initializing eth rpc pool: %w", err)
 	}
 
-	alignedLayerDeploymentConfigFilePath := baseConfigFromYaml.AlignedLayerDeploymentConfigFilePath
-	if alignedLayerDeploymentConfigFilePath == "" {
-		log.Fatal("Aligned layer deployment config file path is empty")
+	// Holds what is built so far, to close it if a later step fails
+	baseConfig := &BaseConfig{EthRpcPool: ethRpcPool}
+	if eventSource == EventSourceWs {
+		baseConfig.EthWsPool, err = rpcpool.NewPool("ethWs", ethWsUrls, poolParams, logger)
+		if err != nil {
+			baseConfig.Close()
+			return nil, fmt.Errorf("error initializing eth ws pool: %w", err)
+		}
+		baseConfig.EthEventsBackend = baseConfig.EthWsPool
+	} else {
+		baseConfig.EthEventsBackend, err = rpcpool.NewLogPoller(ethRpcPool, rpcpool.LogPollerParams{
+			PollInterval:   baseConfigFromYaml.EventPollInterval,
+			BlockRange:     baseConfigFromYaml.EventPollBlockRange,
+			CursorFilePath: baseConfigFromYaml.EventCursorFilePath,
+		}, logger)
+		if err != nil {
+			baseConfig.Close()
+			return nil, fmt.Errorf("error initializing eth log poller: %w", err)
+		}
+		logger.Info("Polling events through the eth rpc url")
 	}
 
-	if _, err := os.Stat(alignedLayerDeploymentConfigFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup aligned layer deployment file does not exist")
+	chainId, err := ethRpcPool.ChainID(context.Background())
+	if err != nil {
+		baseConfig.Close()
+		return nil, fmt.Errorf("cannot get chain id from eth rpc: %w", err)
 	}
 
-	alignedLayerDeploymentConfig := NewAlignedLayerDeploymentConfig(alignedLayerDeploymentConfigFilePath)
-	if alignedLayerDeploymentConfig == nil {
-		log.Fatal("Error reading aligned layer deployment config: ", err)
+	ctx, cancel := context.WithTimeout(context.Background(), deploymentReadTimeout)
+	defer cancel()
+	alignedLayerDeploymentConfig, eigenLayerDeploymentConfig, err := ResolveDeployment(ctx, ethRpcPool, chainId, static.alignedLayerDeploymentConfig, static.eigenLayerDeploymentConfig)
+	if err != nil {
+		baseConfig.Close()
+		return nil, err
 	}
 
-	eigenLayerDeploymentConfigFilePath := baseConfigFromYaml.EigenLayerDeploymentConfigFilePath
-	if eigenLayerDeploymentConfigFilePath == "" {
-		log.Fatal("Eigen layer deployment config file path is empty")
-	}
+	return &BaseConfig{
+		AlignedLayerDeploymentConfig: alignedLayerDeploymentConfig,
+		EigenLayerDeploymentConfig:   eigenLayerDeploymentConfig,
+		Logger:                       logger,
+		EthRpcUrl:                    ethRpcUrls[0],
+		EthWsUrl:                     firstUrl(ethWsUrls),
+		EthRpcPool:                   ethRpcPool,
+		EthWsPool:                    baseConfig.EthWsPool,
+		EthEventsBackend:             baseConfig.EthEventsBackend,
+		EventSource:                  eventSource,
+		EthRpcUrlFallback:            fallbackUrl(ethRpcUrls),
+		EthWsUrlFallback:             fallbackUrl(ethWsUrls),
+		EigenMetricsIpPortAddress:    baseConfigFromYaml.EigenMetricsIpPortAddress,
+		ChainId:                      chainId,
+		NewBatchConfirmationDepth:    baseConfigFromYaml.NewBatchConfirmationDepth,
+		LogsBlockRange:               baseConfigFromYaml.LogsBlockRange,
+	}, nil
+}
 
-	if _, err := os.Stat(eigenLayerDeploymentConfigFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup eigen layer deployment file does not exist")
+// Close stops the log poller, if the events are polled, and the health checks of the pools, and closes the
+// connections of their endpoints. The config must not be used afterwards
+func (c *BaseConfig) Close() {
+	if poller, ok := c.EthEventsBackend.(*rpcpool.LogPoller); ok {
+		poller.Close()
+	}
+	if c.EthWsPool != nil {
+		c.EthWsPool.Close()
 	}
-	eigenLayerDeploymentConfig := NewEigenLayerDeploymentConfig(baseConfigFromYaml.EigenLayerDeploymentConfigFilePath)
+	c.EthRpcPool.Close()
+}
 
-	if eigenLayerDeploymentConfig == nil {
-		log.Fatal("Error reading eigen layer deployment config: ", err)
+// checkBaseConfig adds the problems of the base config to the ones found by the caller, without dialing the endpoints.
+// It returns nil if the file can not be read
+func checkBaseConfig(configFilePath string, problems *validationErrors) *staticBaseConfig {
+	var baseConfigFromYaml BaseConfigFromYaml
+	if err := readYamlFile(configFilePath, &baseConfigFromYaml); err != nil {
+		problems.addErr(err)
+		return nil
 	}
-	logger, err := NewLogger(baseConfigFromYaml.Environment)
 
+	alignedLayerDeploymentConfig, eigenLayerDeploymentConfig := checkDeploymentConfig(configFilePath, &baseConfigFromYaml, problems)
+
+	logger, err := NewLogger(baseConfigFromYaml.Environment)
 	if err != nil {
-		log.Fatal("Error initializing logger: ", err)
+		problems.add(configFilePath, "environment", fmt.Errorf("%w: %v", ErrInvalidField, err))
 	}
 
-	if baseConfigFromYaml.EthWsUrl == "" || baseConfigFromYaml.EthWsUrlFallback == "" {
-		log.Fatal("Eth ws url or fallback is empty")
+	ethRpcUrls := mergeUrls(baseConfigFromYaml.EthRpcUrl, baseConfigFromYaml.EthRpcUrlFallback, baseConfigFromYaml.EthRpcUrls)
+	if len(ethRpcUrls) == 0 {
+		problems.add(configFilePath, "eth_rpc_url", ErrEmptyField)
 	}
 
-	reg := prometheus.NewRegistry()
-	rpcCallsCollector := rpccalls.NewCollector("ethWs", reg)
-	ethWsClient, err := eth.NewInstrumentedClient(baseConfigFromYaml.EthWsUrl, rpcCallsCollector)
-	if err != nil {
-		log.Fatal("Error initializing eth ws client: ", err)
+	ethWsUrls := mergeUrls(baseConfigFromYaml.EthWsUrl, baseConfigFromYaml.EthWsUrlFallback, baseConfigFromYaml.EthWsUrls)
+
+	// Websocket endpoints are optional, events are polled over http when there are none
+	eventSource := baseConfigFromYaml.EventSource
+	if eventSource == "" {
+		eventSource = EventSourceWs
+		if len(ethWsUrls) == 0 {
+			eventSource = EventSourceHttp
+		}
 	}
-	reg = prometheus.NewRegistry()
-	rpcCallsCollector = rpccalls.NewCollector("ethWsFallback", reg)
-	ethWsClientFallback, err := eth.NewInstrumentedClient(baseConfigFromYaml.EthWsUrlFallback, rpcCallsCollector)
-	if err != nil {
-		log.Fatal("Error initializing eth ws client fallback: ", err)
+	switch eventSource {
+	case EventSourceWs:
+		if len(ethWsUrls) == 0 {
+			problems.add(configFilePath, "eth_ws_url", fmt.Errorf("%w, set event_source to '%s' to poll events through the eth rpc url", ErrEmptyField, EventSourceHttp))
+		}
+	case EventSourceHttp:
+	default:
+		problems.add(configFilePath, "event_source", fmt.Errorf("%w: %q, expected %q or %q", ErrInvalidField, eventSource, EventSourceWs, EventSourceHttp))
 	}
 
-	if baseConfigFromYaml.EthRpcUrl == "" || baseConfigFromYaml.EthRpcUrlFallback == "" {
-		log.Fatal("Eth rpc url is empty")
+	if baseConfigFromYaml.EigenMetricsIpPortAddress == "" {
+		problems.add(configFilePath, "eigen_metrics_ip_port_address", ErrEmptyField)
 	}
 
-	reg = prometheus.NewRegistry()
-	rpcCallsCollector = rpccalls.NewCollector("ethRpc", reg)
-	ethRpcClient, err := eth.NewInstrumentedClient(baseConfigFromYaml.EthRpcUrl, rpcCallsCollector)
-	if err != nil {
-		log.Fatal("Error initializing eth rpc client: ", err)
+	return &staticBaseConfig{
+		fromYaml:                     baseConfigFromYaml,
+		alignedLayerDeploymentConfig: alignedLayerDeploymentConfig,
+		eigenLayerDeploymentConfig:   eigenLayerDeploymentConfig,
+		logger:                       logger,
+		ethRpcUrls:                   ethRpcUrls,
+		ethWsUrls:                    ethWsUrls,
+		eventSource:                  eventSource,
 	}
+}
 
-	reg = prometheus.NewRegistry()
-	rpcCallsCollector = rpccalls.NewCollector("ethRpc", reg)
-	ethRpcClientFallback, err := eth.NewInstrumentedClient(baseConfigFromYaml.EthRpcUrlFallback, rpcCallsCollector)
-	if err != nil {
-		log.Fatal("Error initializing eth rpc client fallback: ", err)
+// checkDeploymentConfig reads the deployment from the network preset, the deployment config files, or when only the
+// service manager address is set, returns an Aligned deployment with it and no EigenLayer deployment,
+// for ResolveDeployment to read the rest from the chain
+func checkDeploymentConfig(configFilePath string, baseConfigFromYaml *BaseConfigFromYaml, problems *validationErrors) (*AlignedLayerDeploymentConfig, *EigenLayerDeploymentConfig) {
+	deploymentFilesSet := baseConfigFromYaml.AlignedLayerDeploymentConfigFilePath != "" || baseConfigFromYaml.EigenLayerDeploymentConfigFilePath != ""
+	serviceManagerSet := baseConfigFromYaml.AlignedLayerServiceManagerAddress != (common.Address{})
+	errOneSource := fmt.Errorf("%w: set only one of network, aligned_layer_service_manager_address or the deployment config files", ErrInvalidField)
+
+	switch {
+	case baseConfigFromYaml.Network != "":
+		if deploymentFilesSet || serviceManagerSet {
+			problems.add(configFilePath, "network", errOneSource)
+			return nil, nil
+		}
+		alignedLayerDeploymentConfig, eigenLayerDeploymentConfig, err := NetworkDeploymentConfig(baseConfigFromYaml.Network)
+		if err != nil {
+			problems.add(configFilePath, "network", fmt.Errorf("%w: %v", ErrInvalidField, err))
+		}
+		return alignedLayerDeploymentConfig, eigenLayerDeploymentConfig
+
+	case serviceManagerSet:
+		if deploymentFilesSet {
+			problems.add(configFilePath, "aligned_layer_service_manager_address", errOneSource)
+			return nil, nil
+		}
+		return &AlignedLayerDeploymentConfig{
+			AlignedLayerServiceManagerAddr:         baseConfigFromYaml.AlignedLayerServiceManagerAddress,
+			AlignedLayerOperatorStateRetrieverAddr: baseConfigFromYaml.AlignedLayerOperatorStateRetrieverAddress,
+			source:                                 configFilePath,
+		}, nil
 	}
 
-	chainId, err := ethRpcClient.ChainID(context.Background())
-	if err != nil {
-		logger.Error("Cannot get chainId from eth rpc client", "err", err)
-		return nil
+	var alignedLayerDeploymentConfig *AlignedLayerDeploymentConfig
+	if problems.checkFileExists(configFilePath, "aligned_layer_deployment_config_file_path", baseConfigFromYaml.AlignedLayerDeploymentConfigFilePath) {
+		var err error
+		alignedLayerDeploymentConfig, err = NewAlignedLayerDeploymentConfig(baseConfigFromYaml.AlignedLayerDeploymentConfigFilePath)
+		if err != nil {
+			problems.addErr(err)
+		}
 	}
 
-	if baseConfigFromYaml.EigenMetricsIpPortAddress == "" {
-		log.Fatal("Eigen metrics ip port address is empty")
+	var eigenLayerDeploymentConfig *EigenLayerDeploymentConfig
+	if problems.checkFileExists(configFilePath, "eigen_layer_deployment_config_file_path", baseConfigFromYaml.EigenLayerDeploymentConfigFilePath) {
+		var err error
+		eigenLayerDeploymentConfig, err = NewEigenLayerDeploymentConfig(baseConfigFromYaml.EigenLayerDeploymentConfigFilePath)
+		if err != nil {
+			problems.addErr(err)
+		}
 	}
+	return alignedLayerDeploymentConfig, eigenLayerDeploymentConfig
+}
 
-	return &BaseConfig{
-		AlignedLayerDeploymentConfig: alignedLayerDeploymentConfig,
-		EigenLayerDeploymentConfig:   eigenLayerDeploymentConfig,
-		Logger:                       logger,
-		EthRpcUrl:                    baseConfigFromYaml.EthRpcUrl,
-		EthWsUrl:                     baseConfigFromYaml.EthWsUrl,
-		EthRpcClient:                 *ethRpcClient,
-		EthRpcClientFallback:         *ethRpcClientFallback,
-		EthWsClient:                  *ethWsClient,
-		EthWsClientFallback:          *ethWsClientFallback,
-		EthRpcUrlFallback:            baseConfigFromYaml.EthRpcUrlFallback,
-		EthWsUrlFallback:             baseConfigFromYaml.EthWsUrlFallback,
-		EigenMetricsIpPortAddress:    baseConfigFromYaml.EigenMetricsIpPortAddress,
-		ChainId:                      chainId,
+// mergeUrls returns the primary and fallback urls followed by the extra ones, skipping empty and repeated urls
+func mergeUrls(primary string, fallback string, extra []string) []string {
+	urls := make([]string, 0, len(extra)+2)
+	seen := make(map[string]struct{})
+	for _, url := range append([]string{primary, fallback}, extra...) {
+		if url == "" {
+			continue
+		}
+		if _, ok := seen[url]; ok {
+			continue
+		}
+		seen[url] = struct{}{}
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// firstUrl returns the first url of the list, or an empty string if there is none
+func firstUrl(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
+
+// fallbackUrl returns the second url of the list, or the first one if there is only one
+func fallbackUrl(urls []string) string {
+	if len(urls) > 1 {
+		return urls[1]
+	}
+	return firstUrl(urls)
+}
+
+// EthWsUrlOrRpcUrl returns the url for the eigensdk websocket client. The sdk dials it even if it is only
+// used to subscribe to events, so the rpc url is used when there is no websocket endpoint
+func (c *BaseConfig) EthWsUrlOrRpcUrl() string {
+	if c.EthWsUrl == "" {
+		return c.EthRpcUrl
 	}
+	return c.EthWsUrl
 }
@@ -1,12 +1,9 @@
 package config
 
 import (
-	"errors"
-	"log"
-	"os"
+	"fmt"
 
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type BlsConfig struct {
@@ -20,27 +17,24 @@ type BlsConfigFromYaml struct {
 	} `yaml:"bls"`
 }
 
-func NewBlsConfig(blsConfigFilePath string) *BlsConfig {
-	if _, err := os.Stat(blsConfigFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup bls config file does not exist")
-	}
-
+func NewBlsConfig(blsConfigFilePath string) (*BlsConfig, error) {
 	var blsConfigFromYaml BlsConfigFromYaml
-	err := utils.ReadYamlConfig(blsConfigFilePath, &blsConfigFromYaml)
-	if err != nil {
-		log.Fatal("Error reading bls config: ", err)
+	if err := readYamlFile(blsConfigFilePath, &blsConfigFromYaml); err != nil {
+		return nil, err
 	}
 
-	if blsConfigFromYaml.Bls.PrivateKeyStorePath == "" {
-		log.Fatal("Bls private key store path is empty")
+	problems := validationErrors{}
+	if !problems.checkFileExists(blsConfigFilePath, "bls.private_key_store_path", blsConfigFromYaml.Bls.PrivateKeyStorePath) {
+		return nil, problems.err()
 	}
 
 	blsKeyPair, err := bls.ReadPrivateKeyFromFile(blsConfigFromYaml.Bls.PrivateKeyStorePath, blsConfigFromYaml.Bls.PrivateKeyStorePassword)
 	if err != nil {
-		log.Fatal("Error reading bls private key from file: ", err)
+		problems.add(blsConfigFilePath, "bls.private_key_store_path", fmt.Errorf("could not be decrypted: %w", err))
+		return nil, problems.err()
 	}
 
 	return &BlsConfig{
 		KeyPair: blsKeyPair,
-	}
+	}, nil
 }
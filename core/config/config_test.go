@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Could not write %s: %v", name, err)
+	}
+	return path
+}
+
+// fieldsWithProblems returns the fields of the problems of a ValidationError
+func fieldsWithProblems(t *testing.T, err error) map[string]error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	fields := make(map[string]error)
+	for _, problem := range validationErr.Errors {
+		var fieldErr *FieldError
+		if !errors.As(problem, &fieldErr) {
+			t.Fatalf("Expected a FieldError, got %v", problem)
+		}
+		fields[fieldErr.Field] = fieldErr.Err
+	}
+	return fields
+}
+
+func TestNewBaseConfigListsEveryProblem(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", `
+aligned_layer_deployment_config_file_path: ./does-not-exist.json
+environment: staging
+event_source: carrier-pigeon
+`)
+
+	baseConfig, err := NewBaseConfig(configFilePath)
+	if baseConfig != nil {
+		t.Fatalf("Expected no config for an invalid file")
+	}
+
+	fields := fieldsWithProblems(t, err)
+	expected := map[string]error{
+		"aligned_layer_deployment_config_file_path": ErrFileNotFound,
+		"eigen_layer_deployment_config_file_path":   ErrEmptyField,
+		"environment":                   ErrInvalidField,
+		"eth_rpc_url":                   ErrEmptyField,
+		"event_source":                  ErrInvalidField,
+		"eigen_metrics_ip_port_address": ErrEmptyField,
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d problems, got %v", len(expected), err)
+	}
+	for field, expectedErr := range expected {
+		if !errors.Is(fields[field], expectedErr) {
+			t.Errorf("Expected %s to fail with %v, got %v", field, expectedErr, fields[field])
+		}
+	}
+	if !errors.Is(err, ErrEmptyField) {
+		t.Errorf("Expected errors.Is to find the problems of the ValidationError")
+	}
+}
+
+func TestNewAlignedLayerDeploymentConfigEmptyAddresses(t *testing.T) {
+	deploymentFilePath := writeTestFile(t, "deployment.json", `{"addresses": {"multicall3": "0xcA11bde05977b3631167028862bE2a173976CA11"}}`)
+
+	_, err := NewAlignedLayerDeploymentConfig(deploymentFilePath)
+	fields := fieldsWithProblems(t, err)
+	for _, field := range []string{"addresses.alignedLayerServiceManager", "addresses.registryCoordinator", "addresses.operatorStateRetriever"} {
+		if !errors.Is(fields[field], ErrEmptyField) {
+			t.Errorf("Expected %s to be reported as empty, got %v", field, fields[field])
+		}
+	}
+}
+
+func TestNewOperatorConfigIncludesKeyStoreProblems(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", `
+environment: development
+eth_rpc_url: http://localhost:8545
+eigen_metrics_ip_port_address: localhost:9090
+aligned_layer_deployment_config_file_path: ../../contracts/script/output/devnet/alignedlayer_deployment_output.json
+eigen_layer_deployment_config_file_path: ../../contracts/script/output/devnet/eigenlayer_deployment_output.json
+bls:
+  private_key_store_path: ./missing.bls.key.json
+operator:
+  enable_metrics: true
+`)
+
+	_, err := NewOperatorConfig(configFilePath)
+	fields := fieldsWithProblems(t, err)
+	expected := map[string]error{
+		"bls.private_key_store_path":                     ErrFileNotFound,
+		"operator.address":                               ErrEmptyField,
+		"operator.aggregator_rpc_server_ip_port_address": ErrEmptyField,
+		"operator.last_processed_batch_filepath":         ErrEmptyField,
+		"operator.metrics_ip_port_address":               ErrEmptyField,
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d problems, got %v", len(expected), err)
+	}
+	for field, expectedErr := range expected {
+		if !errors.Is(fields[field], expectedErr) {
+			t.Errorf("Expected %s to fail with %v, got %v", field, expectedErr, fields[field])
+		}
+	}
+}
+
+func TestNewAggregatorConfigMissingFile(t *testing.T) {
+	_, err := NewAggregatorConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	if !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, got %v", err)
+	}
+}
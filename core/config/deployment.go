@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+// ErrDeploymentMismatch is the problem of a deployment address or chain that differs from the one on chain
+var ErrDeploymentMismatch = errors.New("does not match the chain")
+
+// Source of the deployment addresses read from the service manager
+const onChainSource = "service manager"
+
+// onChainDeployment has the addresses the AlignedLayerServiceManager points to
+type onChainDeployment struct {
+	registryCoordinator common.Address
+	stakeRegistry       common.Address
+	blsApkRegistry      common.Address
+	avsDirectory        common.Address
+	delegationManager   common.Address
+	slasher             common.Address
+}
+
+func readOnChainDeployment(ctx context.Context, caller bind.ContractCaller, serviceManagerAddr common.Address) (*onChainDeployment, error) {
+	serviceManager, err := servicemanager.NewContractAlignedLayerServiceManagerCaller(serviceManagerAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	deployment := &onChainDeployment{}
+	calls := []struct {
+		name    string
+		call    func(*bind.CallOpts) (common.Address, error)
+		address *common.Address
+	}{
+		{"registryCoordinator", serviceManager.RegistryCoordinator, &deployment.registryCoordinator},
+		{"stakeRegistry", serviceManager.StakeRegistry, &deployment.stakeRegistry},
+		{"blsApkRegistry", serviceManager.BlsApkRegistry, &deployment.blsApkRegistry},
+		{"avsDirectory", serviceManager.AvsDirectory, &deployment.avsDirectory},
+		{"delegation", serviceManager.Delegation, &deployment.delegationManager},
+	}
+	for _, c := range calls {
+		if *c.address, err = c.call(opts); err != nil {
+			return nil, fmt.Errorf("could not read %s from the service manager at %s: %w", c.name, serviceManagerAddr, err)
+		}
+	}
+
+	delegationManager, err := delegationmanager.NewContractDelegationManagerCaller(deployment.delegationManager, caller)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.slasher, err = delegationManager.Slasher(opts); err != nil {
+		return nil, fmt.Errorf("could not read slasher from the delegation manager at %s: %w", deployment.delegationManager, err)
+	}
+	return deployment, nil
+}
+
+// ResolveDeployment reads the addresses the service manager of the deployment points to, on the chain of caller.
+// Without an eigenLayer deployment, the configured service manager is the only source: the rest of the deployment is
+// filled in from the chain, and the operator state retriever, if not configured, from the network preset of the chain.
+// Otherwise every address of the deployment is checked against the chain, and the optional ones are filled in.
+// Mismatches are returned in a ValidationError
+func ResolveDeployment(ctx context.Context, caller bind.ContractCaller, chainId *big.Int, alignedLayer *AlignedLayerDeploymentConfig, eigenLayer *EigenLayerDeploymentConfig) (*AlignedLayerDeploymentConfig, *EigenLayerDeploymentConfig, error) {
+	problems := validationErrors{}
+	checkChainId := func(source string, deploymentChainId uint64) {
+		if deploymentChainId != 0 && new(big.Int).SetUint64(deploymentChainId).Cmp(chainId) != 0 {
+			problems.add(source, "chainInfo.chainId", fmt.Errorf("%w: %d, but the endpoint is on chain %s", ErrDeploymentMismatch, deploymentChainId, chainId))
+		}
+	}
+	checkChainId(alignedLayer.source, alignedLayer.ChainId)
+	if eigenLayer != nil {
+		checkChainId(eigenLayer.source, eigenLayer.ChainId)
+	}
+	if !problems.empty() {
+		return nil, nil, problems.err()
+	}
+
+	onChain, err := readOnChainDeployment(ctx, caller, alignedLayer.AlignedLayerServiceManagerAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolvedAlignedLayer := *alignedLayer
+	resolvedAlignedLayer.ChainId = chainId.Uint64()
+	if eigenLayer == nil {
+		resolvedAlignedLayer.AlignedLayerRegistryCoordinatorAddr = onChain.registryCoordinator
+		resolvedAlignedLayer.AlignedLayerStakeRegistryAddr = onChain.stakeRegistry
+		resolvedAlignedLayer.AlignedLayerBlsApkRegistryAddr = onChain.blsApkRegistry
+		if resolvedAlignedLayer.AlignedLayerOperatorStateRetrieverAddr == (common.Address{}) {
+			preset := networkDeploymentConfigOfChain(resolvedAlignedLayer.ChainId)
+			if preset == nil {
+				problems.add(alignedLayer.source, "aligned_layer_operator_state_retriever_address", fmt.Errorf("%w, there is no network preset for chain %s to take it from", ErrEmptyField, chainId))
+				return nil, nil, problems.err()
+			}
+			resolvedAlignedLayer.AlignedLayerOperatorStateRetrieverAddr = preset.AlignedLayerOperatorStateRetrieverAddr
+		}
+		return &resolvedAlignedLayer, &EigenLayerDeploymentConfig{
+			DelegationManagerAddr: onChain.delegationManager,
+			AVSDirectoryAddr:      onChain.avsDirectory,
+			SlasherAddr:           onChain.slasher,
+			ChainId:               chainId.Uint64(),
+			source:                onChainSource,
+		}, nil
+	}
+
+	checkAddress := func(source string, field string, configured *common.Address, actual common.Address) {
+		// The optional addresses are filled in
+		if *configured == (common.Address{}) {
+			*configured = actual
+			return
+		}
+		if *configured != actual {
+			problems.add(source, field, fmt.Errorf("%w: %s is configured, but the %s points to %s", ErrDeploymentMismatch, *configured, onChainSource, actual))
+		}
+	}
+	resolvedEigenLayer := *eigenLayer
+	resolvedEigenLayer.ChainId = chainId.Uint64()
+	checkAddress(alignedLayer.source, "addresses.registryCoordinator", &resolvedAlignedLayer.AlignedLayerRegistryCoordinatorAddr, onChain.registryCoordinator)
+	checkAddress(alignedLayer.source, "addresses.stakeRegistry", &resolvedAlignedLayer.AlignedLayerStakeRegistryAddr, onChain.stakeRegistry)
+	checkAddress(alignedLayer.source, "addresses.blsApkRegistry", &resolvedAlignedLayer.AlignedLayerBlsApkRegistryAddr, onChain.blsApkRegistry)
+	checkAddress(eigenLayer.source, "addresses.avsDirectory", &resolvedEigenLayer.AVSDirectoryAddr, onChain.avsDirectory)
+	checkAddress(eigenLayer.source, "addresses.delegationManager", &resolvedEigenLayer.DelegationManagerAddr, onChain.delegationManager)
+	checkAddress(eigenLayer.source, "addresses.slasher", &resolvedEigenLayer.SlasherAddr, onChain.slasher)
+	if !problems.empty() {
+		return nil, nil, problems.err()
+	}
+	return &resolvedAlignedLayer, &resolvedEigenLayer, nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testContractCaller answers the getters of the service manager and delegation manager with fixed addresses
+type testContractCaller map[string]common.Address
+
+func (c testContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (c testContractCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	for method, address := range c {
+		if bytes.Equal(call.Data[:4], crypto.Keccak256([]byte(method + "()"))[:4]) {
+			return common.LeftPadBytes(address.Bytes(), 32), nil
+		}
+	}
+	return nil, errors.New("execution reverted")
+}
+
+// newTestContractCaller returns a caller with the addresses of a network preset
+func newTestContractCaller(t *testing.T, network string) testContractCaller {
+	alignedLayer, eigenLayer, err := NetworkDeploymentConfig(network)
+	if err != nil {
+		t.Fatalf("Could not read network preset: %v", err)
+	}
+	return testContractCaller{
+		"registryCoordinator": alignedLayer.AlignedLayerRegistryCoordinatorAddr,
+		"stakeRegistry":       alignedLayer.AlignedLayerStakeRegistryAddr,
+		"blsApkRegistry":      alignedLayer.AlignedLayerBlsApkRegistryAddr,
+		"avsDirectory":        eigenLayer.AVSDirectoryAddr,
+		"delegation":          eigenLayer.DelegationManagerAddr,
+		"slasher":             eigenLayer.SlasherAddr,
+	}
+}
+
+func TestNetworkPresetsMatchDeploymentOutputs(t *testing.T) {
+	for _, network := range Networks {
+		for _, fileName := range []string{"alignedlayer_deployment_output.json", "eigenlayer_deployment_output.json"} {
+			preset, err := networkPresets.ReadFile(path.Join("networks", network, fileName))
+			if err != nil {
+				t.Fatalf("Could not read preset: %v", err)
+			}
+			output, err := os.ReadFile(filepath.Join("../../contracts/script/output", network, fileName))
+			if err != nil {
+				t.Fatalf("Could not read deployment output: %v", err)
+			}
+			if !bytes.Equal(preset, output) {
+				t.Errorf("The %s preset of %s is outdated, run make update_network_presets", fileName, network)
+			}
+		}
+	}
+
+	if _, _, err := NetworkDeploymentConfig("goerli"); err == nil {
+		t.Errorf("Expected an unknown network to fail")
+	}
+}
+
+func TestCheckDeploymentConfigSources(t *testing.T) {
+	problems := validationErrors{}
+	alignedLayer, eigenLayer := checkDeploymentConfig("config.yaml", &BaseConfigFromYaml{Network: "holesky"}, &problems)
+	if !problems.empty() || alignedLayer.ChainId != 17000 || eigenLayer.ChainId != 17000 {
+		t.Errorf("Expected the holesky preset, got %+v, %+v, %v", alignedLayer, eigenLayer, problems.err())
+	}
+
+	serviceManager := common.HexToAddress("0x58F280BeBE9B34c9939C3C39e0890C81f163B623")
+	alignedLayer, eigenLayer = checkDeploymentConfig("config.yaml", &BaseConfigFromYaml{AlignedLayerServiceManagerAddress: serviceManager}, &problems)
+	if !problems.empty() || alignedLayer.AlignedLayerServiceManagerAddr != serviceManager || eigenLayer != nil {
+		t.Errorf("Expected only the service manager, got %+v, %+v, %v", alignedLayer, eigenLayer, problems.err())
+	}
+
+	checkDeploymentConfig("config.yaml", &BaseConfigFromYaml{Network: "holesky", AlignedLayerDeploymentConfigFilePath: "./deployment.json"}, &problems)
+	if fields := fieldsWithProblems(t, problems.err()); !errors.Is(fields["network"], ErrInvalidField) {
+		t.Errorf("Expected setting two sources to fail, got %v", fields)
+	}
+}
+
+func TestResolveDeploymentDiscoversAddresses(t *testing.T) {
+	devnet, devnetEigenLayer, err := NetworkDeploymentConfig("devnet")
+	if err != nil {
+		t.Fatalf("Could not read network preset: %v", err)
+	}
+	configured := &AlignedLayerDeploymentConfig{AlignedLayerServiceManagerAddr: devnet.AlignedLayerServiceManagerAddr, source: "config.yaml"}
+
+	alignedLayer, eigenLayer, err := ResolveDeployment(context.Background(), newTestContractCaller(t, "devnet"), big.NewInt(31337), configured, nil)
+	if err != nil {
+		t.Fatalf("Could not resolve deployment: %v", err)
+	}
+	if alignedLayer.AlignedLayerRegistryCoordinatorAddr != devnet.AlignedLayerRegistryCoordinatorAddr ||
+		alignedLayer.AlignedLayerStakeRegistryAddr != devnet.AlignedLayerStakeRegistryAddr ||
+		alignedLayer.AlignedLayerBlsApkRegistryAddr != devnet.AlignedLayerBlsApkRegistryAddr {
+		t.Errorf("Unexpected aligned layer deployment %+v", alignedLayer)
+	}
+	// Not pointed to by the service manager, taken from the preset of the chain
+	if alignedLayer.AlignedLayerOperatorStateRetrieverAddr != devnet.AlignedLayerOperatorStateRetrieverAddr {
+		t.Errorf("Expected the operator state retriever of the devnet preset, got %s", alignedLayer.AlignedLayerOperatorStateRetrieverAddr)
+	}
+	if eigenLayer.DelegationManagerAddr != devnetEigenLayer.DelegationManagerAddr || eigenLayer.AVSDirectoryAddr != devnetEigenLayer.AVSDirectoryAddr ||
+		eigenLayer.SlasherAddr != devnetEigenLayer.SlasherAddr || eigenLayer.ChainId != 31337 {
+		t.Errorf("Unexpected eigen layer deployment %+v", eigenLayer)
+	}
+
+	_, _, err = ResolveDeployment(context.Background(), newTestContractCaller(t, "devnet"), big.NewInt(5), configured, nil)
+	if fields := fieldsWithProblems(t, err); !errors.Is(fields["aligned_layer_operator_state_retriever_address"], ErrEmptyField) {
+		t.Errorf("Expected the operator state retriever to be required without a preset, got %v", fields)
+	}
+}
+
+func TestResolveDeploymentReportsMismatches(t *testing.T) {
+	alignedLayer, eigenLayer, err := NetworkDeploymentConfig("holesky")
+	if err != nil {
+		t.Fatalf("Could not read network preset: %v", err)
+	}
+	caller := newTestContractCaller(t, "holesky")
+
+	if _, _, err := ResolveDeployment(context.Background(), caller, big.NewInt(17000), alignedLayer, eigenLayer); err != nil {
+		t.Errorf("Expected the preset to match its chain, got %v", err)
+	}
+
+	caller["registryCoordinator"] = common.HexToAddress("0x1")
+	caller["slasher"] = common.HexToAddress("0x2")
+	_, _, err = ResolveDeployment(context.Background(), caller, big.NewInt(17000), alignedLayer, eigenLayer)
+	fields := fieldsWithProblems(t, err)
+	if len(fields) != 2 || !errors.Is(fields["addresses.registryCoordinator"], ErrDeploymentMismatch) || !errors.Is(fields["addresses.slasher"], ErrDeploymentMismatch) {
+		t.Errorf("Expected the registry coordinator and slasher to mismatch, got %v", err)
+	}
+
+	_, _, err = ResolveDeployment(context.Background(), caller, big.NewInt(1), alignedLayer, eigenLayer)
+	if fields := fieldsWithProblems(t, err); !errors.Is(fields["chainInfo.chainId"], ErrDeploymentMismatch) {
+		t.Errorf("Expected the chain id to mismatch, got %v", fields)
+	}
+}
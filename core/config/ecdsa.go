@@ -2,14 +2,11 @@ package config
 
 import (
 	"crypto/ecdsa"
-	"errors"
-	"log"
+	"fmt"
 	"math/big"
-	"os"
 
 	ecdsa2 "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
 	"github.com/Layr-Labs/eigensdk-go/signer"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type EcdsaConfig struct {
@@ -24,33 +21,43 @@ type EcdsaConfigFromYaml struct {
 	} `yaml:"ecdsa"`
 }
 
-func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) *EcdsaConfig {
-	if _, err := os.Stat(ecdsaConfigFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup ecdsa config file does not exist")
-	}
-
-	var ecdsaConfigFromYaml EcdsaConfigFromYaml
-	err := utils.ReadYamlConfig(ecdsaConfigFilePath, &ecdsaConfigFromYaml)
-	if err != nil {
-		log.Fatal("Error reading ecdsa config: ", err)
-	}
-
-	if ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath == "" {
-		log.Fatal("Ecdsa private key store path is empty")
-	}
-
-	ecdsaKeyPair, err := ecdsa2.ReadKey(ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath, ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePassword)
+func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) (*EcdsaConfig, error) {
+	ecdsaKeyPair, err := readEcdsaKey(ecdsaConfigFilePath)
 	if err != nil {
-		log.Fatal("Error reading ecdsa private key from file: ", err)
+		return nil, err
 	}
+	return newEcdsaConfig(ecdsaKeyPair, chainId)
+}
 
+func newEcdsaConfig(ecdsaKeyPair *ecdsa.PrivateKey, chainId *big.Int) (*EcdsaConfig, error) {
 	privateKeySigner, err := signer.NewPrivateKeySigner(ecdsaKeyPair, chainId)
 	if err != nil {
-		log.Fatal("Error creating private key signer: ", err)
+		return nil, fmt.Errorf("error creating private key signer: %w", err)
 	}
 
 	return &EcdsaConfig{
 		PrivateKey: ecdsaKeyPair,
 		Signer:     privateKeySigner,
+	}, nil
+}
+
+// readEcdsaKey decrypts the key store of the ecdsa section of the config file.
+// The key store can be checked with it before the chain id for the signer is known
+func readEcdsaKey(ecdsaConfigFilePath string) (*ecdsa.PrivateKey, error) {
+	var ecdsaConfigFromYaml EcdsaConfigFromYaml
+	if err := readYamlFile(ecdsaConfigFilePath, &ecdsaConfigFromYaml); err != nil {
+		return nil, err
+	}
+
+	problems := validationErrors{}
+	if !problems.checkFileExists(ecdsaConfigFilePath, "ecdsa.private_key_store_path", ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath) {
+		return nil, problems.err()
+	}
+
+	ecdsaKeyPair, err := ecdsa2.ReadKey(ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath, ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePassword)
+	if err != nil {
+		problems.add(ecdsaConfigFilePath, "ecdsa.private_key_store_path", fmt.Errorf("could not be decrypted: %w", err))
+		return nil, problems.err()
 	}
+	return ecdsaKeyPair, nil
 }
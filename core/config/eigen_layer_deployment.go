@@ -1,18 +1,17 @@
 package config
 
 import (
-	"errors"
-	"log"
-	"os"
-
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type EigenLayerDeploymentConfig struct {
 	DelegationManagerAddr common.Address
 	AVSDirectoryAddr      common.Address
 	SlasherAddr           common.Address
+	// Chain the contracts were deployed to, 0 if the file does not say
+	ChainId uint64
+	// File or network preset the deployment was read from, to report where a mismatch with the chain comes from
+	source string
 }
 
 type EigenLayerDeploymentConfigFromJson struct {
@@ -21,36 +20,43 @@ type EigenLayerDeploymentConfigFromJson struct {
 		AVSDirectoryAddr      common.Address `json:"avsDirectory"`
 		SlasherAddr           common.Address `json:"slasher"`
 	} `json:"addresses"`
+	ChainInfo struct {
+		ChainId uint64 `json:"chainId"`
+	} `json:"chainInfo"`
 }
 
-func NewEigenLayerDeploymentConfig(eigenLayerDeploymentFilePath string) *EigenLayerDeploymentConfig {
-
-	if _, err := os.Stat(eigenLayerDeploymentFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup eigen layer deployment file does not exist")
-	}
-
+func NewEigenLayerDeploymentConfig(eigenLayerDeploymentFilePath string) (*EigenLayerDeploymentConfig, error) {
 	var eigenLayerDeploymentConfigFromJson EigenLayerDeploymentConfigFromJson
-	err := utils.ReadJsonConfig(eigenLayerDeploymentFilePath, &eigenLayerDeploymentConfigFromJson)
-
-	if err != nil {
-		log.Fatal("Error reading eigen layer deployment config: ", err)
+	if err := readJsonFile(eigenLayerDeploymentFilePath, &eigenLayerDeploymentConfigFromJson); err != nil {
+		return nil, err
 	}
+	return newEigenLayerDeploymentConfig(eigenLayerDeploymentFilePath, &eigenLayerDeploymentConfigFromJson)
+}
 
+// newEigenLayerDeploymentConfig checks a deployment parsed from source, a file or a network preset
+func newEigenLayerDeploymentConfig(source string, eigenLayerDeploymentConfigFromJson *EigenLayerDeploymentConfigFromJson) (*EigenLayerDeploymentConfig, error) {
+	problems := validationErrors{}
 	if eigenLayerDeploymentConfigFromJson.Addresses.DelegationManagerAddr == common.HexToAddress("") {
-		log.Fatal("Delegation manager address is empty")
+		problems.add(source, "addresses.delegationManager", ErrEmptyField)
 	}
 
 	if eigenLayerDeploymentConfigFromJson.Addresses.AVSDirectoryAddr == common.HexToAddress("") {
-		log.Fatal("AVS directory address is empty")
+		problems.add(source, "addresses.avsDirectory", ErrEmptyField)
 	}
 
 	if eigenLayerDeploymentConfigFromJson.Addresses.SlasherAddr == common.HexToAddress("") {
-		log.Fatal("Slasher address is empty")
+		problems.add(source, "addresses.slasher", ErrEmptyField)
+	}
+
+	if !problems.empty() {
+		return nil, problems.err()
 	}
 
 	return &EigenLayerDeploymentConfig{
 		DelegationManagerAddr: eigenLayerDeploymentConfigFromJson.Addresses.DelegationManagerAddr,
 		AVSDirectoryAddr:      eigenLayerDeploymentConfigFromJson.Addresses.AVSDirectoryAddr,
 		SlasherAddr:           eigenLayerDeploymentConfigFromJson.Addresses.SlasherAddr,
-	}
+		ChainId:               eigenLayerDeploymentConfigFromJson.ChainInfo.ChainId,
+		source:                source,
+	}, nil
 }
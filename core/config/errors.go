@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// Problems of a config field, wrapped in a FieldError
+var (
+	ErrFileNotFound = errors.New("file does not exist")
+	ErrEmptyField   = errors.New("is empty")
+	ErrInvalidField = errors.New("is invalid")
+)
+
+// FieldError is a problem with a field of a config file
+type FieldError struct {
+	File string
+	// Key of the field in the file, such as "bls.private_key_store_path". Empty for problems of the whole file
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s: %s %v", e.File, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ValidationError lists every problem found while loading a config.
+// Use errors.As to get the FieldError of a problem, and errors.Is to look for one of the ErrXxx above
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("invalid config, %d problem(s) found:", len(e.Errors)))
+	for _, err := range e.Errors {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error { return e.Errors }
+
+// validationErrors collects the problems found while loading a config
+type validationErrors struct {
+	errors []error
+}
+
+func (v *validationErrors) add(file string, field string, err error) {
+	v.errors = append(v.errors, &FieldError{File: file, Field: field, Err: err})
+}
+
+// addErr adds an error returned by another loader, flattening the problems of a ValidationError
+func (v *validationErrors) addErr(err error) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		v.errors = append(v.errors, validationErr.Errors...)
+		return
+	}
+	v.errors = append(v.errors, err)
+}
+
+func (v *validationErrors) empty() bool {
+	return len(v.errors) == 0
+}
+
+// err returns a ValidationError with the problems found, or nil if there are none
+func (v *validationErrors) err() error {
+	if v.empty() {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+// checkFileExists adds a problem if the file at path does not exist
+func (v *validationErrors) checkFileExists(file string, field string, path string) bool {
+	if path == "" {
+		v.add(file, field, ErrEmptyField)
+		return false
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		v.add(file, field, fmt.Errorf("%w: %s", ErrFileNotFound, path))
+		return false
+	}
+	return true
+}
+
+// readJsonFile parses a json config file, returning a ValidationError if it does not exist or can not be parsed
+func readJsonFile(path string, o interface{}) error {
+	return readFile(path, o, utils.ReadJsonConfig)
+}
+
+func readFile(path string, o interface{}, read func(string, interface{}) error) error {
+	problems := validationErrors{}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		problems.add(path, "", ErrFileNotFound)
+		return problems.err()
+	}
+	if err := read(path, o); err != nil {
+		problems.add(path, "", err)
+	}
+	return problems.err()
+}
@@ -2,11 +2,27 @@ package config
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
 )
 
-func NewLogger(loggingLevel sdklogging.LogLevel) (sdklogging.Logger, error) {
+// NewLogger returns a logger for the environment, which can be changed later with SetEnvironment
+func NewLogger(loggingLevel sdklogging.LogLevel) (*ReloadableLogger, error) {
+	logger, err := newZapLogger(loggingLevel)
+	if err != nil {
+		return nil, err
+	}
+	reloadableLogger := &ReloadableLogger{}
+	reloadableLogger.logger.Store(&logger)
+	return reloadableLogger, nil
+}
+
+func newZapLogger(loggingLevel sdklogging.LogLevel) (sdklogging.Logger, error) {
+	// The sdk panics on unknown environments
+	if loggingLevel != sdklogging.Development && loggingLevel != sdklogging.Production {
+		return nil, fmt.Errorf("unknown environment %q, expected %q or %q", loggingLevel, sdklogging.Development, sdklogging.Production)
+	}
 	logger, err := sdklogging.NewZapLogger(loggingLevel)
 	if err != nil {
 		fmt.Println("Could not initialize logger")
@@ -14,3 +30,76 @@ func NewLogger(loggingLevel sdklogging.LogLevel) (sdklogging.Logger, error) {
 	}
 	return logger, nil
 }
+
+// ReloadableLogger is a logger whose environment can be changed while it is in use.
+// Loggers derived from it with With follow the changes too
+type ReloadableLogger struct {
+	logger atomic.Pointer[sdklogging.Logger]
+	// Set for the loggers returned by With
+	parent *ReloadableLogger
+	tags   []any
+}
+
+// SetEnvironment swaps the logger for one of the environment
+func (l *ReloadableLogger) SetEnvironment(loggingLevel sdklogging.LogLevel) error {
+	setEnvironment, err := l.PrepareEnvironment(loggingLevel)
+	if err != nil {
+		return err
+	}
+	setEnvironment()
+	return nil
+}
+
+// PrepareEnvironment creates the logger for the environment without swapping it yet.
+// The returned function swaps it, so it can be applied along with other changes once they are all valid
+func (l *ReloadableLogger) PrepareEnvironment(loggingLevel sdklogging.LogLevel) (func(), error) {
+	if l.parent != nil {
+		return l.parent.PrepareEnvironment(loggingLevel)
+	}
+	logger, err := newZapLogger(loggingLevel)
+	if err != nil {
+		return nil, err
+	}
+	return func() { l.logger.Store(&logger) }, nil
+}
+
+func (l *ReloadableLogger) current() sdklogging.Logger {
+	if l.parent != nil {
+		return l.parent.current().With(l.tags...)
+	}
+	return *l.logger.Load()
+}
+
+func (l *ReloadableLogger) Debug(msg string, tags ...any) { l.current().Debug(msg, tags...) }
+
+func (l *ReloadableLogger) Info(msg string, tags ...any) { l.current().Info(msg, tags...) }
+
+func (l *ReloadableLogger) Warn(msg string, tags ...any) { l.current().Warn(msg, tags...) }
+
+func (l *ReloadableLogger) Error(msg string, tags ...any) { l.current().Error(msg, tags...) }
+
+func (l *ReloadableLogger) Fatal(msg string, tags ...any) { l.current().Fatal(msg, tags...) }
+
+func (l *ReloadableLogger) Debugf(template string, args ...interface{}) {
+	l.current().Debugf(template, args...)
+}
+
+func (l *ReloadableLogger) Infof(template string, args ...interface{}) {
+	l.current().Infof(template, args...)
+}
+
+func (l *ReloadableLogger) Warnf(template string, args ...interface{}) {
+	l.current().Warnf(template, args...)
+}
+
+func (l *ReloadableLogger) Errorf(template string, args ...interface{}) {
+	l.current().Errorf(template, args...)
+}
+
+func (l *ReloadableLogger) Fatalf(template string, args ...interface{}) {
+	l.current().Fatalf(template, args...)
+}
+
+func (l *ReloadableLogger) With(tags ...any) sdklogging.Logger {
+	return &ReloadableLogger{parent: l, tags: tags}
+}
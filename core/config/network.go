@@ -0,0 +1,67 @@
+package config
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Deployments of the networks Aligned runs on, a copy of the ones in contracts/script/output
+//
+//go:embed networks
+var networkPresets embed.FS
+
+// Networks that can be set in the network field of a config file instead of the deployment config files
+var Networks = []string{"mainnet", "holesky", "devnet"}
+
+// NetworkDeploymentConfig returns the deployment of one of the Networks, embedded in the binary
+func NetworkDeploymentConfig(network string) (*AlignedLayerDeploymentConfig, *EigenLayerDeploymentConfig, error) {
+	if !slices.Contains(Networks, network) {
+		return nil, nil, fmt.Errorf("unknown network %q, expected one of %s", network, strings.Join(Networks, ", "))
+	}
+	source := fmt.Sprintf("network preset %s", network)
+
+	var alignedLayerDeploymentConfigFromJson AlignedLayerDeploymentConfigFromJson
+	if err := readNetworkPreset(network, "alignedlayer_deployment_output.json", &alignedLayerDeploymentConfigFromJson); err != nil {
+		return nil, nil, err
+	}
+	alignedLayerDeploymentConfig, err := newAlignedLayerDeploymentConfig(source, &alignedLayerDeploymentConfigFromJson)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var eigenLayerDeploymentConfigFromJson EigenLayerDeploymentConfigFromJson
+	if err := readNetworkPreset(network, "eigenlayer_deployment_output.json", &eigenLayerDeploymentConfigFromJson); err != nil {
+		return nil, nil, err
+	}
+	eigenLayerDeploymentConfig, err := newEigenLayerDeploymentConfig(source, &eigenLayerDeploymentConfigFromJson)
+	if err != nil {
+		return nil, nil, err
+	}
+	return alignedLayerDeploymentConfig, eigenLayerDeploymentConfig, nil
+}
+
+func readNetworkPreset(network string, fileName string, o interface{}) error {
+	b, err := networkPresets.ReadFile(path.Join("networks", network, fileName))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, o); err != nil {
+		return fmt.Errorf("unable to parse %s of network preset %s: %w", fileName, network, err)
+	}
+	return nil
+}
+
+// networkDeploymentConfigOfChain returns the Aligned deployment of the preset on the chain, or nil if there is none
+func networkDeploymentConfigOfChain(chainId uint64) *AlignedLayerDeploymentConfig {
+	for _, network := range Networks {
+		alignedLayerDeploymentConfig, _, err := NetworkDeploymentConfig(network)
+		if err == nil && alignedLayerDeploymentConfig.ChainId == chainId {
+			return alignedLayerDeploymentConfig
+		}
+	}
+	return nil
+}
@@ -1,12 +1,10 @@
 package config
 
 import (
-	"errors"
-	"log"
-	"os"
+	"fmt"
 
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type OperatorConfig struct {
@@ -45,29 +43,18 @@ type OperatorConfigFromYaml struct {
 		MaxBatchSize                  int64          `yaml:"max_batch_size"`
 		LastProcessedBatchFilePath    string         `yaml:"last_processed_batch_filepath"`
 	} `yaml:"operator"`
-	BlsConfigFromYaml   BlsConfigFromYaml   `yaml:"bls"`
 }
 
-func NewOperatorConfig(configFilePath string) *OperatorConfig {
-	if _, err := os.Stat(configFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup config file does not exist")
+func NewOperatorConfig(configFilePath string) (*OperatorConfig, error) {
+	problems := validationErrors{}
+	operatorConfigFromYaml, blsConfig := checkOperatorConfig(configFilePath, &problems)
+	if operatorConfigFromYaml == nil {
+		return nil, problems.err()
 	}
 
-	baseConfig := NewBaseConfig(configFilePath)
-	if baseConfig == nil {
-		log.Fatal("Error reading base config: ")
-	}
-
-	blsConfig := NewBlsConfig(configFilePath)
-	if blsConfig == nil {
-		log.Fatal("Error reading bls config: ")
-	}
-
-	var operatorConfigFromYaml OperatorConfigFromYaml
-	err := utils.ReadYamlConfig(configFilePath, &operatorConfigFromYaml)
-
+	baseConfig, err := newBaseConfig(configFilePath, &problems)
 	if err != nil {
-		log.Fatal("Error reading operator config: ", err)
+		return nil, err
 	}
 
 	return &OperatorConfig{
@@ -88,5 +75,103 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 			MaxBatchSize                  int64
 			LastProcessedBatchFilePath    string
 		}(operatorConfigFromYaml.Operator),
+	}, nil
+}
+
+// checkOperatorConfig adds the problems of the operator section of the config file to problems.
+// It returns a nil config if the file can not be read
+func checkOperatorConfig(configFilePath string, problems *validationErrors) (*OperatorConfigFromYaml, *BlsConfig) {
+	var operatorConfigFromYaml OperatorConfigFromYaml
+	if err := readYamlFile(configFilePath, &operatorConfigFromYaml); err != nil {
+		problems.addErr(err)
+		return nil, nil
+	}
+
+	blsConfig, err := NewBlsConfig(configFilePath)
+	if err != nil {
+		problems.addErr(err)
+	}
+
+	if operatorConfigFromYaml.Operator.Address == (common.Address{}) {
+		problems.add(configFilePath, "operator.address", ErrEmptyField)
+	}
+	if operatorConfigFromYaml.Operator.AggregatorServerIpPortAddress == "" {
+		problems.add(configFilePath, "operator.aggregator_rpc_server_ip_port_address", ErrEmptyField)
+	}
+	if operatorConfigFromYaml.Operator.LastProcessedBatchFilePath == "" {
+		problems.add(configFilePath, "operator.last_processed_batch_filepath", ErrEmptyField)
+	}
+	if operatorConfigFromYaml.Operator.EnableMetrics && operatorConfigFromYaml.Operator.MetricsIpPortAddress == "" {
+		problems.add(configFilePath, "operator.metrics_ip_port_address", fmt.Errorf("%w, but metrics are enabled", ErrEmptyField))
+	}
+
+	return &operatorConfigFromYaml, blsConfig
+}
+
+// OperatorSettings are the fields of the operator config that can be changed while it runs, see OperatorReloader
+type OperatorSettings struct {
+	AggregatorServerIpPortAddress string
+	MaxBatchSize                  int64
+}
+
+// Settings returns the fields of the config that can be reloaded
+func (c *OperatorConfig) Settings() *OperatorSettings {
+	return &OperatorSettings{
+		AggregatorServerIpPortAddress: c.Operator.AggregatorServerIpPortAddress,
+		MaxBatchSize:                  c.Operator.MaxBatchSize,
+	}
+}
+
+// OperatorReloadableFields are the fields of the operator config file that can be reloaded
+var OperatorReloadableFields = []string{
+	"environment",
+	"operator.aggregator_rpc_server_ip_port_address",
+	"operator.max_batch_size",
+}
+
+// operatorConfigFile has every section of the config file read by the operator
+type operatorConfigFile struct {
+	Base     BaseConfigFromYaml     `yaml:",inline"`
+	Operator OperatorConfigFromYaml `yaml:",inline"`
+	Bls      BlsConfigFromYaml      `yaml:",inline"`
+}
+
+// OperatorReloader re-reads the config file of a running operator
+type OperatorReloader struct {
+	reloader *reloader
+}
+
+// NewOperatorReloader reads the config file the following reloads are compared with
+func NewOperatorReloader(configFilePath string) (*OperatorReloader, error) {
+	reloader, err := newReloader(configFilePath, &operatorConfigFile{}, OperatorReloadableFields)
+	if err != nil {
+		return nil, err
+	}
+	return &OperatorReloader{reloader: reloader}, nil
+}
+
+// Reload re-reads the config file and calls apply with the environment and settings in it.
+// Nothing is applied if a field that is not one of the OperatorReloadableFields changed, or a changed field is
+// invalid, returning the problems in a ValidationError. It returns the changed fields
+func (r *OperatorReloader) Reload(apply func(environment sdklogging.LogLevel, settings *OperatorSettings) error) ([]FieldChange, error) {
+	next := &operatorConfigFile{}
+	check := func(change FieldChange, problems *validationErrors) {
+		switch value := change.New.(type) {
+		case string:
+			if value == "" {
+				problems.add(r.reloader.configFilePath, change.Field, ErrEmptyField)
+			}
+		case int64:
+			if value <= 0 {
+				problems.add(r.reloader.configFilePath, change.Field, fmt.Errorf("%w: %d, expected a positive size", ErrInvalidField, value))
+			}
+		}
+		r.reloader.checkReloadedField(change, problems)
 	}
+	return r.reloader.reload(next, check, func() error {
+		return apply(next.Base.Environment, &OperatorSettings{
+			AggregatorServerIpPortAddress: next.Operator.Operator.AggregatorServerIpPortAddress,
+			MaxBatchSize:                  next.Operator.Operator.MaxBatchSize,
+		})
+	})
 }
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/mattn/go-isatty"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Every field of a yaml config file can be set from the sources below. Each one overrides the previous ones:
+//  1. The config file, either with the value (`eth_rpc_url: ...`) or with a file holding it, adding the
+//     FileSuffix to the key (`eth_rpc_url_file: /run/secrets/eth_rpc_url`)
+//  2. The environment, either with the value or with a file holding it. The variable is the EnvPrefix followed by
+//     the path of the field in upper case joined by underscores, such as ALIGNED_ETH_RPC_URL or
+//     ALIGNED_BLS_PRIVATE_KEY_STORE_PASSWORD, and the FileSuffix in upper case for a file (ALIGNED_ETH_RPC_URL_FILE)
+//  3. For key store passwords not set by any of the sources above, a prompt, if stdin is a terminal
+//
+// Setting both the value and the file in the same source is an error.
+// Values read from files have their trailing newline removed. Lists are comma separated in the environment
+const (
+	EnvPrefix  = "ALIGNED_"
+	FileSuffix = "_file"
+)
+
+// envSource is the File of the FieldErrors of environment variables
+const envSource = "environment"
+
+// Passwords already prompted for, by config file and field, so they are asked once per process
+var (
+	promptedPasswordsMutex sync.Mutex
+	promptedPasswords      = make(map[string]string)
+)
+
+// EnvVarName returns the environment variable that overrides the field at the yaml path
+func EnvVarName(path ...string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(path, "_"))
+}
+
+// readYamlFile parses a yaml config file applying the overrides described above.
+// It returns a ValidationError if the file does not exist, can not be parsed or an override can not be read
+func readYamlFile(path string, o interface{}) error {
+	problems := validationErrors{}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		problems.add(path, "", ErrFileNotFound)
+		return problems.err()
+	}
+
+	b, err := utils.ReadFile(path)
+	if err != nil {
+		problems.add(path, "", err)
+		return problems.err()
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(b, &document); err != nil {
+		problems.add(path, "", fmt.Errorf("unable to parse file: %w", err))
+		return problems.err()
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+		root = document.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		problems.add(path, "", fmt.Errorf("unable to parse file: expected a mapping at the top level"))
+		return problems.err()
+	}
+
+	overrides := &overrider{file: path, problems: &problems}
+	overrides.apply(root, reflect.TypeOf(o).Elem(), nil)
+	if !problems.empty() {
+		return problems.err()
+	}
+
+	if err := root.Decode(o); err != nil {
+		problems.add(path, "", fmt.Errorf("unable to parse file: %w", err))
+	}
+	return problems.err()
+}
+
+// overrider sets the fields of a parsed config file from the other sources
+type overrider struct {
+	file     string
+	problems *validationErrors
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// apply overrides the fields of the struct type t under the mapping node, found at path in the file
+func (o *overrider) apply(mapping *yaml.Node, t reflect.Type, path []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if len(tag) > 1 && tag[1] == "inline" && field.Type.Kind() == reflect.Struct {
+			o.apply(mapping, field.Type, path)
+			continue
+		}
+		key := tag[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), key)
+
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			child := mappingValue(mapping, key)
+			if child == nil {
+				child = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(mapping, key, child)
+			}
+			if child.Kind == yaml.MappingNode {
+				o.apply(child, field.Type, fieldPath)
+			}
+			continue
+		}
+		o.applyField(mapping, field.Type, fieldPath)
+	}
+}
+
+func (o *overrider) applyField(mapping *yaml.Node, t reflect.Type, path []string) {
+	key := path[len(path)-1]
+	fieldName := strings.Join(path, ".")
+	set := mappingValue(mapping, key) != nil
+
+	if fileNode := mappingValue(mapping, key+FileSuffix); fileNode != nil {
+		if set {
+			o.problems.add(o.file, fieldName+FileSuffix, fmt.Errorf("%w: %s is also set", ErrInvalidField, key))
+			return
+		}
+		if value, ok := o.readValueFile(o.file, fieldName+FileSuffix, fileNode.Value); ok {
+			setMappingValue(mapping, key, valueNode(t, value))
+			set = true
+		}
+	}
+
+	envVar := EnvVarName(path...)
+	envValue, envSet := os.LookupEnv(envVar)
+	envFile, envFileSet := os.LookupEnv(envVar + strings.ToUpper(FileSuffix))
+	switch {
+	case envSet && envFileSet:
+		o.problems.add(envSource, envVar+strings.ToUpper(FileSuffix), fmt.Errorf("%w: %s is also set", ErrInvalidField, envVar))
+		return
+	case envSet:
+		setMappingValue(mapping, key, valueNode(t, envValue))
+		return
+	case envFileSet:
+		if value, ok := o.readValueFile(envSource, envVar+strings.ToUpper(FileSuffix), envFile); ok {
+			setMappingValue(mapping, key, valueNode(t, value))
+		}
+		return
+	}
+
+	if !set && strings.HasSuffix(key, "password") && t.Kind() == reflect.String {
+		if value, ok := o.promptPassword(fieldName); ok {
+			setMappingValue(mapping, key, valueNode(t, value))
+		}
+	}
+}
+
+// readValueFile reads a value referenced by a FileSuffix field or environment variable
+func (o *overrider) readValueFile(source string, field string, path string) (string, bool) {
+	if !o.problems.checkFileExists(source, field, path) {
+		return "", false
+	}
+	b, err := utils.ReadFile(path)
+	if err != nil {
+		o.problems.add(source, field, err)
+		return "", false
+	}
+	return strings.TrimRight(string(b), "\r\n"), true
+}
+
+// promptPassword asks for the field if stdin is a terminal, remembering the answer for the rest of the process
+func (o *overrider) promptPassword(fieldName string) (string, bool) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", false
+	}
+
+	promptedPasswordsMutex.Lock()
+	defer promptedPasswordsMutex.Unlock()
+	promptKey := o.file + ":" + fieldName
+	if password, ok := promptedPasswords[promptKey]; ok {
+		return password, true
+	}
+	password, err := prompt.Stdin.PromptPassword(fmt.Sprintf("%s of %s: ", fieldName, o.file))
+	if err != nil {
+		o.problems.add(o.file, fieldName, fmt.Errorf("could not be read from the terminal: %w", err))
+		return "", false
+	}
+	promptedPasswords[promptKey] = password
+	return password, true
+}
+
+// valueNode returns the node to decode a value from a file or the environment into a field of type t
+func valueNode(t reflect.Type, value string) *yaml.Node {
+	if t.Kind() == reflect.Slice && !t.Implements(textUnmarshalerType) {
+		sequence := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				sequence.Content = append(sequence.Content, valueNode(t.Elem(), item))
+			}
+		}
+		return sequence
+	}
+	if t.Kind() == reflect.String {
+		// Keep values like "123" or "null" as they are
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+	// Resolved from the value, like in the config file
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// mappingValue returns the value of the key in a mapping node, or nil if it is not there
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of the key in a mapping node, adding the key if it is not there
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
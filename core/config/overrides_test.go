@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testOverridesConfig struct {
+	EthRpcUrl    string        `yaml:"eth_rpc_url"`
+	EthRpcUrls   []string      `yaml:"eth_rpc_urls"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Bls          struct {
+		PrivateKeyStorePassword string `yaml:"private_key_store_password"`
+	} `yaml:"bls"`
+	Operator struct {
+		MaxBatchSize  int64 `yaml:"max_batch_size"`
+		EnableMetrics bool  `yaml:"enable_metrics"`
+	} `yaml:"operator"`
+}
+
+func TestReadYamlFileOverrides(t *testing.T) {
+	passwordFilePath := writeTestFile(t, "password", "from-yaml-file\n")
+	configFilePath := writeTestFile(t, "config.yaml", `
+eth_rpc_url: http://from-yaml
+poll_interval: 4s
+bls:
+  private_key_store_password_file: `+passwordFilePath+`
+operator:
+  max_batch_size: 10
+`)
+	envUrlFilePath := writeTestFile(t, "eth_rpc_url", "http://from-env-file\n")
+	t.Setenv("ALIGNED_ETH_RPC_URL_FILE", envUrlFilePath)
+	t.Setenv("ALIGNED_ETH_RPC_URLS", "http://a, http://b")
+	t.Setenv("ALIGNED_OPERATOR_ENABLE_METRICS", "true")
+	t.Setenv("ALIGNED_OPERATOR_MAX_BATCH_SIZE", "20")
+
+	var config testOverridesConfig
+	if err := readYamlFile(configFilePath, &config); err != nil {
+		t.Fatalf("Could not read config: %v", err)
+	}
+
+	if config.EthRpcUrl != "http://from-env-file" {
+		t.Errorf("Expected the environment file to override the config file, got %s", config.EthRpcUrl)
+	}
+	if !reflect.DeepEqual(config.EthRpcUrls, []string{"http://a", "http://b"}) {
+		t.Errorf("Expected the list from the environment, got %v", config.EthRpcUrls)
+	}
+	if config.PollInterval != 4*time.Second {
+		t.Errorf("Expected the poll interval of the config file, got %v", config.PollInterval)
+	}
+	if config.Bls.PrivateKeyStorePassword != "from-yaml-file" {
+		t.Errorf("Expected the password from the referenced file, got %q", config.Bls.PrivateKeyStorePassword)
+	}
+	if config.Operator.MaxBatchSize != 20 || !config.Operator.EnableMetrics {
+		t.Errorf("Expected the operator fields from the environment, got %+v", config.Operator)
+	}
+}
+
+func TestReadYamlFileKeepsStringValuesFromEnvironment(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", "eth_rpc_url: http://from-yaml\n")
+	t.Setenv("ALIGNED_BLS_PRIVATE_KEY_STORE_PASSWORD", "0123")
+
+	var config testOverridesConfig
+	if err := readYamlFile(configFilePath, &config); err != nil {
+		t.Fatalf("Could not read config: %v", err)
+	}
+	if config.Bls.PrivateKeyStorePassword != "0123" {
+		t.Errorf("Expected the password to be kept as a string, got %q", config.Bls.PrivateKeyStorePassword)
+	}
+}
+
+func TestReadYamlFileOverrideProblems(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", `
+eth_rpc_url: http://from-yaml
+eth_rpc_url_file: ./eth_rpc_url
+bls:
+  private_key_store_password_file: ./missing-password
+`)
+	t.Setenv("ALIGNED_OPERATOR_MAX_BATCH_SIZE", "20")
+	t.Setenv("ALIGNED_OPERATOR_MAX_BATCH_SIZE_FILE", "./max_batch_size")
+
+	var config testOverridesConfig
+	fields := fieldsWithProblems(t, readYamlFile(configFilePath, &config))
+	expected := map[string]error{
+		"eth_rpc_url_file":                     ErrInvalidField,
+		"bls.private_key_store_password_file":  ErrFileNotFound,
+		"ALIGNED_OPERATOR_MAX_BATCH_SIZE_FILE": ErrInvalidField,
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d problems, got %v", len(expected), fields)
+	}
+	for field, expectedErr := range expected {
+		if !errors.Is(fields[field], expectedErr) {
+			t.Errorf("Expected %s to fail with %v, got %v", field, expectedErr, fields[field])
+		}
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if name := EnvVarName("bls", "private_key_store_password"); name != "ALIGNED_BLS_PRIVATE_KEY_STORE_PASSWORD" {
+		t.Errorf("Unexpected environment variable name %s", name)
+	}
+}
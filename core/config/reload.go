@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ErrNotReloadable is the problem of a changed field that can only be changed restarting the service
+var ErrNotReloadable = errors.New("can not be reloaded, restart to change it")
+
+// Time to wait for more changes of the config file before reloading it, as editors may write it in several steps
+const reloadDebounce = 500 * time.Millisecond
+
+// FieldChange is a field that changed between two reads of a config file
+type FieldChange struct {
+	// Key of the field in the file, such as "aggregator.gas_base_bump_percentage"
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+}
+
+// reloader re-reads a config file, checking only the reloadable fields changed since it was last read
+type reloader struct {
+	configFilePath string
+	reloadable     map[string]bool
+	// Pointer to the sections of the config file used by the service, as last read
+	current interface{}
+}
+
+func newReloader(configFilePath string, current interface{}, reloadable []string) (*reloader, error) {
+	if err := readYamlFile(configFilePath, current); err != nil {
+		return nil, err
+	}
+	reloadableFields := make(map[string]bool, len(reloadable))
+	for _, field := range reloadable {
+		reloadableFields[field] = true
+	}
+	return &reloader{configFilePath: configFilePath, reloadable: reloadableFields, current: current}, nil
+}
+
+// reload parses the config file into next, a pointer to a new value of the type of current, and calls apply
+// if the changed fields are reloadable and pass check. Once applied, next is the config the following reloads are
+// compared with. Changes of fields that are not reloadable and invalid values are returned in a ValidationError
+func (r *reloader) reload(next interface{}, check func(change FieldChange, problems *validationErrors), apply func() error) ([]FieldChange, error) {
+	if err := readYamlFile(r.configFilePath, next); err != nil {
+		return nil, err
+	}
+
+	problems := validationErrors{}
+	changes := diffFields(reflect.ValueOf(r.current).Elem(), reflect.ValueOf(next).Elem(), nil)
+	for _, change := range changes {
+		if !r.reloadable[change.Field] {
+			problems.add(r.configFilePath, change.Field, ErrNotReloadable)
+			continue
+		}
+		check(change, &problems)
+	}
+	if !problems.empty() {
+		return changes, problems.err()
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	if err := apply(); err != nil {
+		return changes, err
+	}
+	r.current = next
+	return changes, nil
+}
+
+// diffFields lists the fields with a yaml key that differ between two values of the same struct type
+func diffFields(old reflect.Value, next reflect.Value, path []string) []FieldChange {
+	var changes []FieldChange
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if len(tag) > 1 && tag[1] == "inline" && field.Type.Kind() == reflect.Struct {
+			changes = append(changes, diffFields(old.Field(i), next.Field(i), path)...)
+			continue
+		}
+		key := tag[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), key)
+
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			changes = append(changes, diffFields(old.Field(i), next.Field(i), fieldPath)...)
+			continue
+		}
+		if !reflect.DeepEqual(old.Field(i).Interface(), next.Field(i).Interface()) {
+			changes = append(changes, FieldChange{
+				Field: strings.Join(fieldPath, "."),
+				Old:   old.Field(i).Interface(),
+				New:   next.Field(i).Interface(),
+			})
+		}
+	}
+	return changes
+}
+
+// checkReloadedField adds a problem if a reloaded environment or duration is invalid
+func (r *reloader) checkReloadedField(change FieldChange, problems *validationErrors) {
+	switch value := change.New.(type) {
+	case sdklogging.LogLevel:
+		if value != sdklogging.Development && value != sdklogging.Production {
+			problems.add(r.configFilePath, change.Field, fmt.Errorf("%w: %q, expected %q or %q", ErrInvalidField, value, sdklogging.Development, sdklogging.Production))
+		}
+	case time.Duration:
+		if value <= 0 {
+			problems.add(r.configFilePath, change.Field, fmt.Errorf("%w: %v, expected a positive duration", ErrInvalidField, value))
+		}
+	}
+}
+
+// WatchReloads calls reload when the process receives a SIGHUP, and when watchFile is set, when the config file changes.
+// It returns once the watches are set up, calling reload from another goroutine
+func WatchReloads(configFilePath string, watchFile bool, logger sdklogging.Logger, reload func()) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	var fileEvents <-chan fsnotify.Event
+	var fileErrors <-chan error
+	if watchFile {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("could not watch config file: %w", err)
+		}
+		// The directory is watched, as editors may replace the file instead of writing it
+		if err := watcher.Add(filepath.Dir(configFilePath)); err != nil {
+			watcher.Close()
+			return fmt.Errorf("could not watch config file: %w", err)
+		}
+		fileEvents = watcher.Events
+		fileErrors = watcher.Errors
+	}
+
+	configFileName := filepath.Clean(configFilePath)
+	go func() {
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-signals:
+				logger.Info("Received SIGHUP, reloading config file", "path", configFilePath)
+				reload()
+			case event := <-fileEvents:
+				if filepath.Clean(event.Name) != configFileName || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				debounce = time.After(reloadDebounce)
+			case <-debounce:
+				debounce = nil
+				logger.Info("Config file changed, reloading it", "path", configFilePath)
+				reload()
+			case err := <-fileErrors:
+				logger.Warn("Error watching config file", "err", err)
+			}
+		}
+	}()
+	return nil
+}
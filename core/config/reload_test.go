@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const testReloadConfig = `
+environment: production
+eth_rpc_url: http://localhost:8545
+aggregator:
+  server_ip_port_address: localhost:8090
+  time_to_wait_before_bump: 72s
+  gas_base_bump_percentage: 25
+`
+
+func rewriteTestFile(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Could not write %s: %v", path, err)
+	}
+}
+
+func TestAggregatorReloaderAppliesReloadableFields(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", testReloadConfig)
+	reloader, err := NewAggregatorReloader(configFilePath)
+	if err != nil {
+		t.Fatalf("Could not create reloader: %v", err)
+	}
+
+	rewriteTestFile(t, configFilePath, `
+environment: development
+eth_rpc_url: http://localhost:8545
+aggregator:
+  server_ip_port_address: localhost:8090
+  time_to_wait_before_bump: 36s
+  gas_base_bump_percentage: 25
+`)
+	var appliedEnvironment sdklogging.LogLevel
+	var applied *AggregatorSettings
+	changes, err := reloader.Reload(func(environment sdklogging.LogLevel, settings *AggregatorSettings) error {
+		appliedEnvironment, applied = environment, settings
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Could not reload: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("Expected 2 changes, got %v", changes)
+	}
+	if appliedEnvironment != sdklogging.Development || applied.TimeToWaitBeforeBump != 36*time.Second || applied.GasBaseBumpPercentage != 25 {
+		t.Errorf("Unexpected applied config %s %+v", appliedEnvironment, applied)
+	}
+
+	// The applied config is the one the next reload is compared with
+	changes, err = reloader.Reload(func(sdklogging.LogLevel, *AggregatorSettings) error {
+		t.Errorf("Expected nothing to be applied without changes")
+		return nil
+	})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v, %v", changes, err)
+	}
+}
+
+func TestAggregatorReloaderRejectsInvalidChanges(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", testReloadConfig)
+	reloader, err := NewAggregatorReloader(configFilePath)
+	if err != nil {
+		t.Fatalf("Could not create reloader: %v", err)
+	}
+
+	rewriteTestFile(t, configFilePath, `
+environment: staging
+eth_rpc_url: http://localhost:8546
+aggregator:
+  server_ip_port_address: localhost:8090
+  time_to_wait_before_bump: 0s
+  gas_base_bump_percentage: 30
+`)
+	_, err = reloader.Reload(func(sdklogging.LogLevel, *AggregatorSettings) error {
+		t.Errorf("Expected nothing to be applied with invalid changes")
+		return nil
+	})
+	fields := fieldsWithProblems(t, err)
+	expected := map[string]error{
+		"environment":                         ErrInvalidField,
+		"eth_rpc_url":                         ErrNotReloadable,
+		"aggregator.time_to_wait_before_bump": ErrInvalidField,
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d problems, got %v", len(expected), err)
+	}
+	for field, expectedErr := range expected {
+		if !errors.Is(fields[field], expectedErr) {
+			t.Errorf("Expected %s to fail with %v, got %v", field, expectedErr, fields[field])
+		}
+	}
+}
+
+func TestOperatorReloaderKeepsConfigWhenApplyFails(t *testing.T) {
+	configFilePath := writeTestFile(t, "config.yaml", `
+environment: production
+operator:
+  aggregator_rpc_server_ip_port_address: localhost:8090
+  max_batch_size: 1000
+`)
+	reloader, err := NewOperatorReloader(configFilePath)
+	if err != nil {
+		t.Fatalf("Could not create reloader: %v", err)
+	}
+
+	rewriteTestFile(t, configFilePath, `
+environment: production
+operator:
+  aggregator_rpc_server_ip_port_address: localhost:9090
+  max_batch_size: 1000
+`)
+	applyErr := errors.New("aggregator unreachable")
+	if _, err := reloader.Reload(func(sdklogging.LogLevel, *OperatorSettings) error { return applyErr }); !errors.Is(err, applyErr) {
+		t.Fatalf("Expected the apply error, got %v", err)
+	}
+
+	// The change is retried on the next reload, as it was not applied
+	changes, err := reloader.Reload(func(_ sdklogging.LogLevel, settings *OperatorSettings) error {
+		if settings.AggregatorServerIpPortAddress != "localhost:9090" || settings.MaxBatchSize != 1000 {
+			t.Errorf("Unexpected settings %+v", settings)
+		}
+		return nil
+	})
+	if err != nil || len(changes) != 1 || changes[0].Field != "operator.aggregator_rpc_server_ip_port_address" {
+		t.Errorf("Expected the aggregator address change, got %v, %v", changes, err)
+	}
+}
+
+func TestPrepareEnvironmentSwapsTheLoggerOnlyOnceApplied(t *testing.T) {
+	logger, err := NewLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived := logger.With("component", "test").(*ReloadableLogger)
+	initial := logger.logger.Load()
+
+	if _, err := derived.PrepareEnvironment("staging"); err == nil {
+		t.Errorf("Expected an unknown environment to be rejected")
+	}
+	setEnvironment, err := derived.PrepareEnvironment(sdklogging.Production)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.logger.Load() != initial {
+		t.Errorf("Expected the logger not to be swapped before the environment is applied")
+	}
+	setEnvironment()
+	if logger.logger.Load() == initial {
+		t.Errorf("Expected the logger to be swapped once the environment is applied")
+	}
+}
@@ -1,11 +1,5 @@
 package config
 
-import (
-	"errors"
-	"log"
-	"os"
-)
-
 type TaskSenderConfig struct {
 	BaseConfig  *BaseConfig
 	EcdsaConfig *EcdsaConfig
@@ -15,23 +9,27 @@ type TaskSenderConfigFromYaml struct {
 	EcdsaConfigFromYaml EcdsaConfigFromYaml `yaml:"ecdsa"`
 }
 
-func NewTaskSenderConfig(configFilePath string) *TaskSenderConfig {
-	if _, err := os.Stat(configFilePath); errors.Is(err, os.ErrNotExist) {
-		log.Fatal("Setup config file does not exist")
+func NewTaskSenderConfig(configFilePath string) (*TaskSenderConfig, error) {
+	problems := validationErrors{}
+
+	ecdsaKeyPair, err := readEcdsaKey(configFilePath)
+	if err != nil {
+		problems.addErr(err)
 	}
 
-	baseConfig := NewBaseConfig(configFilePath)
-	if baseConfig == nil {
-		log.Fatal("Error reading base config: ")
+	baseConfig, err := newBaseConfig(configFilePath, &problems)
+	if err != nil {
+		return nil, err
 	}
 
-	ecdsaConfig := NewEcdsaConfig(configFilePath, baseConfig.ChainId)
-	if ecdsaConfig == nil {
-		log.Fatal("Error reading ecdsa config: ")
+	ecdsaConfig, err := newEcdsaConfig(ecdsaKeyPair, baseConfig.ChainId)
+	if err != nil {
+		baseConfig.Close()
+		return nil, err
 	}
 
 	return &TaskSenderConfig{
 		BaseConfig:  baseConfig,
 		EcdsaConfig: ecdsaConfig,
-	}
+	}, nil
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// StaticConfig is the part of a config file the validate commands check against the network.
+// It is loaded without dialing the endpoints
+type StaticConfig struct {
+	EthRpcUrls                   []string
+	EthWsUrls                    []string
+	AlignedLayerDeploymentConfig *AlignedLayerDeploymentConfig
+	EigenLayerDeploymentConfig   *EigenLayerDeploymentConfig
+}
+
+// CheckOperatorConfig runs every check of NewOperatorConfig that does not need the network, and decrypts the
+// ecdsa key store the register and staking commands sign with. The problems found are returned in a ValidationError
+func CheckOperatorConfig(configFilePath string) (*StaticConfig, error) {
+	problems := validationErrors{}
+	operatorConfigFromYaml, _ := checkOperatorConfig(configFilePath, &problems)
+	if operatorConfigFromYaml == nil {
+		return nil, problems.err()
+	}
+
+	ecdsaKeyPair, err := readEcdsaKey(configFilePath)
+	if err != nil {
+		problems.addErr(err)
+	} else if keyAddress := crypto.PubkeyToAddress(ecdsaKeyPair.PublicKey); operatorConfigFromYaml.Operator.Address != (common.Address{}) && keyAddress != operatorConfigFromYaml.Operator.Address {
+		problems.add(configFilePath, "operator.address", fmt.Errorf("%w: %s, but the ecdsa key is for %s", ErrInvalidField, operatorConfigFromYaml.Operator.Address, keyAddress))
+	}
+	return newStaticConfig(configFilePath, &problems)
+}
+
+// CheckAggregatorConfig runs every check of NewAggregatorConfig that does not need the network.
+// The problems found are returned in a ValidationError
+func CheckAggregatorConfig(configFilePath string) (*StaticConfig, error) {
+	problems := validationErrors{}
+	if aggregatorConfigFromYaml, _, _ := checkAggregatorConfig(configFilePath, &problems); aggregatorConfigFromYaml == nil {
+		return nil, problems.err()
+	}
+	return newStaticConfig(configFilePath, &problems)
+}
+
+func newStaticConfig(configFilePath string, problems *validationErrors) (*StaticConfig, error) {
+	static := checkBaseConfig(configFilePath, problems)
+	if !problems.empty() {
+		return nil, problems.err()
+	}
+	return &StaticConfig{
+		EthRpcUrls:                   static.ethRpcUrls,
+		EthWsUrls:                    static.ethWsUrls,
+		AlignedLayerDeploymentConfig: static.alignedLayerDeploymentConfig,
+		EigenLayerDeploymentConfig:   static.eigenLayerDeploymentConfig,
+	}, nil
+}
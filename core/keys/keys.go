@@ -0,0 +1,211 @@
+// Package keys creates and reads the BLS and ECDSA key stores of the operator, in the formats read by
+// bls.ReadPrivateKeyFromFile and ecdsa.ReadKey of the eigensdk, the same ones the EigenLayer CLI writes
+package keys
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+type KeyType string
+
+const (
+	KeyTypeBls   KeyType = "bls"
+	KeyTypeEcdsa KeyType = "ecdsa"
+)
+
+// ErrKeyStoreExists is returned instead of overwriting a key store
+var ErrKeyStoreExists = errors.New("key store already exists")
+
+// Scrypt parameters of the key stores written, lowered by the tests
+var (
+	scryptN = keystore.StandardScryptN
+	scryptP = keystore.StandardScryptP
+)
+
+func ParseKeyType(keyType string) (KeyType, error) {
+	switch KeyType(keyType) {
+	case KeyTypeBls, KeyTypeEcdsa:
+		return KeyType(keyType), nil
+	}
+	return "", fmt.Errorf("unknown key type %q, expected %q or %q", keyType, KeyTypeBls, KeyTypeEcdsa)
+}
+
+// Key is a decrypted private key of either type
+type Key struct {
+	Type  KeyType
+	Bls   *bls.KeyPair
+	Ecdsa *ecdsa.PrivateKey
+}
+
+// Generate returns a new random key
+func Generate(keyType KeyType) (*Key, error) {
+	if keyType == KeyTypeBls {
+		keyPair, err := bls.GenRandomBlsKeys()
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Type: keyType, Bls: keyPair}, nil
+	}
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Key{Type: keyType, Ecdsa: privateKey}, nil
+}
+
+// ParsePrivateKey parses a private key as printed by PrivateKey: decimal or 0x prefixed hex for BLS,
+// hex with or without the 0x prefix for ECDSA
+func ParsePrivateKey(keyType KeyType, privateKey string) (*Key, error) {
+	privateKey = strings.TrimSpace(privateKey)
+	if keyType == KeyTypeBls {
+		element, err := new(fr.Element).SetString(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bls private key: %w", err)
+		}
+		if element.IsZero() {
+			return nil, fmt.Errorf("invalid bls private key: it is zero")
+		}
+		return &Key{Type: keyType, Bls: bls.NewKeyPair(element)}, nil
+	}
+	ecdsaKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ecdsa private key: %w", err)
+	}
+	return &Key{Type: keyType, Ecdsa: ecdsaKey}, nil
+}
+
+// ReadKeyStore decrypts a key store file
+func ReadKeyStore(keyType KeyType, path string, password string) (*Key, error) {
+	if keyType == KeyTypeBls {
+		keyPair, err := bls.ReadPrivateKeyFromFile(path, password)
+		if err != nil {
+			return nil, fmt.Errorf("could not read bls key store %s: %w", path, err)
+		}
+		return &Key{Type: keyType, Bls: keyPair}, nil
+	}
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(b, password)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ecdsa key store %s: %w", path, err)
+	}
+	return &Key{Type: keyType, Ecdsa: key.PrivateKey}, nil
+}
+
+// PrivateKey returns the private key in the format ParsePrivateKey reads: decimal for BLS, as the EigenLayer CLI
+// prints it, and hex without prefix for ECDSA
+func (k *Key) PrivateKey() string {
+	if k.Type == KeyTypeBls {
+		return k.Bls.PrivKey.String()
+	}
+	return common.Bytes2Hex(crypto.FromECDSA(k.Ecdsa))
+}
+
+// WriteKeyStore encrypts the key with the password into a new file, which must not exist
+func (k *Key) WriteKeyStore(path string, password string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s", ErrKeyStoreExists, path)
+	}
+	return k.writeKeyStore(path, password)
+}
+
+// ChangePassword re-encrypts a key store with a new password, replacing the file once the new one is written
+func ChangePassword(keyType KeyType, path string, oldPassword string, newPassword string) error {
+	key, err := ReadKeyStore(keyType, path, oldPassword)
+	if err != nil {
+		return err
+	}
+	return key.writeKeyStore(path, newPassword)
+}
+
+// blsKeyStore is the format of bls.ReadPrivateKeyFromFile, with the G1 public key in the clear
+type blsKeyStore struct {
+	PubKey string              `json:"pubKey"`
+	Crypto keystore.CryptoJSON `json:"crypto"`
+}
+
+func (k *Key) writeKeyStore(path string, password string) error {
+	var data []byte
+	var err error
+	if k.Type == KeyTypeBls {
+		privateKey := k.Bls.PrivKey.Bytes()
+		cryptoJson, encryptErr := keystore.EncryptDataV3(privateKey[:], []byte(password), scryptN, scryptP)
+		if encryptErr != nil {
+			return encryptErr
+		}
+		data, err = json.Marshal(blsKeyStore{PubKey: k.Bls.PubKey.String(), Crypto: cryptoJson})
+	} else {
+		id, uuidErr := uuid.NewRandom()
+		if uuidErr != nil {
+			return uuidErr
+		}
+		key := &keystore.Key{Id: id, Address: crypto.PubkeyToAddress(k.Ecdsa.PublicKey), PrivateKey: k.Ecdsa}
+		data, err = keystore.EncryptKey(key, password, scryptN, scryptP)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, data)
+}
+
+// writeFileAtomically writes the file readable only by its owner, through a temporary file renamed over it,
+// so a key store is never left half written
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// PublicKeys are the public parts of a key: the address for ECDSA, the G1 and G2 points and operator id for BLS
+type PublicKeys struct {
+	Address    *common.Address
+	G1         *bls.G1Point
+	G2         *bls.G2Point
+	OperatorId *eigentypes.OperatorId
+}
+
+func (k *Key) PublicKeys() *PublicKeys {
+	if k.Type == KeyTypeBls {
+		operatorId := eigentypes.OperatorIdFromKeyPair(k.Bls)
+		return &PublicKeys{G1: k.Bls.GetPubKeyG1(), G2: k.Bls.GetPubKeyG2(), OperatorId: &operatorId}
+	}
+	address := crypto.PubkeyToAddress(k.Ecdsa.PublicKey)
+	return &PublicKeys{Address: &address}
+}
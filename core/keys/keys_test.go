@@ -0,0 +1,110 @@
+package keys
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+func init() {
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+}
+
+func TestKeyStoreRoundTrip(t *testing.T) {
+	for _, keyType := range []KeyType{KeyTypeBls, KeyTypeEcdsa} {
+		key, err := Generate(keyType)
+		if err != nil {
+			t.Fatalf("Could not generate %s key: %v", keyType, err)
+		}
+		path := filepath.Join(t.TempDir(), "keys", string(keyType)+".key.json")
+		if err := key.WriteKeyStore(path, "secret"); err != nil {
+			t.Fatalf("Could not write %s key store: %v", keyType, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected the %s key store to be readable only by its owner, got %v, %v", keyType, info.Mode(), err)
+		}
+
+		read, err := ReadKeyStore(keyType, path, "secret")
+		if err != nil {
+			t.Fatalf("Could not read %s key store: %v", keyType, err)
+		}
+		if read.PrivateKey() != key.PrivateKey() {
+			t.Errorf("Expected the %s key read to be the one written", keyType)
+		}
+		if _, err := ReadKeyStore(keyType, path, "wrong"); err == nil {
+			t.Errorf("Expected a wrong password to fail for the %s key store", keyType)
+		}
+		if err := key.WriteKeyStore(path, "secret"); !errors.Is(err, ErrKeyStoreExists) {
+			t.Errorf("Expected the %s key store not to be overwritten, got %v", keyType, err)
+		}
+	}
+}
+
+func TestImportedKeysMatchTheirPublicKeys(t *testing.T) {
+	// Anvil's first account
+	ecdsaKey, err := ParsePrivateKey(KeyTypeEcdsa, "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatalf("Could not parse ecdsa key: %v", err)
+	}
+	if address := ecdsaKey.PublicKeys().Address.Hex(); address != "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266" {
+		t.Errorf("Unexpected address %s", address)
+	}
+	path := filepath.Join(t.TempDir(), "ecdsa.key.json")
+	if err := ecdsaKey.WriteKeyStore(path, ""); err != nil {
+		t.Fatalf("Could not write ecdsa key store: %v", err)
+	}
+	// Readable by the sdk, as the config loader does
+	if _, err := sdkecdsa.ReadKey(path, ""); err != nil {
+		t.Errorf("Expected the sdk to read the key store: %v", err)
+	}
+
+	blsKey, err := ParsePrivateKey(KeyTypeBls, "12248929636257230549931416853095037629726205319386239410403476017439825112537")
+	if err != nil {
+		t.Fatalf("Could not parse bls key: %v", err)
+	}
+	publicKeys := blsKey.PublicKeys()
+	if ok, err := publicKeys.G1.VerifyEquivalence(publicKeys.G2); err != nil || !ok {
+		t.Errorf("Expected the G1 and G2 public keys to match, got %v, %v", ok, err)
+	}
+	if reparsed, err := ParsePrivateKey(KeyTypeBls, blsKey.PrivateKey()); err != nil || reparsed.PrivateKey() != blsKey.PrivateKey() {
+		t.Errorf("Expected the printed bls key to be parsed back, got %v", err)
+	}
+
+	if _, err := ParsePrivateKey(KeyTypeBls, "0"); err == nil {
+		t.Errorf("Expected a zero bls key to fail")
+	}
+	if _, err := ParsePrivateKey(KeyTypeEcdsa, "0x12"); err == nil {
+		t.Errorf("Expected a short ecdsa key to fail")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	key, err := Generate(KeyTypeBls)
+	if err != nil {
+		t.Fatalf("Could not generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "bls.key.json")
+	if err := key.WriteKeyStore(path, "old"); err != nil {
+		t.Fatalf("Could not write key store: %v", err)
+	}
+
+	if err := ChangePassword(KeyTypeBls, path, "wrong", "new"); err == nil {
+		t.Errorf("Expected the wrong current password to fail")
+	}
+	if err := ChangePassword(KeyTypeBls, path, "old", "new"); err != nil {
+		t.Fatalf("Could not change password: %v", err)
+	}
+	read, err := ReadKeyStore(KeyTypeBls, path, "new")
+	if err != nil || read.PrivateKey() != key.PrivateKey() {
+		t.Errorf("Expected the key to be readable with the new password, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left, got %v", entries)
+	}
+}
@@ -0,0 +1,233 @@
+// Package registration builds the registration of an operator in steps, so its ECDSA key can stay on an
+// air-gapped host: a Payload is prepared with the chain state, signed offline, then broadcast by any host.
+// The registry coordinator registers the sender of the transaction, so the payload carries the transaction of
+// the operator address along with its signature of the AVS directory digest
+package registration
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultGasLimit covers the registration of the BLS public key and of the operator in every registry
+const DefaultGasLimit = 1_000_000
+
+var (
+	ErrNotSigned      = errors.New("payload is not signed")
+	ErrExpired        = errors.New("registration signature expired")
+	ErrDigestMismatch = errors.New("digest does not match the payload")
+)
+
+// EIP-712 type hashes of the AVS directory of EigenLayer
+var (
+	domainTypeHash                  = crypto.Keccak256Hash([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)"))
+	domainNameHash                  = crypto.Keccak256Hash([]byte("EigenLayer"))
+	operatorAVSRegistrationTypeHash = crypto.Keccak256Hash([]byte("OperatorAVSRegistration(address operator,address avs,bytes32 salt,uint256 expiry)"))
+)
+
+// Payload is everything needed to register an operator. The fields up to GasFeeCap are set when preparing,
+// Signature and Transaction when signing
+type Payload struct {
+	ChainId             uint64         `json:"chain_id"`
+	RegistryCoordinator common.Address `json:"registry_coordinator"`
+	AvsDirectory        common.Address `json:"avs_directory"`
+	ServiceManager      common.Address `json:"service_manager"`
+	Operator            common.Address `json:"operator"`
+	QuorumNumbers       hexutil.Bytes  `json:"quorum_numbers"`
+	Socket              string         `json:"socket"`
+	// Signed by the BLS key when preparing, it does not expire
+	PubkeyRegistration regcoord.IBLSApkRegistryPubkeyRegistrationParams `json:"pubkey_registration"`
+	Salt               common.Hash                                      `json:"salt"`
+	// Unix time after which the AVS directory rejects the signature
+	Expiry uint64 `json:"expiry"`
+	// EIP-712 digest of the registration in the AVS directory, signed by the operator
+	Digest common.Hash `json:"digest"`
+
+	// Fields of the transaction the operator address sends
+	Nonce     uint64   `json:"nonce"`
+	GasLimit  uint64   `json:"gas_limit"`
+	GasTipCap *big.Int `json:"gas_tip_cap"`
+	GasFeeCap *big.Int `json:"gas_fee_cap"`
+
+	Signature   hexutil.Bytes `json:"signature,omitempty"`
+	Transaction hexutil.Bytes `json:"transaction,omitempty"`
+}
+
+// OperatorAVSRegistrationDigest is the digest AVSDirectory.calculateOperatorAVSRegistrationDigestHash returns
+func OperatorAVSRegistrationDigest(chainId uint64, avsDirectory common.Address, operator common.Address, avs common.Address, salt common.Hash, expiry uint64) common.Hash {
+	domainSeparator := crypto.Keccak256(
+		domainTypeHash.Bytes(),
+		domainNameHash.Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(chainId)),
+		common.LeftPadBytes(avsDirectory.Bytes(), 32),
+	)
+	structHash := crypto.Keccak256(
+		operatorAVSRegistrationTypeHash.Bytes(),
+		common.LeftPadBytes(operator.Bytes(), 32),
+		common.LeftPadBytes(avs.Bytes(), 32),
+		salt.Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(expiry)),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+// ExpiryTime is the time after which the registration signature is rejected
+func (p *Payload) ExpiryTime() time.Time {
+	return time.Unix(int64(p.Expiry), 0).UTC()
+}
+
+// MaxCost is the most the operator address pays in wei for the transaction
+func (p *Payload) MaxCost() *big.Int {
+	return new(big.Int).Mul(p.GasFeeCap, new(big.Int).SetUint64(p.GasLimit))
+}
+
+// CheckDigest recomputes the digest from the fields of the payload, so the signer knows what it signs without
+// reaching the chain
+func (p *Payload) CheckDigest() error {
+	digest := OperatorAVSRegistrationDigest(p.ChainId, p.AvsDirectory, p.Operator, p.ServiceManager, p.Salt, p.Expiry)
+	if digest != p.Digest {
+		return fmt.Errorf("%w: expected %s, the payload has %s", ErrDigestMismatch, digest, p.Digest)
+	}
+	return nil
+}
+
+// Sign signs the digest and the registration transaction with the ECDSA key of the operator
+func (p *Payload) Sign(key *ecdsa.PrivateKey, now time.Time) error {
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	if address != p.Operator {
+		return fmt.Errorf("the key is for %s, but the payload registers %s", address, p.Operator)
+	}
+	if !now.Before(p.ExpiryTime()) {
+		return fmt.Errorf("%w at %s, prepare the registration again", ErrExpired, p.ExpiryTime())
+	}
+	if err := p.CheckDigest(); err != nil {
+		return err
+	}
+
+	signature, err := crypto.Sign(p.Digest.Bytes(), key)
+	if err != nil {
+		return err
+	}
+	// The AVS directory expects v to be 27 or 28
+	signature[64] += 27
+
+	data, err := p.calldata(signature)
+	if err != nil {
+		return err
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(new(big.Int).SetUint64(p.ChainId)), &types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(p.ChainId),
+		Nonce:     p.Nonce,
+		GasTipCap: p.GasTipCap,
+		GasFeeCap: p.GasFeeCap,
+		Gas:       p.GasLimit,
+		To:        &p.RegistryCoordinator,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	p.Signature = signature
+	p.Transaction = encoded
+	return nil
+}
+
+// SignedTransaction decodes the signed transaction, and checks it is the operator's registration of the payload
+func (p *Payload) SignedTransaction() (*types.Transaction, error) {
+	if len(p.Transaction) == 0 || len(p.Signature) == 0 {
+		return nil, ErrNotSigned
+	}
+	if err := p.CheckDigest(); err != nil {
+		return nil, err
+	}
+	if len(p.Signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("signature has %d bytes, expected %d", len(p.Signature), crypto.SignatureLength)
+	}
+	signature := bytes.Clone(p.Signature)
+	signature[64] -= 27
+	pubKey, err := crypto.SigToPub(p.Digest.Bytes(), signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != p.Operator {
+		return nil, fmt.Errorf("digest is signed by %s, not by the operator %s", signer, p.Operator)
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(p.Transaction); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(new(big.Int).SetUint64(p.ChainId)), tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction signature: %w", err)
+	}
+	if sender != p.Operator {
+		return nil, fmt.Errorf("transaction is sent by %s, not by the operator %s", sender, p.Operator)
+	}
+	data, err := p.calldata(p.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if tx.To() == nil || *tx.To() != p.RegistryCoordinator || tx.Nonce() != p.Nonce || !bytes.Equal(tx.Data(), data) {
+		return nil, errors.New("transaction does not match the payload")
+	}
+	return tx, nil
+}
+
+// calldata is the call to RegistryCoordinator.registerOperator with the given operator signature
+func (p *Payload) calldata(signature []byte) ([]byte, error) {
+	registryCoordinatorAbi, err := regcoord.ContractRegistryCoordinatorMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return registryCoordinatorAbi.Pack(
+		"registerOperator",
+		[]byte(p.QuorumNumbers),
+		p.Socket,
+		p.PubkeyRegistration,
+		regcoord.ISignatureUtilsSignatureWithSaltAndExpiry{
+			Signature: signature,
+			Salt:      p.Salt,
+			Expiry:    new(big.Int).SetUint64(p.Expiry),
+		},
+	)
+}
+
+func ReadPayload(path string) (*Payload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid registration payload %s: %w", path, err)
+	}
+	return &payload, nil
+}
+
+// Write saves the payload as indented json, to be read by the next step on another host
+func (p *Payload) Write(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
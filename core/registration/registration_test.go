@@ -0,0 +1,141 @@
+package registration
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var now = time.Unix(1_700_000_000, 0)
+
+func testPayload(t *testing.T, operator common.Address) *Payload {
+	t.Helper()
+	payload := &Payload{
+		ChainId:             17000,
+		RegistryCoordinator: common.HexToAddress("0x1"),
+		AvsDirectory:        common.HexToAddress("0x2"),
+		ServiceManager:      common.HexToAddress("0x3"),
+		Operator:            operator,
+		QuorumNumbers:       []byte{0},
+		Socket:              "Not Needed",
+		PubkeyRegistration: regcoord.IBLSApkRegistryPubkeyRegistrationParams{
+			PubkeyRegistrationSignature: regcoord.BN254G1Point{X: big.NewInt(1), Y: big.NewInt(2)},
+			PubkeyG1:                    regcoord.BN254G1Point{X: big.NewInt(1), Y: big.NewInt(2)},
+			PubkeyG2: regcoord.BN254G2Point{
+				X: [2]*big.Int{big.NewInt(1), big.NewInt(2)},
+				Y: [2]*big.Int{big.NewInt(3), big.NewInt(4)},
+			},
+		},
+		Salt:      common.HexToHash("0xabcd"),
+		Expiry:    uint64(now.Add(time.Hour).Unix()),
+		Nonce:     3,
+		GasLimit:  DefaultGasLimit,
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+	}
+	payload.Digest = OperatorAVSRegistrationDigest(payload.ChainId, payload.AvsDirectory, payload.Operator, payload.ServiceManager, payload.Salt, payload.Expiry)
+	return payload
+}
+
+func TestSignedPayloadRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPayload(t, crypto.PubkeyToAddress(key.PublicKey))
+	if _, err := payload.SignedTransaction(); !errors.Is(err, ErrNotSigned) {
+		t.Fatalf("expected ErrNotSigned before signing, got %v", err)
+	}
+	if err := payload.Sign(key, now); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "registration.json")
+	if err := payload.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadPayload(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := read.SignedTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *tx.To() != payload.RegistryCoordinator || tx.Nonce() != payload.Nonce || tx.Gas() != payload.GasLimit {
+		t.Errorf("transaction %+v does not match the payload", tx)
+	}
+	if read.MaxCost().Cmp(new(big.Int).Mul(big.NewInt(30_000_000_000), big.NewInt(DefaultGasLimit))) != 0 {
+		t.Errorf("unexpected max cost %s", read.MaxCost())
+	}
+}
+
+func TestSignRejects(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := crypto.PubkeyToAddress(key.PublicKey)
+
+	t.Run("key of another address", func(t *testing.T) {
+		payload := testPayload(t, common.HexToAddress("0x4"))
+		if err := payload.Sign(key, now); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		payload := testPayload(t, operator)
+		if err := payload.Sign(key, now.Add(2*time.Hour)); !errors.Is(err, ErrExpired) {
+			t.Errorf("expected ErrExpired, got %v", err)
+		}
+	})
+
+	t.Run("digest of other fields", func(t *testing.T) {
+		payload := testPayload(t, operator)
+		payload.ServiceManager = common.HexToAddress("0x5")
+		if err := payload.Sign(key, now); !errors.Is(err, ErrDigestMismatch) {
+			t.Errorf("expected ErrDigestMismatch, got %v", err)
+		}
+	})
+}
+
+func TestSignedTransactionRejectsChangedPayload(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPayload(t, crypto.PubkeyToAddress(key.PublicKey))
+	if err := payload.Sign(key, now); err != nil {
+		t.Fatal(err)
+	}
+
+	payload.Socket = "changed"
+	if _, err := payload.SignedTransaction(); err == nil {
+		t.Error("expected an error for a transaction that does not match the payload")
+	}
+
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload = testPayload(t, crypto.PubkeyToAddress(key.PublicKey))
+	if err := payload.Sign(key, now); err != nil {
+		t.Fatal(err)
+	}
+	signature, err := crypto.Sign(payload.Digest.Bytes(), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[64] += 27
+	payload.Signature = signature
+	if _, err := payload.SignedTransaction(); err == nil {
+		t.Error("expected an error for a digest signed by another key")
+	}
+}
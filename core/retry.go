@@ -1,7 +1,9 @@
 package retry
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -31,6 +33,7 @@ const (
 	NetworkRandomizationFactor float64 = 0                // Randomization (Jitter) factor used to map retry interval to a range of values around the computed interval. In precise terms (random value in range [1 - randomizationfactor, 1 + randomizationfactor]). NOTE: This is set to 0 as we do not use jitter in Aligned.
 	NetworkMultiplier          float64 = 2                // Multiplier factor computed exponential retry interval is scaled by.
 	NetworkNumRetries          uint64  = 3                // Total number of retries attempted.
+	NetworkAttemptTimeout              = 30 * time.Second // Maximum time a single attempt may take before it is cancelled and retried.
 
 	// Retry Params for Sending Tx to Chain
 	ChainInitialInterval = 12 * time.Second // Initial delay for retry interval for contract calls. Corresponds to 1 ethereum block.
@@ -53,6 +56,47 @@ type RetryParams struct {
 	RandomizationFactor float64
 	Multiplier          float64
 	NumRetries          uint64
+	AttemptTimeout      time.Duration // Maximum time a single attempt may take. `0` corresponds to no limit on the time of an attempt.
+	// Called after every attempt, with the error returned by the attempt or nil if it succeeded.
+	OnAttempt func(name string, attempt uint64, duration time.Duration, err error)
+	// Called when the call fails for good, after a permanent error, when the retries are exhausted or when the context is done.
+	OnGiveUp func(name string, attempts uint64, err error)
+	// Budget the retries are taken from, the call gives up with ErrRetryBudgetExhausted once it is empty. If nil, retries are not limited by a budget.
+	Budget *RetryBudget
+}
+
+// Observer is notified of the attempts and give ups of every named retried call, and of the state changes
+// of every circuit breaker, for example to export them as metrics.
+type Observer interface {
+	ObserveRetryAttempt(name string, attempt uint64, duration time.Duration, err error)
+	ObserveRetryGiveUp(name string, attempts uint64, err error)
+	ObserveCircuitState(name string, state CircuitState)
+}
+
+var (
+	observerMutex sync.RWMutex
+	observer      Observer
+)
+
+// SetObserver sets the Observer notified of every named retried call and circuit breaker of the process,
+// and reports to it the current state of every breaker. A nil Observer disables the notifications.
+func SetObserver(o Observer) {
+	observerMutex.Lock()
+	observer = o
+	observerMutex.Unlock()
+
+	if o == nil {
+		return
+	}
+	for _, breaker := range registeredBreakers() {
+		o.ObserveCircuitState(breaker.Name(), breaker.State())
+	}
+}
+
+func getObserver() Observer {
+	observerMutex.RLock()
+	defer observerMutex.RUnlock()
+	return observer
 }
 
 func NetworkRetryParams() *RetryParams {
@@ -63,6 +107,8 @@ func NetworkRetryParams() *RetryParams {
 		RandomizationFactor: NetworkRandomizationFactor,
 		Multiplier:          NetworkMultiplier,
 		NumRetries:          NetworkNumRetries,
+		AttemptTimeout:      NetworkAttemptTimeout,
+		Budget:              DefaultRetryBudget,
 	}
 }
 
@@ -74,6 +120,22 @@ func SendToChainRetryParams() *RetryParams {
 		RandomizationFactor: NetworkRandomizationFactor,
 		Multiplier:          NetworkMultiplier,
 		NumRetries:          NetworkNumRetries,
+		AttemptTimeout:      NetworkAttemptTimeout,
+		Budget:              DefaultRetryBudget,
+	}
+}
+
+// ResubscribeRetryParams returns the retry parameters to renew a failed event subscription.
+// It retries until the subscription succeeds, as events would be missed without it
+func ResubscribeRetryParams() *RetryParams {
+	return &RetryParams{
+		InitialInterval:     NetworkInitialInterval,
+		MaxInterval:         NetworkMaxInterval,
+		MaxElapsedTime:      NetworkMaxElapsedTime,
+		RandomizationFactor: NetworkRandomizationFactor,
+		Multiplier:          NetworkMultiplier,
+		NumRetries:          0,
+		AttemptTimeout:      NetworkAttemptTimeout,
 	}
 }
 
@@ -99,6 +161,7 @@ func WaitForTxRetryParams(maxElapsedTime time.Duration) *RetryParams {
 		RandomizationFactor: NetworkRandomizationFactor,
 		Multiplier:          NetworkMultiplier,
 		NumRetries:          WaitForTxNumRetries,
+		AttemptTimeout:      NetworkAttemptTimeout,
 	}
 }
 
@@ -161,90 +224,113 @@ Reference: https://github.com/cenkalti/backoff/blob/v4/exponential.go#L9
 
 // Same as Retry only that the functionToRetry can return a value upon correct execution
 func RetryWithData[T any](functionToRetry func() (T, error), config *RetryParams) (T, error) {
+	return RetryWithDataContext(context.Background(), "", func(context.Context) (T, error) { return functionToRetry() }, config)
+}
+
+// Retries a given function in an exponential backoff manner.
+// It will retry calling the function while it returns an error, until the max retries.
+// If maxTries == 0 then the retry function will run indefinitely until success
+// from the configuration are reached, or until a `PermanentError` is returned.
+// The function to be retried should return `PermanentError` when the condition for stop retrying
+// is met.
+func Retry(functionToRetry func() error, config *RetryParams) error {
+	return RetryContext(context.Background(), "", func(context.Context) error { return functionToRetry() }, config)
+}
+
+// RetryContext is the context-aware version of Retry, see RetryWithDataContext.
+func RetryContext(ctx context.Context, name string, functionToRetry func(ctx context.Context) error, config *RetryParams) error {
+	_, err := RetryWithDataContext(ctx, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, functionToRetry(ctx)
+	}, config)
+	return err
+}
+
+// RetryWithDataContext retries functionToRetry like RetryWithData, until ctx is done.
+// Every attempt receives a context derived from ctx, that expires after config.AttemptTimeout if it is set,
+// so a hanging call is retried instead of blocking the whole retry loop.
+// The attempts and the give up of the call are reported to config.OnAttempt and config.OnGiveUp and to the Observer
+// set with SetObserver, labeled with name. Calls without a name are not reported to the Observer.
+func RetryWithDataContext[T any](ctx context.Context, name string, functionToRetry func(ctx context.Context) (T, error), config *RetryParams) (T, error) {
+	var attempt uint64
 	f := func() (T, error) {
 		var (
 			val T
 			err error
 		)
+		attempt++
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if config.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, config.AttemptTimeout)
+		}
+		defer cancel()
+
+		if config.Budget != nil {
+			if attempt == 1 {
+				config.Budget.Deposit()
+			} else if !config.Budget.Withdraw() {
+				config.attempted(name, attempt, 0, ErrRetryBudgetExhausted)
+				return val, backoff.Permanent(ErrRetryBudgetExhausted)
+			}
+		}
+
+		start := time.Now()
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
 					if panic_err, ok := r.(error); ok {
 						err = panic_err
 					} else {
-						err = fmt.Errorf("RetryWithData panicked: %v", panic_err)
+						err = fmt.Errorf("Retry panicked: %v", r)
 					}
 				}
 			}()
-			val, err = functionToRetry()
-			// Convert the returned `PermanentError` (our implementation) to `backoff.PermanentError`.
-			//This exits the retry loop in the `backoff` library.
-			if perm, ok := err.(PermanentError); err != nil && ok {
-				err = backoff.Permanent(perm.Inner)
-			}
+			val, err = functionToRetry(attemptCtx)
 		}()
+		config.attempted(name, attempt, time.Since(start), err)
+
+		// Convert the returned `PermanentError` (our implementation) to `backoff.PermanentError`.
+		//This exits the retry loop in the `backoff` library.
+		if perm, ok := err.(PermanentError); err != nil && ok {
+			err = backoff.Permanent(perm.Inner)
+		}
 		return val, err
 	}
 
+	val, err := backoff.RetryWithData(f, backoff.WithContext(config.backOff(), ctx))
+	if err != nil {
+		config.gaveUp(name, attempt, err)
+	}
+	return val, err
+}
+
+func (config *RetryParams) backOff() backoff.BackOff {
 	initialRetryOption := backoff.WithInitialInterval(config.InitialInterval)
 	multiplierOption := backoff.WithMultiplier(config.Multiplier)
 	maxIntervalOption := backoff.WithMaxInterval(config.MaxInterval)
 	maxElapsedTimeOption := backoff.WithMaxElapsedTime(config.MaxElapsedTime)
 	randomOption := backoff.WithRandomizationFactor(config.RandomizationFactor)
 	expBackoff := backoff.NewExponentialBackOff(randomOption, multiplierOption, initialRetryOption, maxIntervalOption, maxElapsedTimeOption)
-	var maxRetriesBackoff backoff.BackOff
 
 	if config.NumRetries > 0 {
-		maxRetriesBackoff = backoff.WithMaxRetries(expBackoff, config.NumRetries)
-	} else {
-		maxRetriesBackoff = expBackoff
+		return backoff.WithMaxRetries(expBackoff, config.NumRetries)
 	}
-
-	return backoff.RetryWithData(f, maxRetriesBackoff)
+	return expBackoff
 }
 
-// Retries a given function in an exponential backoff manner.
-// It will retry calling the function while it returns an error, until the max retries.
-// If maxTries == 0 then the retry function will run indefinitely until success
-// from the configuration are reached, or until a `PermanentError` is returned.
-// The function to be retried should return `PermanentError` when the condition for stop retrying
-// is met.
-func Retry(functionToRetry func() error, config *RetryParams) error {
-	f := func() error {
-		var err error
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					if panic_err, ok := r.(error); ok {
-						err = panic_err
-					} else {
-						err = fmt.Errorf("Retry panicked: %v", panic_err)
-					}
-				}
-			}()
-			err = functionToRetry()
-			// Convert the returned `PermanentError` (our implementation) to a `backoff.PermanentError`.
-			//This exits the retry loop in the `backoff` library.
-			if perm, ok := err.(PermanentError); err != nil && ok {
-				err = backoff.Permanent(perm.Inner)
-			}
-		}()
-		return err
+func (config *RetryParams) attempted(name string, attempt uint64, duration time.Duration, err error) {
+	if config.OnAttempt != nil {
+		config.OnAttempt(name, attempt, duration, err)
 	}
-
-	initialRetryOption := backoff.WithInitialInterval(config.InitialInterval)
-	multiplierOption := backoff.WithMultiplier(config.Multiplier)
-	maxIntervalOption := backoff.WithMaxInterval(config.MaxInterval)
-	maxElapsedTimeOption := backoff.WithMaxElapsedTime(config.MaxElapsedTime)
-	randomOption := backoff.WithRandomizationFactor(config.RandomizationFactor)
-	expBackoff := backoff.NewExponentialBackOff(randomOption, multiplierOption, initialRetryOption, maxIntervalOption, maxElapsedTimeOption)
-	var maxRetriesBackoff backoff.BackOff
-
-	if config.NumRetries > 0 {
-		maxRetriesBackoff = backoff.WithMaxRetries(expBackoff, config.NumRetries)
-	} else {
-		maxRetriesBackoff = expBackoff
+	if o := getObserver(); o != nil && name != "" {
+		o.ObserveRetryAttempt(name, attempt, duration, err)
 	}
+}
 
-	return backoff.Retry(f, maxRetriesBackoff)
+func (config *RetryParams) gaveUp(name string, attempts uint64, err error) {
+	if config.OnGiveUp != nil {
+		config.OnGiveUp(name, attempts, err)
+	}
+	if o := getObserver(); o != nil && name != "" {
+		o.ObserveRetryGiveUp(name, attempts, err)
+	}
 }
@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+A RetryBudget caps the ratio of retries to requests of the whole process, so many goroutines retrying on their own
+do not multiply the load on endpoints that are already struggling.
+
+Every first attempt deposits `Ratio` tokens and every retry takes one. Tokens are also refilled at `MinRetriesPerSecond`,
+so a process with few requests can still retry. When there are no tokens left the call gives up right away with
+ErrRetryBudgetExhausted, instead of waiting for the next backoff intervals and using up its remaining retries.
+*/
+const (
+	RetryBudgetRatio                       = 0.2 // Retries allowed per request.
+	RetryBudgetMinRetriesPerSecond         = 1   // Retries allowed per second regardless of the number of requests.
+	RetryBudgetMaxTokens           float64 = 100 // Maximum retries that can be saved up, and the tokens of a new budget.
+)
+
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// Budget shared by the network retries of the process, see NetworkRetryParams
+var DefaultRetryBudget = NewRetryBudget(RetryBudgetRatio, RetryBudgetMinRetriesPerSecond, RetryBudgetMaxTokens)
+
+type RetryBudget struct {
+	ratio               float64
+	minRetriesPerSecond float64
+	maxTokens           float64
+
+	mutex      sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+}
+
+func NewRetryBudget(ratio float64, minRetriesPerSecond float64, maxTokens float64) *RetryBudget {
+	return &RetryBudget{
+		ratio:               ratio,
+		minRetriesPerSecond: minRetriesPerSecond,
+		maxTokens:           maxTokens,
+		tokens:              maxTokens,
+		lastRefill:          time.Now(),
+	}
+}
+
+// Deposit records a first attempt
+func (b *RetryBudget) Deposit() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refill()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// Withdraw takes a token for a retry, and returns false if there are none left
+func (b *RetryBudget) Withdraw() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill must be called with the mutex held
+func (b *RetryBudget) refill() {
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.lastRefill).Seconds()*b.minRetriesPerSecond, b.maxTokens)
+	b.lastRefill = now
+}
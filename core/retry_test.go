@@ -1,8 +1,11 @@
 package retry_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	retry "github.com/yetanotherco/aligned_layer/core"
 )
@@ -53,3 +56,118 @@ func TestRetry(t *testing.T) {
 		t.Errorf("Retry error!: %s", err)
 	}
 }
+
+func TestRetryWithDataContextTimesOutAttempts(t *testing.T) {
+	attempts := 0
+	function := func(ctx context.Context) (uint64, error) {
+		attempts++
+		if attempts == 1 {
+			// The first attempt hangs until its context expires
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return DummyFunction(43)
+	}
+	config := &retry.RetryParams{
+		InitialInterval:     time.Millisecond,
+		MaxInterval:         time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          retry.NetworkMultiplier,
+		NumRetries:          retry.NetworkNumRetries,
+		AttemptTimeout:      10 * time.Millisecond,
+	}
+	x, err := retry.RetryWithDataContext(context.Background(), "test", function, config)
+	if err != nil {
+		t.Errorf("Retry error!: %s", err)
+	}
+	if x != 43 || attempts != 2 {
+		t.Errorf("Expected 43 after 2 attempts, got %d after %d attempts", x, attempts)
+	}
+}
+
+func TestRetryContextStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	function := func(ctx context.Context) error {
+		attempts++
+		cancel()
+		_, err := DummyFunction(41)
+		return err
+	}
+	config := &retry.RetryParams{
+		InitialInterval:     time.Minute,
+		MaxInterval:         time.Minute,
+		RandomizationFactor: 0,
+		Multiplier:          retry.NetworkMultiplier,
+		NumRetries:          retry.NetworkNumRetries,
+	}
+	err := retry.RetryContext(ctx, "test", function, config)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+type testObserver struct {
+	attempts []error
+	giveUps  uint64
+}
+
+func (o *testObserver) ObserveRetryAttempt(name string, attempt uint64, duration time.Duration, err error) {
+	o.attempts = append(o.attempts, err)
+}
+
+func (o *testObserver) ObserveRetryGiveUp(name string, attempts uint64, err error) {
+	o.giveUps = attempts
+}
+
+func (o *testObserver) ObserveCircuitState(name string, state retry.CircuitState) {}
+
+func TestRetryContextReportsAttemptsAndGiveUps(t *testing.T) {
+	observer := &testObserver{}
+	retry.SetObserver(observer)
+	defer retry.SetObserver(nil)
+
+	var hookAttempts, hookGiveUps uint64
+	config := &retry.RetryParams{
+		InitialInterval:     time.Millisecond,
+		MaxInterval:         time.Millisecond,
+		RandomizationFactor: 0,
+		Multiplier:          retry.NetworkMultiplier,
+		NumRetries:          retry.NetworkNumRetries,
+		OnAttempt: func(name string, attempt uint64, duration time.Duration, err error) {
+			hookAttempts = attempt
+		},
+		OnGiveUp: func(name string, attempts uint64, err error) {
+			hookGiveUps = attempts
+		},
+	}
+	function := func(ctx context.Context) error {
+		_, err := DummyFunction(41)
+		return err
+	}
+	err := retry.RetryContext(context.Background(), "test", function, config)
+	if err == nil {
+		t.Errorf("Expected transient error after running out of retries")
+	}
+	expectedAttempts := retry.NetworkNumRetries + 1
+	if uint64(len(observer.attempts)) != expectedAttempts || observer.giveUps != expectedAttempts {
+		t.Errorf("Expected %d attempts reported to the observer, got %d attempts and %d on give up", expectedAttempts, len(observer.attempts), observer.giveUps)
+	}
+	if hookAttempts != expectedAttempts || hookGiveUps != expectedAttempts {
+		t.Errorf("Expected %d attempts reported to the hooks, got %d attempts and %d on give up", expectedAttempts, hookAttempts, hookGiveUps)
+	}
+
+	// Permanent errors are given up on after the first attempt
+	observer.attempts = nil
+	function = func(ctx context.Context) error {
+		_, err := DummyFunction(42)
+		return err
+	}
+	err = retry.RetryContext(context.Background(), "test", function, config)
+	if err == nil || len(observer.attempts) != 1 || observer.giveUps != 1 {
+		t.Errorf("Expected permanent error after 1 attempt, got %v after %d attempts", err, len(observer.attempts))
+	}
+}
@@ -0,0 +1,133 @@
+package rpcpool
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The pool can be used anywhere a single eth client is expected, every call is routed through it
+var _ eth.HttpBackend = (*Pool)(nil)
+var _ eth.WsBackend = (*Pool)(nil)
+
+func (p *Pool) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*big.Int, error) {
+		return client.ChainID(ctx)
+	})
+}
+
+func (p *Pool) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func (p *Pool) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*types.Block, error) {
+		return client.BlockByNumber(ctx, number)
+	})
+}
+
+func (p *Pool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	})
+}
+
+func (p *Pool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*big.Int, error) {
+		return client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (p *Pool) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (p *Pool) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) ([]byte, error) {
+		return client.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (p *Pool) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) ([]byte, error) {
+		return client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (p *Pool) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) ([]byte, error) {
+		return client.PendingCodeAt(ctx, account)
+	})
+}
+
+func (p *Pool) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (p *Pool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+func (p *Pool) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+func (p *Pool) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (uint64, error) {
+		return client.EstimateGas(ctx, msg)
+	})
+}
+
+// SendTransaction sends the transaction to the endpoints in ranking order until one accepts it.
+// An endpoint that failed may still have broadcast the transaction, so the next ones rejecting it as already sent
+// count as a success
+func (p *Pool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempts := 0
+	_, err := call(ctx, p, func(client *eth.InstrumentedClient) (struct{}, error) {
+		attempts++
+		err := client.SendTransaction(ctx, tx)
+		if err != nil && attempts > 1 && alreadySent(err, func() bool {
+			_, _, err := client.TransactionByHash(ctx, tx.Hash())
+			return err == nil
+		}) {
+			return struct{}{}, nil
+		}
+		return struct{}{}, err
+	})
+	return err
+}
+
+func (p *Pool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+// SubscribeFilterLogs subscribes on the healthiest endpoint. The subscription stays on that endpoint,
+// so callers must subscribe again when it fails to move to a healthier one.
+func (p *Pool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (ethereum.Subscription, error) {
+		return client.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+// SubscribeNewHead subscribes on the healthiest endpoint, see SubscribeFilterLogs
+func (p *Pool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return call(ctx, p, func(client *eth.InstrumentedClient) (ethereum.Subscription, error) {
+		return client.SubscribeNewHead(ctx, ch)
+	})
+}
@@ -0,0 +1,712 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	DefaultLogPollInterval   = 4 * time.Second
+	DefaultLogPollBlockRange = 1000
+	// Blocks behind the head whose hashes are kept to detect reorgs, deeper reorgs are not seen
+	DefaultLogPollMaxReorgDepth = 64
+	// Logs a subscriber can be behind before its subscription fails with rpc.ErrSubscriptionQueueOverflow
+	maxQueuedLogs = 10_000
+)
+
+// Returned when subscribing to a closed LogPoller
+var ErrLogPollerClosed = errors.New("log poller is closed")
+
+// Backend is what event subscribers need from an eth client. It is implemented by the Pool,
+// which subscribes through its websocket endpoints, and by the LogPoller, which polls http endpoints
+type Backend interface {
+	eth.WsBackend
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+var _ Backend = (*Pool)(nil)
+var _ Backend = (*LogPoller)(nil)
+
+// logSource is what the LogPoller polls, the pool it is built with
+type logSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+type LogPollerParams struct {
+	PollInterval time.Duration
+	// Maximum number of blocks queried in a single eth_getLogs call
+	BlockRange uint64
+	// Blocks behind the head whose hashes are kept to detect reorgs
+	MaxReorgDepth uint64
+	// File where the last block whose logs were received is stored. If empty, polling starts from the latest block
+	// on every start
+	CursorFilePath string
+}
+
+// LogPoller serves log and new head subscriptions by polling eth_getLogs over the pool, for deployments
+// without websocket endpoints. Every other call goes straight to the pool.
+// All the subscriptions are served from a single cursor, the last polled block. Every subscription has its own queue,
+// so a subscriber that does not read does not hold back the others. The cursor file only moves past a block once
+// every subscriber received its logs, so the logs polled but not received when the process stops are delivered
+// again after a restart.
+// Reorgs are detected by comparing the hash of the last polled block with the chain on every poll. The logs of the
+// reorged blocks are then delivered again with Removed set, as websocket subscriptions do, and the new ones are polled.
+type LogPoller struct {
+	*Pool
+	source logSource
+	params LogPollerParams
+	logger sdklogging.Logger
+
+	// Cancelled by Close to stop polling
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stopped   chan struct{}
+	startOnce sync.Once
+
+	mutex         sync.Mutex
+	subscriptions map[*pollSubscription]struct{}
+	// Last polled block, valid once started is set
+	cursor  uint64
+	started bool
+	// Hashes of the polled blocks within MaxReorgDepth of the head, by block number
+	checkpoints map[uint64]common.Hash
+	// Logs polled within MaxReorgDepth of the head, delivered again as removed when their block is reorged
+	recentLogs []types.Log
+	lastHead   uint64
+
+	// Serializes the writes of the cursor file
+	persistMutex sync.Mutex
+	persisted    uint64
+}
+
+type logPollerCursor struct {
+	BlockNumber uint64      `json:"block_number"`
+	BlockHash   common.Hash `json:"block_hash"`
+}
+
+func NewLogPoller(pool *Pool, params LogPollerParams, logger sdklogging.Logger) (*LogPoller, error) {
+	return newLogPoller(pool, pool, params, logger)
+}
+
+func newLogPoller(pool *Pool, source logSource, params LogPollerParams, logger sdklogging.Logger) (*LogPoller, error) {
+	if params.PollInterval == 0 {
+		params.PollInterval = DefaultLogPollInterval
+	}
+	if params.BlockRange == 0 {
+		params.BlockRange = DefaultLogPollBlockRange
+	}
+	if params.MaxReorgDepth == 0 {
+		params.MaxReorgDepth = DefaultLogPollMaxReorgDepth
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := &LogPoller{
+		Pool:          pool,
+		source:        source,
+		params:        params,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+		stopped:       make(chan struct{}),
+		subscriptions: make(map[*pollSubscription]struct{}),
+		checkpoints:   make(map[uint64]common.Hash),
+	}
+
+	if params.CursorFilePath != "" {
+		file, err := os.ReadFile(params.CursorFilePath)
+		// If the file does not exist, it is created after the first poll
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			cancel()
+			return nil, err
+		}
+		if err == nil {
+			var cursor logPollerCursor
+			if err := json.Unmarshal(file, &cursor); err != nil {
+				cancel()
+				return nil, err
+			}
+			poller.cursor = cursor.BlockNumber
+			poller.persisted = cursor.BlockNumber
+			poller.started = true
+			if cursor.BlockHash != (common.Hash{}) {
+				poller.checkpoints[cursor.BlockNumber] = cursor.BlockHash
+			}
+			logger.Info("Resuming log polling", "fromBlock", cursor.BlockNumber+1)
+		}
+	}
+
+	return poller, nil
+}
+
+// Close stops polling and ends every subscription without an error, as their subscribers are stopping too.
+// It does not close the pool the poller goes through
+func (p *LogPoller) Close() {
+	p.cancel()
+	// If polling never started, there is nothing to wait for
+	p.startOnce.Do(func() { close(p.stopped) })
+	<-p.stopped
+
+	subscriptions, _ := p.snapshot()
+	for _, sub := range subscriptions {
+		sub.stop()
+	}
+}
+
+// SubscribeFilterLogs delivers the logs matching the query from the poller cursor on. The FromBlock and ToBlock
+// of the query are ignored
+func (p *LogPoller) SubscribeFilterLogs(_ context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return p.subscribe(&pollSubscription{query: query, logs: ch})
+}
+
+// SubscribeNewHead delivers the latest header every time the head advances between polls. A subscriber that
+// does not read only gets the latest one
+func (p *LogPoller) SubscribeNewHead(_ context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return p.subscribe(&pollSubscription{headers: ch})
+}
+
+// Cursor returns the last polled block
+func (p *LogPoller) Cursor() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.cursor
+}
+
+func (p *LogPoller) subscribe(sub *pollSubscription) (*pollSubscription, error) {
+	if p.ctx.Err() != nil {
+		return nil, ErrLogPollerClosed
+	}
+	sub.poller = p
+	sub.quit = make(chan struct{})
+	sub.err = make(chan error, 1)
+	sub.wake = make(chan struct{}, 1)
+
+	p.mutex.Lock()
+	sub.delivered = p.cursor
+	p.subscriptions[sub] = struct{}{}
+	p.mutex.Unlock()
+
+	go sub.deliver()
+	// The first poll happens after a poll interval, so subscriptions made right after this one are not behind it
+	p.startOnce.Do(func() { go p.run() })
+	return sub, nil
+}
+
+func (p *LogPoller) unsubscribe(sub *pollSubscription) {
+	p.mutex.Lock()
+	delete(p.subscriptions, sub)
+	p.mutex.Unlock()
+	// The cursor file may have been held back by this subscription
+	p.persistCursor()
+}
+
+func (p *LogPoller) run() {
+	defer close(p.stopped)
+	p.logger.Info("Polling logs", "pool", p.name, "interval", p.params.PollInterval, "blockRange", p.params.BlockRange)
+	ticker := time.NewTicker(p.params.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep polling while catching up, instead of waiting for the next tick
+			for p.ctx.Err() == nil && p.poll(p.ctx) {
+			}
+		}
+	}
+}
+
+// poll delivers the logs of the next block range and returns whether the cursor is still behind the latest block
+func (p *LogPoller) poll(ctx context.Context) bool {
+	latestBlock, err := p.source.BlockNumber(ctx)
+	if err != nil {
+		p.logger.Warn("Could not get latest block to poll logs", "err", err)
+		return false
+	}
+
+	subscriptions, cursor := p.snapshot()
+	if !p.isStarted() {
+		// Nothing persisted, start from the latest block
+		header, err := p.source.HeaderByNumber(ctx, new(big.Int).SetUint64(latestBlock))
+		if err != nil {
+			p.logger.Warn("Could not get latest header to start polling logs", "block", latestBlock, "err", err)
+			return false
+		}
+		p.mutex.Lock()
+		p.started = true
+		p.cursor = latestBlock
+		p.checkpoints[latestBlock] = header.Hash()
+		for sub := range p.subscriptions {
+			sub.setDelivered(latestBlock)
+		}
+		p.mutex.Unlock()
+		p.persistCursor()
+		return false
+	}
+
+	if latestBlock > p.lastHead {
+		p.deliverHead(ctx, subscriptions, latestBlock)
+	}
+
+	reorged, err := p.checkReorg(ctx, subscriptions, cursor)
+	if err != nil {
+		p.logger.Warn("Could not check the polled blocks are still in the chain", "block", cursor, "err", err)
+		return false
+	}
+	if reorged {
+		return true
+	}
+	if latestBlock <= cursor {
+		return false
+	}
+
+	toBlock := min(latestBlock, cursor+p.params.BlockRange)
+	// The hash of the last block is read before its logs, so a reorg while they are polled is seen on the next poll
+	header, err := p.source.HeaderByNumber(ctx, new(big.Int).SetUint64(toBlock))
+	if err != nil {
+		p.logger.Warn("Could not get header to poll logs", "block", toBlock, "err", err)
+		return false
+	}
+	logs, err := p.source.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(cursor + 1),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: queriedAddresses(subscriptions),
+	})
+	if err != nil {
+		p.logger.Warn("Could not poll logs", "fromBlock", cursor+1, "toBlock", toBlock, "err", err)
+		return false
+	}
+
+	for _, sub := range subscriptions {
+		sub.enqueue(logs, toBlock)
+	}
+
+	p.mutex.Lock()
+	p.cursor = toBlock
+	p.checkpoints[toBlock] = header.Hash()
+	p.recentLogs = append(p.recentLogs, logs...)
+	p.prune(latestBlock)
+	p.mutex.Unlock()
+	p.persistCursor()
+	return toBlock < latestBlock
+}
+
+// checkReorg compares the hash of the cursor block with the one it had when it was polled. On a reorg, the logs
+// polled after the last block still in the chain are delivered again as removed, and the cursor goes back to that
+// block so the logs of the new blocks are polled
+func (p *LogPoller) checkReorg(ctx context.Context, subscriptions []*pollSubscription, cursor uint64) (bool, error) {
+	p.mutex.Lock()
+	hash, ok := p.checkpoints[cursor]
+	checkpoints := make([]uint64, 0, len(p.checkpoints))
+	for number := range p.checkpoints {
+		if number < cursor {
+			checkpoints = append(checkpoints, number)
+		}
+	}
+	p.mutex.Unlock()
+	if !ok {
+		return false, nil
+	}
+	header, err := p.source.HeaderByNumber(ctx, new(big.Int).SetUint64(cursor))
+	if err != nil {
+		return false, err
+	}
+	if header.Hash() == hash {
+		return false, nil
+	}
+
+	// The blocks before a block still in the chain are too, so the fork is after the most recent one that is.
+	// If none is, the reorg is deeper than the kept hashes and the logs are polled again from MaxReorgDepth back
+	forkBlock := cursor - min(cursor, p.params.MaxReorgDepth)
+	slices.Sort(checkpoints)
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		header, err := p.source.HeaderByNumber(ctx, new(big.Int).SetUint64(checkpoints[i]))
+		if err != nil {
+			return false, err
+		}
+		p.mutex.Lock()
+		inChain := header.Hash() == p.checkpoints[checkpoints[i]]
+		p.mutex.Unlock()
+		if inChain {
+			forkBlock = checkpoints[i]
+			break
+		}
+	}
+
+	p.mutex.Lock()
+	var removed, kept []types.Log
+	for i := len(p.recentLogs) - 1; i >= 0; i-- {
+		log := p.recentLogs[i]
+		if log.BlockNumber <= forkBlock {
+			continue
+		}
+		log.Removed = true
+		removed = append(removed, log)
+	}
+	for _, log := range p.recentLogs {
+		if log.BlockNumber <= forkBlock {
+			kept = append(kept, log)
+		}
+	}
+	p.recentLogs = kept
+	for number := range p.checkpoints {
+		if number > forkBlock {
+			delete(p.checkpoints, number)
+		}
+	}
+	p.cursor = forkBlock
+	p.mutex.Unlock()
+
+	p.logger.Warn("Chain reorganized, delivering the logs of the reorged blocks as removed",
+		"fromBlock", forkBlock+1, "removedLogs", len(removed))
+	for _, sub := range subscriptions {
+		sub.enqueue(removed, forkBlock)
+	}
+	p.persistCursor()
+	return true, nil
+}
+
+// prune forgets the hashes and logs of the blocks too deep to be reorged. Must be called with the mutex held
+func (p *LogPoller) prune(latestBlock uint64) {
+	if latestBlock <= p.params.MaxReorgDepth {
+		return
+	}
+	oldest := latestBlock - p.params.MaxReorgDepth
+	for number := range p.checkpoints {
+		// The cursor is kept, it is the block reorgs are detected from
+		if number < oldest && number != p.cursor {
+			delete(p.checkpoints, number)
+		}
+	}
+	firstKept := 0
+	for firstKept < len(p.recentLogs) && p.recentLogs[firstKept].BlockNumber < oldest {
+		firstKept++
+	}
+	p.recentLogs = p.recentLogs[firstKept:]
+}
+
+func (p *LogPoller) deliverHead(ctx context.Context, subscriptions []*pollSubscription, latestBlock uint64) {
+	hasHeadSubscriptions := false
+	for _, sub := range subscriptions {
+		hasHeadSubscriptions = hasHeadSubscriptions || sub.headers != nil
+	}
+	if !hasHeadSubscriptions {
+		p.lastHead = latestBlock
+		return
+	}
+
+	header, err := p.source.HeaderByNumber(ctx, new(big.Int).SetUint64(latestBlock))
+	if err != nil {
+		p.logger.Warn("Could not get latest header", "block", latestBlock, "err", err)
+		return
+	}
+	for _, sub := range subscriptions {
+		sub.setHeader(header)
+	}
+	p.lastHead = latestBlock
+}
+
+func (p *LogPoller) isStarted() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.started
+}
+
+func (p *LogPoller) snapshot() ([]*pollSubscription, uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	subscriptions := make([]*pollSubscription, 0, len(p.subscriptions))
+	for sub := range p.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, p.cursor
+}
+
+// persistCursor writes the last block whose logs every log subscriber received to the cursor file
+func (p *LogPoller) persistCursor() {
+	if p.params.CursorFilePath == "" {
+		return
+	}
+	p.mutex.Lock()
+	if !p.started {
+		p.mutex.Unlock()
+		return
+	}
+	blockNumber := p.cursor
+	for sub := range p.subscriptions {
+		if sub.logs != nil {
+			blockNumber = min(blockNumber, sub.deliveredBlock())
+		}
+	}
+	blockHash := p.checkpoints[blockNumber]
+	p.mutex.Unlock()
+
+	p.persistMutex.Lock()
+	defer p.persistMutex.Unlock()
+	if blockNumber == p.persisted {
+		return
+	}
+	cursor, err := json.Marshal(logPollerCursor{BlockNumber: blockNumber, BlockHash: blockHash})
+	if err != nil {
+		p.logger.Error("Could not marshal log poller cursor", "err", err)
+		return
+	}
+	if err := writeCursorFile(p.params.CursorFilePath, cursor); err != nil {
+		p.logger.Error("Could not persist log poller cursor", "file", p.params.CursorFilePath, "err", err)
+		return
+	}
+	p.persisted = blockNumber
+}
+
+// writeCursorFile writes the file readable only by its owner, through a temporary file renamed over it,
+// so the cursor is never left half written
+func writeCursorFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// queriedAddresses returns the addresses of every subscription, or nil if any of them queries every address
+func queriedAddresses(subscriptions []*pollSubscription) []common.Address {
+	addresses := []common.Address{}
+	seen := make(map[common.Address]struct{})
+	for _, sub := range subscriptions {
+		if sub.logs == nil {
+			continue
+		}
+		if len(sub.query.Addresses) == 0 {
+			return nil
+		}
+		for _, address := range sub.query.Addresses {
+			if _, ok := seen[address]; !ok {
+				seen[address] = struct{}{}
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
+
+// matchesQuery applies the address and topic filters of the query to a log, the same way eth_getLogs does
+func matchesQuery(query ethereum.FilterQuery, log types.Log) bool {
+	if len(query.Addresses) > 0 {
+		found := false
+		for _, address := range query.Addresses {
+			found = found || address == log.Address
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for i, topics := range query.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) {
+			return false
+		}
+		found := false
+		for _, topic := range topics {
+			found = found || topic == log.Topics[i]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// pollBatch is the logs of a subscription polled up to a block
+type pollBatch struct {
+	logs    []types.Log
+	toBlock uint64
+}
+
+// pollSubscription is either a log or a new head subscription of the LogPoller. It is delivered to by its own
+// goroutine, from a queue the poller fills
+type pollSubscription struct {
+	poller  *LogPoller
+	query   ethereum.FilterQuery
+	logs    chan<- types.Log
+	headers chan<- *types.Header
+	quit    chan struct{}
+	err     chan error
+	once    sync.Once
+	// Signals the delivery goroutine there is something to deliver
+	wake chan struct{}
+
+	mutex      sync.Mutex
+	batches    []pollBatch
+	queuedLogs int
+	// Latest header not received yet
+	header *types.Header
+	// Last block whose logs were received
+	delivered uint64
+}
+
+// enqueue queues the logs matching the query for delivery. The subscription fails if the subscriber is too far behind
+func (s *pollSubscription) enqueue(logs []types.Log, toBlock uint64) {
+	if s.logs == nil {
+		return
+	}
+	var matching []types.Log
+	for _, log := range logs {
+		if matchesQuery(s.query, log) {
+			matching = append(matching, log)
+		}
+	}
+
+	s.mutex.Lock()
+	if s.queuedLogs+len(matching) > maxQueuedLogs {
+		s.mutex.Unlock()
+		s.fail(rpc.ErrSubscriptionQueueOverflow)
+		return
+	}
+	s.batches = append(s.batches, pollBatch{logs: matching, toBlock: toBlock})
+	s.queuedLogs += len(matching)
+	s.mutex.Unlock()
+	s.signal()
+}
+
+func (s *pollSubscription) setHeader(header *types.Header) {
+	if s.headers == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.header = header
+	s.mutex.Unlock()
+	s.signal()
+}
+
+func (s *pollSubscription) setDelivered(blockNumber uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.delivered = blockNumber
+}
+
+func (s *pollSubscription) deliveredBlock() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.delivered
+}
+
+func (s *pollSubscription) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *pollSubscription) deliver() {
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-s.wake:
+		}
+		for s.deliverNext() {
+		}
+	}
+}
+
+// deliverNext hands the latest header, or the next batch of logs, to the subscriber. It returns false once there is
+// nothing left to deliver or the subscription is over
+func (s *pollSubscription) deliverNext() bool {
+	s.mutex.Lock()
+	header := s.header
+	s.header = nil
+	var batch pollBatch
+	hasBatch := header == nil && len(s.batches) > 0
+	if hasBatch {
+		batch = s.batches[0]
+	}
+	s.mutex.Unlock()
+
+	switch {
+	case header != nil:
+		select {
+		case s.headers <- header:
+			return true
+		case <-s.quit:
+			return false
+		}
+	case hasBatch:
+		for _, log := range batch.logs {
+			select {
+			case s.logs <- log:
+			case <-s.quit:
+				return false
+			}
+		}
+		s.mutex.Lock()
+		s.batches = s.batches[1:]
+		s.queuedLogs -= len(batch.logs)
+		s.delivered = batch.toBlock
+		s.mutex.Unlock()
+		s.poller.persistCursor()
+		return true
+	}
+	return false
+}
+
+// Err returns rpc.ErrSubscriptionQueueOverflow if the subscriber falls too far behind, the subscription is then over.
+// Polling failures are not returned, they are retried on the next poll. The channel is closed on Unsubscribe
+func (s *pollSubscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *pollSubscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.poller.unsubscribe(s)
+		close(s.quit)
+		close(s.err)
+	})
+}
+
+func (s *pollSubscription) fail(err error) {
+	s.once.Do(func() {
+		s.poller.unsubscribe(s)
+		s.err <- err
+		close(s.quit)
+		close(s.err)
+	})
+}
+
+// stop ends the subscription without closing its error channel, for subscribers stopping with the poller
+func (s *pollSubscription) stop() {
+	s.once.Do(func() {
+		s.poller.unsubscribe(s)
+		close(s.quit)
+	})
+}
@@ -0,0 +1,310 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMatchesQuery(t *testing.T) {
+	address := common.HexToAddress("0x1")
+	topic := common.HexToHash("0xa")
+	log := types.Log{Address: address, Topics: []common.Hash{topic, common.HexToHash("0xb")}}
+
+	if !matchesQuery(ethereum.FilterQuery{}, log) {
+		t.Errorf("Expected empty query to match every log")
+	}
+	if !matchesQuery(ethereum.FilterQuery{Addresses: []common.Address{address}, Topics: [][]common.Hash{{topic}}}, log) {
+		t.Errorf("Expected query with the log address and topic to match")
+	}
+	if matchesQuery(ethereum.FilterQuery{Addresses: []common.Address{common.HexToAddress("0x2")}}, log) {
+		t.Errorf("Expected query with another address not to match")
+	}
+	if matchesQuery(ethereum.FilterQuery{Topics: [][]common.Hash{{}, {topic}}}, log) {
+		t.Errorf("Expected query with another second topic not to match")
+	}
+	if matchesQuery(ethereum.FilterQuery{Topics: [][]common.Hash{{}, {}, {topic}}}, log) {
+		t.Errorf("Expected query with more topics than the log not to match")
+	}
+}
+
+func TestQueriedAddresses(t *testing.T) {
+	first := common.HexToAddress("0x1")
+	second := common.HexToAddress("0x2")
+	subscriptions := []*pollSubscription{
+		{query: ethereum.FilterQuery{Addresses: []common.Address{first}}, logs: make(chan types.Log)},
+		{query: ethereum.FilterQuery{Addresses: []common.Address{second, first}}, logs: make(chan types.Log)},
+		{headers: make(chan *types.Header)},
+	}
+	if addresses := queriedAddresses(subscriptions); len(addresses) != 2 {
+		t.Errorf("Expected the two subscribed addresses, got %v", addresses)
+	}
+
+	subscriptions = append(subscriptions, &pollSubscription{logs: make(chan types.Log)})
+	if addresses := queriedAddresses(subscriptions); addresses != nil {
+		t.Errorf("Expected every address to be queried, got %v", addresses)
+	}
+}
+
+// fakeLogSource is a chain whose blocks can be replaced, changing their hash, to reorg their logs
+type fakeLogSource struct {
+	mutex       sync.Mutex
+	blockNumber uint64
+	// Number of times each block was replaced
+	forks map[uint64]uint64
+	logs  []types.Log
+}
+
+func newFakeLogSource(blockNumber uint64) *fakeLogSource {
+	return &fakeLogSource{blockNumber: blockNumber, forks: make(map[uint64]uint64)}
+}
+
+func (s *fakeLogSource) header(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Extra: binary.BigEndian.AppendUint64(nil, s.forks[number])}
+}
+
+// addLog mines a block with a log
+func (s *fakeLogSource) addLog(address common.Address) types.Log {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.blockNumber++
+	log := types.Log{Address: address, BlockNumber: s.blockNumber, BlockHash: s.header(s.blockNumber).Hash()}
+	s.logs = append(s.logs, log)
+	return log
+}
+
+// reorg replaces every block from fromBlock on, dropping their logs
+func (s *fakeLogSource) reorg(fromBlock uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for number := fromBlock; number <= s.blockNumber; number++ {
+		s.forks[number]++
+	}
+	kept := []types.Log{}
+	for _, log := range s.logs {
+		if log.BlockNumber < fromBlock {
+			kept = append(kept, log)
+		}
+	}
+	s.logs = kept
+}
+
+func (s *fakeLogSource) BlockNumber(ctx context.Context) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.blockNumber, nil
+}
+
+func (s *fakeLogSource) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if number.Uint64() > s.blockNumber {
+		return nil, ethereum.NotFound
+	}
+	return s.header(number.Uint64()), nil
+}
+
+func (s *fakeLogSource) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	logs := []types.Log{}
+	for _, log := range s.logs {
+		if log.BlockNumber >= query.FromBlock.Uint64() && log.BlockNumber <= query.ToBlock.Uint64() && matchesQuery(query, log) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func newTestLogPoller(t *testing.T, source logSource, cursorFilePath string) *LogPoller {
+	t.Helper()
+	poller, err := newLogPoller(newTestPool("a"), source, LogPollerParams{PollInterval: time.Hour, CursorFilePath: cursorFilePath}, logging.NewTextSLogger(io.Discard, nil))
+	if err != nil {
+		t.Fatalf("Could not create log poller: %v", err)
+	}
+	t.Cleanup(poller.Close)
+	return poller
+}
+
+func receiveLog(t *testing.T, logs <-chan types.Log) types.Log {
+	t.Helper()
+	select {
+	case log := <-logs:
+		return log
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a log to be delivered")
+		return types.Log{}
+	}
+}
+
+func readCursorFile(t *testing.T, path string) logPollerCursor {
+	t.Helper()
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read the cursor file: %v", err)
+	}
+	var cursor logPollerCursor
+	if err := json.Unmarshal(file, &cursor); err != nil {
+		t.Fatalf("Could not parse the cursor file: %v", err)
+	}
+	return cursor
+}
+
+func TestLogPollerDeliversWithoutWaitingForSlowSubscribers(t *testing.T) {
+	address := common.HexToAddress("0x1")
+	source := newFakeLogSource(10)
+	poller := newTestLogPoller(t, source, "")
+	ctx := context.Background()
+
+	// Nobody reads the logs of the first subscription
+	if _, err := poller.SubscribeFilterLogs(ctx, ethereum.FilterQuery{}, make(chan types.Log)); err != nil {
+		t.Fatal(err)
+	}
+	logs := make(chan types.Log)
+	if _, err := poller.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{address}}, logs); err != nil {
+		t.Fatal(err)
+	}
+	poller.poll(ctx)
+
+	first := source.addLog(address)
+	source.addLog(common.HexToAddress("0x2"))
+	second := source.addLog(address)
+	poller.poll(ctx)
+
+	if log := receiveLog(t, logs); log.BlockNumber != first.BlockNumber {
+		t.Errorf("Expected the log of block %d, got block %d", first.BlockNumber, log.BlockNumber)
+	}
+	if log := receiveLog(t, logs); log.BlockNumber != second.BlockNumber {
+		t.Errorf("Expected the log of block %d, got block %d", second.BlockNumber, log.BlockNumber)
+	}
+	if cursor := poller.Cursor(); cursor != 13 {
+		t.Errorf("Expected the cursor at the latest block, got %d", cursor)
+	}
+}
+
+func TestLogPollerPersistsCursorOnceLogsAreReceived(t *testing.T) {
+	cursorFile := filepath.Join(t.TempDir(), "cursor.json")
+	source := newFakeLogSource(10)
+	poller := newTestLogPoller(t, source, cursorFile)
+	ctx := context.Background()
+
+	logs := make(chan types.Log)
+	sub, err := poller.SubscribeFilterLogs(ctx, ethereum.FilterQuery{}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	poller.poll(ctx)
+	if cursor := readCursorFile(t, cursorFile); cursor.BlockNumber != 10 {
+		t.Fatalf("Expected polling to start from the latest block, got %d", cursor.BlockNumber)
+	}
+	info, err := os.Stat(cursorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected the cursor file to be readable only by its owner, got %o", perm)
+	}
+
+	source.addLog(common.HexToAddress("0x1"))
+	poller.poll(ctx)
+	if cursor := readCursorFile(t, cursorFile); cursor.BlockNumber != 10 {
+		t.Errorf("Expected the cursor not to move before the log is received, got %d", cursor.BlockNumber)
+	}
+
+	// A restart before the log is received polls it again
+	restarted, err := newLogPoller(newTestPool("a"), source, LogPollerParams{CursorFilePath: cursorFile}, logging.NewTextSLogger(io.Discard, nil))
+	if err != nil {
+		t.Fatalf("Could not create log poller: %v", err)
+	}
+	if cursor := restarted.Cursor(); cursor != 10 {
+		t.Errorf("Expected the restarted poller to resume before the log, got %d", cursor)
+	}
+
+	receiveLog(t, logs)
+	deadline := time.Now().Add(time.Second)
+	for readCursorFile(t, cursorFile).BlockNumber != 11 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if cursor := readCursorFile(t, cursorFile); cursor.BlockNumber != 11 || cursor.BlockHash != source.header(11).Hash() {
+		t.Errorf("Expected the cursor at the received block 11, got %+v", cursor)
+	}
+	sub.Unsubscribe()
+}
+
+func TestLogPollerDeliversRemovedLogsOnReorg(t *testing.T) {
+	address := common.HexToAddress("0x1")
+	source := newFakeLogSource(10)
+	poller := newTestLogPoller(t, source, "")
+	ctx := context.Background()
+
+	logs := make(chan types.Log, 10)
+	if _, err := poller.SubscribeFilterLogs(ctx, ethereum.FilterQuery{}, logs); err != nil {
+		t.Fatal(err)
+	}
+	poller.poll(ctx)
+	kept := source.addLog(address)
+	poller.poll(ctx)
+	reorged := source.addLog(address)
+	source.addLog(address)
+	poller.poll(ctx)
+	for i := 0; i < 3; i++ {
+		receiveLog(t, logs)
+	}
+
+	source.reorg(reorged.BlockNumber)
+	replacing := source.addLog(address)
+	for poller.poll(ctx) {
+	}
+
+	for _, blockNumber := range []uint64{reorged.BlockNumber + 1, reorged.BlockNumber} {
+		log := receiveLog(t, logs)
+		if !log.Removed || log.BlockNumber != blockNumber {
+			t.Errorf("Expected the log of block %d to be removed, got %+v", blockNumber, log)
+		}
+	}
+	log := receiveLog(t, logs)
+	if log.Removed || log.BlockNumber != replacing.BlockNumber || log.BlockHash != replacing.BlockHash {
+		t.Errorf("Expected the log of the new block %d, got %+v", replacing.BlockNumber, log)
+	}
+	select {
+	case log := <-logs:
+		t.Errorf("Expected the log of block %d to stay delivered, got %+v", kept.BlockNumber, log)
+	default:
+	}
+}
+
+func TestLogPollerClose(t *testing.T) {
+	poller := newTestLogPoller(t, newFakeLogSource(10), "")
+	sub, err := poller.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		poller.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Close to stop polling")
+	}
+
+	sub.Unsubscribe()
+	if _, err := poller.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log)); err != ErrLogPollerClosed {
+		t.Errorf("Expected subscribing to a closed poller to fail, got %v", err)
+	}
+}
@@ -0,0 +1,453 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	rpccalls "github.com/Layr-Labs/eigensdk-go/metrics/collectors/rpc_calls"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+const (
+	DefaultHealthCheckPeriod = 12 * time.Second
+	DefaultMaxHeadLag        = 5
+	// Consecutive failed health checks after which an endpoint is quarantined
+	MaxConsecutiveFailures = 3
+	// Weight of the last observation in the moving averages of latency and error rate
+	movingAverageWeight = 0.2
+	// Penalties added to the latency, in milliseconds, to compute the score of an endpoint
+	errorRatePenalty = 10
+	headLagPenalty   = 100
+)
+
+type PoolParams struct {
+	// Blocks an endpoint can be behind the highest head seen in the pool before it is quarantined
+	MaxHeadLag        uint64
+	HealthCheckPeriod time.Duration
+}
+
+// Endpoint is a single RPC provider of the pool along with its health stats
+type Endpoint struct {
+	Url    string
+	client *eth.InstrumentedClient
+	// Connection of the client, closed with the pool
+	ethClient *ethclient.Client
+	// Stops routing calls to the endpoint while it keeps failing, see retry.CircuitBreaker
+	breaker *retry.CircuitBreaker
+
+	mutex               sync.Mutex
+	latency             time.Duration
+	errorRate           float64
+	head                uint64
+	headLag             uint64
+	consecutiveFailures int
+	quarantined         bool
+}
+
+type EndpointStatus struct {
+	Url         string
+	Latency     time.Duration
+	ErrorRate   float64
+	Head        uint64
+	HeadLag     uint64
+	Quarantined bool
+	Circuit     retry.CircuitState
+}
+
+// recordCall updates the moving averages of latency and error rate with the result of a call.
+// Latency is only recorded for successful calls, as failed ones may return early or time out.
+func (e *Endpoint) recordCall(latency time.Duration, failed bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	errorSample := 0.0
+	if failed {
+		errorSample = 1.0
+	}
+	e.errorRate = e.errorRate*(1-movingAverageWeight) + errorSample*movingAverageWeight
+	if failed {
+		return
+	}
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(float64(e.latency)*(1-movingAverageWeight) + float64(latency)*movingAverageWeight)
+	}
+}
+
+// score returns how good the endpoint is, lower is better
+func (e *Endpoint) score() float64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	latencyMs := float64(e.latency) / float64(time.Millisecond)
+	return latencyMs*(1+errorRatePenalty*e.errorRate) + headLagPenalty*float64(e.headLag)
+}
+
+func (e *Endpoint) isQuarantined() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.quarantined
+}
+
+func (e *Endpoint) Status() EndpointStatus {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return EndpointStatus{
+		Url:         e.Url,
+		Latency:     e.latency,
+		ErrorRate:   e.errorRate,
+		Head:        e.head,
+		HeadLag:     e.headLag,
+		Quarantined: e.quarantined,
+		Circuit:     e.breaker.State(),
+	}
+}
+
+// Pool routes calls to the healthiest of a set of RPC endpoints, and falls back to the next ones on error.
+// Endpoints are scored by their latency, error rate and how far they are behind the highest head in the pool.
+// Endpoints that lag more than MaxHeadLag blocks, or that keep failing health checks, are quarantined:
+// they are only used when every other endpoint has failed.
+// Every endpoint has a circuit breaker, fed with the result of the calls routed to it. Endpoints with an open circuit
+// are skipped, so calls fall back to the next endpoint without waiting for the next health check.
+type Pool struct {
+	name      string
+	endpoints []*Endpoint
+	params    PoolParams
+	logger    sdklogging.Logger
+	// Position in the ranking of the endpoint that is tried first, see WithOffset
+	offset int
+
+	// Closed by Close to stop the health checks. Shared with the views of the pool
+	stop      chan struct{}
+	stopped   chan struct{}
+	closeOnce *sync.Once
+}
+
+// NewPool dials every url, runs a first health check and starts checking the endpoints health in the background.
+// Dialing and the first health check are bounded by the health check period, so a hung endpoint can not block
+// the caller
+func NewPool(name string, urls []string, params PoolParams, logger sdklogging.Logger) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no urls given for the %s pool", name)
+	}
+	if params.MaxHeadLag == 0 {
+		params.MaxHeadLag = DefaultMaxHeadLag
+	}
+	if params.HealthCheckPeriod == 0 {
+		params.HealthCheckPeriod = DefaultHealthCheckPeriod
+	}
+
+	endpoints := make([]*Endpoint, 0, len(urls))
+	for i, url := range urls {
+		rpcCallsCollector := rpccalls.NewCollector(fmt.Sprintf("%s%d", name, i), prometheus.NewRegistry())
+		ctx, cancel := context.WithTimeout(context.Background(), params.HealthCheckPeriod)
+		ethClient, client, err := dial(ctx, url, rpcCallsCollector)
+		cancel()
+		if err != nil {
+			closeEndpoints(endpoints)
+			return nil, fmt.Errorf("error initializing %s client %d: %w", name, i, err)
+		}
+		// The breaker is named like the rpc calls collector, as the url may contain an api key
+		breaker := retry.NewCircuitBreaker(fmt.Sprintf("%s%d", name, i), retry.BreakerParams{})
+		endpoints = append(endpoints, &Endpoint{Url: url, client: client, ethClient: ethClient, breaker: breaker})
+	}
+
+	pool := &Pool{
+		name:      name,
+		endpoints: endpoints,
+		params:    params,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), params.HealthCheckPeriod)
+	pool.checkHealth(ctx)
+	cancel()
+	go pool.runHealthChecks()
+
+	return pool, nil
+}
+
+// dial connects to the url. The eigensdk client asks the node for its version, without a timeout, when it is built,
+// so it is built in the background and given up on if ctx expires first
+func dial(ctx context.Context, url string, rpcCallsCollector *rpccalls.Collector) (*ethclient.Client, *eth.InstrumentedClient, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	ethClient := ethclient.NewClient(rpcClient)
+	built := make(chan *eth.InstrumentedClient, 1)
+	go func() {
+		built <- eth.NewInstrumentedClientFromClient(ethClient, rpcCallsCollector)
+	}()
+	select {
+	case client := <-built:
+		return ethClient, client, nil
+	case <-ctx.Done():
+		ethClient.Close()
+		return nil, nil, fmt.Errorf("endpoint did not answer: %w", ctx.Err())
+	}
+}
+
+// closeEndpoints unregisters the breakers of the endpoints and closes their connections
+func closeEndpoints(endpoints []*Endpoint) {
+	for _, endpoint := range endpoints {
+		endpoint.breaker.Close()
+		if endpoint.ethClient != nil {
+			endpoint.ethClient.Close()
+		}
+	}
+}
+
+// WithOffset returns a view of the pool that tries first the endpoint at position offset in the ranking.
+// It shares the endpoints and their stats with the original pool. It is used to keep redundant
+// subscriptions on different endpoints.
+func (p *Pool) WithOffset(offset int) *Pool {
+	return &Pool{
+		name:      p.name,
+		endpoints: p.endpoints,
+		params:    p.params,
+		logger:    p.logger,
+		offset:    offset,
+		stop:      p.stop,
+		stopped:   p.stopped,
+		closeOnce: p.closeOnce,
+	}
+}
+
+// Close stops the health checks in the background and closes the connections of the endpoints, for the pool and
+// all its views. The pool must not be called afterwards
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		<-p.stopped
+		closeEndpoints(p.endpoints)
+	})
+	<-p.stopped
+}
+
+// Urls returns the url of every endpoint, in configuration order
+func (p *Pool) Urls() []string {
+	urls := make([]string, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		urls = append(urls, endpoint.Url)
+	}
+	return urls
+}
+
+// Status returns the health stats of every endpoint, in configuration order
+func (p *Pool) Status() []EndpointStatus {
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		statuses = append(statuses, endpoint.Status())
+	}
+	return statuses
+}
+
+// ranked returns the endpoints in the order they should be tried: healthy ones first, ordered by score,
+// then the quarantined ones. The list is rotated by the pool offset.
+func (p *Pool) ranked() []*Endpoint {
+	type scoredEndpoint struct {
+		endpoint    *Endpoint
+		score       float64
+		quarantined bool
+	}
+	scored := make([]scoredEndpoint, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		scored = append(scored, scoredEndpoint{endpoint, endpoint.score(), endpoint.isQuarantined()})
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].quarantined != scored[j].quarantined {
+			return !scored[i].quarantined
+		}
+		return scored[i].score < scored[j].score
+	})
+
+	ranked := make([]*Endpoint, 0, len(scored))
+	for i := range scored {
+		ranked = append(ranked, scored[(i+p.offset)%len(scored)].endpoint)
+	}
+	return ranked
+}
+
+func (p *Pool) runHealthChecks() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(p.params.HealthCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.params.HealthCheckPeriod)
+			p.checkHealth(ctx)
+			cancel()
+		}
+	}
+}
+
+// checkHealth gets the head of every endpoint and quarantines the ones that lag behind or keep failing
+func (p *Pool) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func(endpoint *Endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			head, err := endpoint.client.BlockNumber(ctx)
+			endpoint.recordCall(time.Since(start), err != nil)
+
+			endpoint.mutex.Lock()
+			defer endpoint.mutex.Unlock()
+			if err != nil {
+				endpoint.consecutiveFailures++
+				return
+			}
+			endpoint.consecutiveFailures = 0
+			endpoint.head = head
+		}(endpoint)
+	}
+	wg.Wait()
+
+	p.updateQuarantine()
+}
+
+// updateQuarantine computes the head lag of every endpoint against the highest head in the pool
+// and updates which endpoints are quarantined
+func (p *Pool) updateQuarantine() {
+	var highestHead uint64
+	for _, endpoint := range p.endpoints {
+		endpoint.mutex.Lock()
+		if endpoint.head > highestHead {
+			highestHead = endpoint.head
+		}
+		endpoint.mutex.Unlock()
+	}
+
+	for _, endpoint := range p.endpoints {
+		endpoint.mutex.Lock()
+		endpoint.headLag = highestHead - endpoint.head
+		quarantined := endpoint.headLag > p.params.MaxHeadLag || endpoint.consecutiveFailures >= MaxConsecutiveFailures
+		changed := quarantined != endpoint.quarantined
+		endpoint.quarantined = quarantined
+		headLag, consecutiveFailures := endpoint.headLag, endpoint.consecutiveFailures
+		endpoint.mutex.Unlock()
+
+		if !changed || p.logger == nil {
+			continue
+		}
+		if quarantined {
+			p.logger.Warn("RPC endpoint quarantined", "pool", p.name, "url", endpoint.Url,
+				"headLag", headLag, "consecutiveFailures", consecutiveFailures)
+		} else {
+			p.logger.Info("RPC endpoint back from quarantine", "pool", p.name, "url", endpoint.Url)
+		}
+	}
+}
+
+// Messages of the errors a node returns when it is behind the others, and does not have the block or state yet
+var laggingNodeErrorMessages = []string{
+	"header not found",
+	"unknown block",
+	"block not found",
+	"missing trie node",
+}
+
+// isEndpointError tells whether an error is caused by the endpoint, in which case the call is tried on the next one.
+// Errors returned by the node itself, such as reverts or "not found", would be the same on any endpoint,
+// except the ones of a node that lags behind, which the next endpoint may not return.
+func isEndpointError(err error) bool {
+	// Cancelled by the caller, it would be cancelled on any endpoint
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	if containsAny(err, laggingNodeErrorMessages) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var dataErr rpc.DataError
+	return !errors.As(err, &dataErr)
+}
+
+// alreadySent tells whether the error of sending a transaction again, after it failed on another endpoint, means the
+// first attempt reached the network: the node already has the transaction, or its nonce was used and isIncluded
+// finds it was by this transaction
+func alreadySent(err error, isIncluded func() bool) bool {
+	if containsAny(err, []string{"already known", "known transaction", "already imported"}) {
+		return true
+	}
+	return containsAny(err, []string{"nonce too low"}) && isIncluded()
+}
+
+func containsAny(err error, messages []string) bool {
+	message := strings.ToLower(err.Error())
+	for _, m := range messages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// call runs fn on the endpoints in ranking order until one of them succeeds or returns a node error.
+// Endpoints whose circuit is open are skipped, and retry.ErrCircuitOpen is returned if every circuit is open.
+// Once ctx is cancelled or expired the call returns right away: every endpoint would fail the same way, and
+// the failure is the caller's, so it is not recorded against the endpoints.
+func call[T any](ctx context.Context, p *Pool, fn func(client *eth.InstrumentedClient) (T, error)) (T, error) {
+	var result T
+	err := retry.ErrCircuitOpen
+	for _, endpoint := range p.ranked() {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if !endpoint.breaker.Allow() {
+			continue
+		}
+		var failed bool
+		result, failed, err = callEndpoint(ctx, endpoint, fn)
+		if !failed {
+			return result, err
+		}
+		if p.logger != nil {
+			p.logger.Debug("RPC call failed, trying next endpoint", "pool", p.name, "url", endpoint.Url, "err", err)
+		}
+	}
+	return result, err
+}
+
+// callEndpoint runs fn on the endpoint and records the result. If fn panics, the call is recorded as failed before
+// the panic goes on, so a half-open circuit does not keep waiting for the result of its trial call.
+// A call cut short by ctx is not recorded, and is not reported as failed so the next endpoints are not tried
+func callEndpoint[T any](ctx context.Context, endpoint *Endpoint, fn func(client *eth.InstrumentedClient) (T, error)) (result T, failed bool, err error) {
+	start := time.Now()
+	failed = true
+	defer func() {
+		if ctx.Err() != nil {
+			endpoint.breaker.Cancel()
+			failed = false
+			return
+		}
+		endpoint.recordCall(time.Since(start), failed)
+		endpoint.breaker.Record(failed)
+	}()
+	result, err = fn(endpoint.client)
+	failed = err != nil && isEndpointError(err)
+	return result, failed, err
+}
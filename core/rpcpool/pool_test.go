@@ -0,0 +1,291 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/ethereum/go-ethereum"
+	retry "github.com/yetanotherco/aligned_layer/core"
+)
+
+func newTestPool(urls ...string) *Pool {
+	endpoints := make([]*Endpoint, 0, len(urls))
+	for _, url := range urls {
+		endpoints = append(endpoints, &Endpoint{Url: url, breaker: retry.NewCircuitBreaker(url, retry.BreakerParams{})})
+	}
+	return &Pool{
+		name:      "test",
+		endpoints: endpoints,
+		params:    PoolParams{MaxHeadLag: DefaultMaxHeadLag, HealthCheckPeriod: DefaultHealthCheckPeriod},
+	}
+}
+
+func rankedUrls(pool *Pool) []string {
+	urls := []string{}
+	for _, endpoint := range pool.ranked() {
+		urls = append(urls, endpoint.Url)
+	}
+	return urls
+}
+
+func TestPoolRanksByLatencyAndErrors(t *testing.T) {
+	pool := newTestPool("slow", "fast", "failing")
+	pool.endpoints[0].recordCall(300*time.Millisecond, false)
+	pool.endpoints[1].recordCall(50*time.Millisecond, false)
+	pool.endpoints[2].recordCall(40*time.Millisecond, false)
+	for i := 0; i < 5; i++ {
+		pool.endpoints[2].recordCall(time.Second, true)
+	}
+
+	urls := rankedUrls(pool)
+	if urls[0] != "fast" || urls[1] != "slow" || urls[2] != "failing" {
+		t.Errorf("Unexpected ranking: %v", urls)
+	}
+}
+
+func TestPoolQuarantinesLaggingEndpoints(t *testing.T) {
+	pool := newTestPool("lagging", "head")
+	pool.endpoints[0].recordCall(10*time.Millisecond, false)
+	pool.endpoints[0].head = 100
+	pool.endpoints[1].recordCall(200*time.Millisecond, false)
+	pool.endpoints[1].head = 100 + DefaultMaxHeadLag + 1
+
+	pool.updateQuarantine()
+
+	if !pool.endpoints[0].isQuarantined() {
+		t.Errorf("Expected lagging endpoint to be quarantined")
+	}
+	if urls := rankedUrls(pool); urls[0] != "head" {
+		t.Errorf("Expected quarantined endpoint to be ranked last, got %v", urls)
+	}
+
+	// Once it catches up, it is back in the ranking
+	pool.endpoints[0].head = pool.endpoints[1].head
+	pool.updateQuarantine()
+	if pool.endpoints[0].isQuarantined() {
+		t.Errorf("Expected endpoint to leave quarantine after catching up")
+	}
+	if urls := rankedUrls(pool); urls[0] != "lagging" {
+		t.Errorf("Expected the fastest endpoint first, got %v", urls)
+	}
+}
+
+func TestPoolWithOffset(t *testing.T) {
+	pool := newTestPool("a", "b", "c")
+	pool.endpoints[0].recordCall(10*time.Millisecond, false)
+	pool.endpoints[1].recordCall(20*time.Millisecond, false)
+	pool.endpoints[2].recordCall(30*time.Millisecond, false)
+
+	urls := rankedUrls(pool.WithOffset(1))
+	if urls[0] != "b" || urls[1] != "c" || urls[2] != "a" {
+		t.Errorf("Unexpected ranking with offset: %v", urls)
+	}
+}
+
+// nodeError is an error returned by the node in the json rpc response
+type nodeError struct {
+	message string
+}
+
+func (e nodeError) Error() string  { return e.message }
+func (e nodeError) ErrorCode() int { return -32000 }
+
+func TestIsEndpointError(t *testing.T) {
+	if isEndpointError(ethereum.NotFound) {
+		t.Errorf("Expected not found to be a node error")
+	}
+	if !isEndpointError(errors.New("connection refused")) {
+		t.Errorf("Expected connection errors to be endpoint errors")
+	}
+	if isEndpointError(nodeError{"execution reverted"}) {
+		t.Errorf("Expected reverts to be node errors")
+	}
+	for _, message := range []string{"header not found", "unknown block", "missing trie node 1234 (path )"} {
+		if !isEndpointError(nodeError{message}) {
+			t.Errorf("Expected %q of a lagging node to be an endpoint error", message)
+		}
+	}
+}
+
+func TestAlreadySent(t *testing.T) {
+	included := func() bool { return true }
+	notIncluded := func() bool { return false }
+
+	if !alreadySent(nodeError{"already known"}, notIncluded) {
+		t.Errorf("Expected a transaction already known by the node to be sent")
+	}
+	if !alreadySent(nodeError{"nonce too low: next nonce 5, tx nonce 4"}, included) {
+		t.Errorf("Expected an included transaction whose nonce is too low to be sent")
+	}
+	if alreadySent(nodeError{"nonce too low: next nonce 5, tx nonce 4"}, notIncluded) {
+		t.Errorf("Expected a transaction whose nonce was used by another one not to be sent")
+	}
+	if alreadySent(nodeError{"insufficient funds for gas * price + value"}, included) {
+		t.Errorf("Expected other errors not to mean the transaction was sent")
+	}
+}
+
+func TestPoolCloseStopsHealthChecks(t *testing.T) {
+	pool, err := NewPool("test", []string{"http://127.0.0.1:1"}, PoolParams{HealthCheckPeriod: 10 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		pool.WithOffset(1).Close()
+		pool.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Close to stop the health checks")
+	}
+}
+
+func TestPanickingCallDoesNotBlockHalfOpenCircuit(t *testing.T) {
+	pool := newTestPool("panicking")
+	pool.endpoints[0].breaker = retry.NewCircuitBreaker("panicking", retry.BreakerParams{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	defer pool.endpoints[0].breaker.Close()
+	pool.endpoints[0].breaker.Record(true)
+	time.Sleep(5 * time.Millisecond)
+
+	// The trial call of the half-open circuit panics
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = call(context.Background(), pool, func(client *eth.InstrumentedClient) (int, error) {
+			panic("boom")
+		})
+	}()
+	if state := pool.endpoints[0].breaker.State(); state != retry.CircuitOpen {
+		t.Fatalf("Expected the panicking trial to open the circuit again, got %s", state)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := call(context.Background(), pool, func(client *eth.InstrumentedClient) (int, error) { return 1, nil }); err != nil {
+		t.Errorf("Expected a new trial call once the circuit timed out, got %v", err)
+	}
+}
+
+func TestCallerDeadlineIsNotRecordedAgainstEndpoints(t *testing.T) {
+	pool := newTestPool("a", "b")
+	for _, endpoint := range pool.endpoints {
+		endpoint.breaker = retry.NewCircuitBreaker(endpoint.Url, retry.BreakerParams{FailureThreshold: 1, OpenTimeout: time.Hour})
+		defer endpoint.breaker.Close()
+	}
+
+	// The deadline of the caller passes during the call
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	calls := 0
+	_, err := call(ctx, pool, func(client *eth.InstrumentedClient) (int, error) {
+		calls++
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline of the caller, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the call not to be tried on the next endpoint, got %d calls", calls)
+	}
+
+	// The context is already expired, no endpoint is called
+	_, err = call(ctx, pool, func(client *eth.InstrumentedClient) (int, error) {
+		calls++
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 1 {
+		t.Errorf("Expected the expired call to return right away, got %v after %d calls", err, calls)
+	}
+
+	for _, endpoint := range pool.endpoints {
+		if state := endpoint.breaker.State(); state != retry.CircuitClosed {
+			t.Errorf("Expected the circuit of %s to stay closed, got %s", endpoint.Url, state)
+		}
+		if status := endpoint.Status(); status.ErrorRate != 0 {
+			t.Errorf("Expected no error recorded for %s, got an error rate of %f", endpoint.Url, status.ErrorRate)
+		}
+	}
+}
+
+// hangingNode is a json rpc server that never answers the hanging methods, and answers "0x1" to the others
+func hangingNode(t *testing.T, hanging ...string) string {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Id     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if slices.Contains(hanging, request.Method) {
+			<-release
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x1"}`, request.Id)
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server.URL
+}
+
+func TestNewPoolDoesNotHangOnUnresponsiveEndpoint(t *testing.T) {
+	params := PoolParams{HealthCheckPeriod: 50 * time.Millisecond}
+
+	pool, err := NewPool("hangingHead", []string{hangingNode(t, "eth_blockNumber")}, params, nil)
+	if err != nil {
+		t.Fatalf("Expected an endpoint failing its health check to be added, got %v", err)
+	}
+	defer pool.Close()
+	if consecutiveFailures := pool.endpoints[0].consecutiveFailures; consecutiveFailures != 1 {
+		t.Errorf("Expected the first health check to time out, got %d failures", consecutiveFailures)
+	}
+
+	if _, err := NewPool("hangingDial", []string{hangingNode(t, "web3_clientVersion")}, params, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected dialing an endpoint that does not answer to time out, got %v", err)
+	}
+}
+
+// circuitsObserver records the names of the circuit breakers reported to it
+type circuitsObserver struct {
+	names []string
+}
+
+func (o *circuitsObserver) ObserveRetryAttempt(name string, attempt uint64, duration time.Duration, err error) {
+}
+
+func (o *circuitsObserver) ObserveRetryGiveUp(name string, attempts uint64, err error) {}
+
+func (o *circuitsObserver) ObserveCircuitState(name string, state retry.CircuitState) {
+	o.names = append(o.names, name)
+}
+
+func TestNewPoolReleasesEndpointsOnDialError(t *testing.T) {
+	_, err := NewPool("leaked", []string{hangingNode(t), "unknown://127.0.0.1"}, PoolParams{HealthCheckPeriod: time.Second}, nil)
+	if err == nil {
+		t.Fatalf("Expected an error dialing an url without transport")
+	}
+
+	observer := &circuitsObserver{}
+	retry.SetObserver(observer)
+	defer retry.SetObserver(nil)
+	if slices.Contains(observer.names, "leaked0") {
+		t.Errorf("Expected the breaker of the endpoint dialed before the error to be unregistered")
+	}
+}
@@ -2,7 +2,7 @@ package utils
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -21,7 +21,7 @@ func ReadYamlConfig(path string, o interface{}) error {
 
 	err = yaml.Unmarshal(b, o)
 	if err != nil {
-		log.Fatalf("unable to parse file with error %#v", err)
+		return fmt.Errorf("unable to parse file: %w", err)
 	}
 
 	return nil
@@ -35,7 +35,7 @@ func ReadJsonConfig(path string, o interface{}) error {
 
 	err = json.Unmarshal(b, o)
 	if err != nil {
-		log.Fatalf("unable to parse file with error %#v", err)
+		return fmt.Errorf("unable to parse file: %w", err)
 	}
 
 	return nil
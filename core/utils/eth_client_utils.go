@@ -4,11 +4,11 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/rpcpool"
 )
 
 // WaitForTransactionReceiptRetryable repeatedly attempts to fetch the transaction receipt for a given transaction hash.
@@ -19,19 +19,11 @@ import (
 // Setting a higher value will imply doing less retries across the waitTimeout, and so we might lose the receipt
 // All errors are considered Transient Errors
 // - Retry times: 0.5s, 1s, 2s, 2s, 2s, ... until it reaches waitTimeout
-func WaitForTransactionReceiptRetryable(client eth.InstrumentedClient, fallbackClient eth.InstrumentedClient, txHash gethcommon.Hash, config *retry.RetryParams) (*types.Receipt, error) {
-	receipt_func := func() (*types.Receipt, error) {
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err != nil {
-			receipt, err = fallbackClient.TransactionReceipt(context.Background(), txHash)
-			if err != nil {
-				return nil, err
-			}
-			return receipt, nil
-		}
-		return receipt, nil
+func WaitForTransactionReceiptRetryable(ctx context.Context, client *rpcpool.Pool, txHash gethcommon.Hash, config *retry.RetryParams) (*types.Receipt, error) {
+	receipt_func := func(ctx context.Context) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
 	}
-	return retry.RetryWithData(receipt_func, config)
+	return retry.RetryWithDataContext(ctx, "WaitForTransactionReceipt", receipt_func, config)
 }
 
 func BytesToQuorumNumbers(quorumNumbersBytes []byte) eigentypes.QuorumNums {
@@ -92,17 +84,9 @@ Get the gas price from the client with retry logic.
 - All errors are considered Transient Errors
 - Retry times: 1 sec, 2 sec, 4 sec
 */
-func GetGasPriceRetryable(client eth.InstrumentedClient, fallbackClient eth.InstrumentedClient, config *retry.RetryParams) (*big.Int, error) {
-	respondToTaskV2_func := func() (*big.Int, error) {
-		gasPrice, err := client.SuggestGasPrice(context.Background())
-		if err != nil {
-			gasPrice, err = fallbackClient.SuggestGasPrice(context.Background())
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		return gasPrice, nil
+func GetGasPriceRetryable(ctx context.Context, client *rpcpool.Pool, config *retry.RetryParams) (*big.Int, error) {
+	respondToTaskV2_func := func(ctx context.Context) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
 	}
-	return retry.RetryWithData(respondToTaskV2_func, config)
+	return retry.RetryWithDataContext(ctx, "GetGasPrice", respondToTaskV2_func, config)
 }
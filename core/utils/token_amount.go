@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseTokenAmount converts a decimal amount of tokens, like "1.5", to the smallest unit of a token
+// with the given decimals. Amounts with more fractional digits than the token has are rejected
+func ParseTokenAmount(amount string, decimals uint8) (*big.Int, error) {
+	whole, fraction, hasFraction := strings.Cut(strings.TrimSpace(amount), ".")
+	if whole == "" && (!hasFraction || fraction == "") {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	digits := whole + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	if strings.ContainsFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	value, _ := new(big.Int).SetString(digits, 10)
+	return value, nil
+}
+
+// FormatTokenAmount converts an amount in the smallest unit of a token to decimals, without trailing zeros
+func FormatTokenAmount(amount *big.Int, decimals uint8) string {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(new(big.Int).Abs(amount), unit, new(big.Int))
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	if fraction.Sign() == 0 {
+		return sign + whole.String()
+	}
+	fractionDigits := fmt.Sprintf("%0*s", decimals, fraction.String())
+	return sign + whole.String() + "." + strings.TrimRight(fractionDigits, "0")
+}
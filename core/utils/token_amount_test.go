@@ -0,0 +1,72 @@
+package utils_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	valid := []struct {
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"1.5", 18, "1500000000000000000"},
+		{"0.000000000000000001", 18, "1"},
+		{".25", 6, "250000"},
+		{"3.", 6, "3000000"},
+		{"100000000000", 18, "100000000000000000000000000000"},
+		{"7", 0, "7"},
+	}
+	for _, test := range valid {
+		got, err := utils.ParseTokenAmount(test.amount, test.decimals)
+		if err != nil {
+			t.Errorf("ParseTokenAmount(%q, %d): %v", test.amount, test.decimals, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("ParseTokenAmount(%q, %d) = %s, want %s", test.amount, test.decimals, got, test.want)
+		}
+	}
+
+	invalid := []struct {
+		amount   string
+		decimals uint8
+	}{
+		{"", 18},
+		{".", 18},
+		{"-1", 18},
+		{"1e18", 18},
+		{"1.2.3", 18},
+		{"0x10", 18},
+		{"1.0000001", 6},
+		{"0.5", 0},
+	}
+	for _, test := range invalid {
+		if got, err := utils.ParseTokenAmount(test.amount, test.decimals); err == nil {
+			t.Errorf("ParseTokenAmount(%q, %d) = %s, expected an error", test.amount, test.decimals, got)
+		}
+	}
+}
+
+func TestFormatTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount   *big.Int
+		decimals uint8
+		want     string
+	}{
+		{big.NewInt(0), 18, "0"},
+		{big.NewInt(1), 18, "0.000000000000000001"},
+		{big.NewInt(1_500_000), 6, "1.5"},
+		{big.NewInt(-2_000_000), 6, "-2"},
+		{big.NewInt(42), 0, "42"},
+	}
+	for _, test := range tests {
+		if got := utils.FormatTokenAmount(test.amount, test.decimals); got != test.want {
+			t.Errorf("FormatTokenAmount(%s, %d) = %s, want %s", test.amount, test.decimals, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	NetworkFlag = &cli.BoolFlag{
+		Name:  "network",
+		Usage: "Also check the config against the chain: endpoints, deployed contracts and registration",
+	}
+	FormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Value: FormatText,
+		Usage: "Write the report as `FORMAT`, 'text' or 'json'",
+	}
+)
+
+// Result writes the report to stdout in the format of the command flags.
+// It returns an error that exits with status 1 if a check failed, so the command can be used in CI
+func Result(ctx *cli.Context, report *Report) error {
+	if err := report.Write(os.Stdout, ctx.String(FormatFlag.Name)); err != nil {
+		return err
+	}
+	if failed := report.Failed(); failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d check(s) failed", failed), 1)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Statuses of a check
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	// The check was not run, because a check it depends on failed
+	StatusSkip = "skip"
+)
+
+// Formats the report can be written in
+const (
+	FormatText = "text"
+	FormatJson = "json"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report lists the checks run by the validate commands, in the order they were run
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+func (r *Report) pass(name string, message string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusPass, Message: message})
+}
+
+func (r *Report) fail(name string, err error) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusFail, Message: err.Error()})
+}
+
+func (r *Report) skip(name string, reason string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusSkip, Message: reason})
+}
+
+// check adds a passing check if err is nil, and a failing one otherwise. It returns whether the check passed
+func (r *Report) check(name string, message string, err error) bool {
+	if err != nil {
+		r.fail(name, err)
+		return false
+	}
+	r.pass(name, message)
+	return true
+}
+
+// Failed returns the number of failed checks
+func (r *Report) Failed() int {
+	return r.count(StatusFail)
+}
+
+func (r *Report) count(status string) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Write writes the report in the given format: a line per check followed by a summary for text,
+// or a single object with the checks for json
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.writeText(w)
+	case FormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	default:
+		return fmt.Errorf("unknown report format %q, expected %q or %q", format, FormatText, FormatJson)
+	}
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		line := fmt.Sprintf("%-4s  %s", strings.ToUpper(check.Status), check.Name)
+		if check.Message != "" {
+			// Multi-line messages, such as the problems of a ValidationError, are indented under the check
+			line += ": " + strings.ReplaceAll(check.Message, "\n", "\n      ")
+		}
+		b.WriteString(line + "\n")
+	}
+	fmt.Fprintf(&b, "%d passed, %d failed, %d skipped\n", r.count(StatusPass), r.count(StatusFail), r.count(StatusSkip))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,304 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// Timeout of every check that goes through the network
+const networkCheckTimeout = 10 * time.Second
+
+// ValidateOperatorConfig checks the config file of an operator. With network, the config is also checked
+// against the chain: the endpoints, the deployed contracts, and the registration of the operator
+func ValidateOperatorConfig(configFilePath string, network bool) *Report {
+	report := &Report{}
+	staticConfig, err := config.CheckOperatorConfig(configFilePath)
+	if !checkStaticConfig(report, staticConfig, err, network) {
+		return report
+	}
+
+	const registeredCheck = "operator registered"
+	const aggregatorCheck = "aggregator rpc server reachable"
+	if report.Failed() > 0 {
+		report.skip(registeredCheck, "the endpoints or the deployment are invalid")
+		report.skip(aggregatorCheck, "the endpoints or the deployment are invalid")
+		return report
+	}
+
+	operatorConfig, err := config.NewOperatorConfig(configFilePath)
+	if err != nil {
+		report.fail("load config", err)
+		return report
+	}
+	defer operatorConfig.BaseConfig.Close()
+
+	registered, err := isOperatorRegistered(operatorConfig)
+	if err == nil && !registered {
+		err = fmt.Errorf("%s is not registered, run the register command", operatorConfig.Operator.Address)
+	}
+	report.check(registeredCheck, operatorConfig.Operator.Address.String(), err)
+
+	aggregatorAddress := operatorConfig.Operator.AggregatorServerIpPortAddress
+	conn, err := net.DialTimeout("tcp", aggregatorAddress, networkCheckTimeout)
+	if err == nil {
+		conn.Close()
+	}
+	report.check(aggregatorCheck, aggregatorAddress, err)
+
+	return report
+}
+
+// ValidateAggregatorConfig checks the config file of the aggregator. With network, the config is also checked
+// against the chain: the endpoints, the deployed contracts, and that the ecdsa key is the one of the aggregator
+func ValidateAggregatorConfig(configFilePath string, network bool) *Report {
+	report := &Report{}
+	staticConfig, err := config.CheckAggregatorConfig(configFilePath)
+	if !checkStaticConfig(report, staticConfig, err, network) {
+		return report
+	}
+
+	const aggregatorCheck = "ecdsa key is the aggregator of the service manager"
+	if report.Failed() > 0 {
+		report.skip(aggregatorCheck, "the endpoints or the deployment are invalid")
+		return report
+	}
+
+	aggregatorConfig, err := config.NewAggregatorConfig(configFilePath)
+	if err != nil {
+		report.fail("load config", err)
+		return report
+	}
+	defer aggregatorConfig.BaseConfig.Close()
+
+	ecdsaAddress := crypto.PubkeyToAddress(aggregatorConfig.EcdsaConfig.PrivateKey.PublicKey)
+	alignedAggregator, err := getAlignedAggregator(aggregatorConfig.BaseConfig)
+	if err == nil && alignedAggregator != ecdsaAddress {
+		err = fmt.Errorf("the key is for %s, but the service manager expects %s", ecdsaAddress, alignedAggregator)
+	}
+	report.check(aggregatorCheck, ecdsaAddress.String(), err)
+
+	return report
+}
+
+// checkStaticConfig adds the problems of the config file to the report, and when it is valid and network is set,
+// checks the endpoints and the deployment. It returns whether the caller should go on with its network checks
+func checkStaticConfig(report *Report, staticConfig *config.StaticConfig, err error, network bool) bool {
+	const configCheck = "config"
+	if err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Errors {
+				report.fail(configCheck, problem)
+			}
+		} else {
+			report.fail(configCheck, err)
+		}
+		if network {
+			report.skip("network", "the config file is invalid")
+		}
+		return false
+	}
+	report.pass(configCheck, "every field is valid and the key stores decrypt")
+
+	if !network {
+		return false
+	}
+
+	client := checkEndpoints(report, staticConfig)
+	if client == nil {
+		report.skip("deployment", "no eth rpc endpoint is reachable")
+		report.skip("contract code", "no eth rpc endpoint is reachable")
+		return true
+	}
+	defer client.Close()
+	alignedLayer, eigenLayer := checkDeployment(report, client, staticConfig)
+	if alignedLayer == nil {
+		// The configured addresses are still checked, unless they were to be read from the service manager
+		if staticConfig.EigenLayerDeploymentConfig == nil {
+			report.skip("contract code", "the deployment could not be read from the service manager")
+			return true
+		}
+		alignedLayer, eigenLayer = staticConfig.AlignedLayerDeploymentConfig, staticConfig.EigenLayerDeploymentConfig
+	}
+	checkContractCode(report, client, alignedLayer, eigenLayer)
+	return true
+}
+
+// checkDeployment reads the deployment from the service manager, checking the configured addresses match it.
+// It returns the resolved deployment, or nil if it can not be read
+func checkDeployment(report *Report, client *ethclient.Client, staticConfig *config.StaticConfig) (*config.AlignedLayerDeploymentConfig, *config.EigenLayerDeploymentConfig) {
+	const deploymentCheck = "deployment matches the service manager"
+	ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+	defer cancel()
+
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		report.fail(deploymentCheck, fmt.Errorf("could not get chain id: %w", err))
+		return nil, nil
+	}
+	alignedLayer, eigenLayer, err := config.ResolveDeployment(ctx, client, chainId, staticConfig.AlignedLayerDeploymentConfig, staticConfig.EigenLayerDeploymentConfig)
+	if err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Errors {
+				report.fail(deploymentCheck, problem)
+			}
+		} else {
+			report.fail(deploymentCheck, err)
+		}
+		return nil, nil
+	}
+	report.pass(deploymentCheck, alignedLayer.AlignedLayerServiceManagerAddr.String())
+	return alignedLayer, eigenLayer
+}
+
+// checkEndpoints checks every endpoint is reachable and on the chain of the deployment.
+// It returns a client of the first reachable rpc endpoint, or nil if there is none
+func checkEndpoints(report *Report, staticConfig *config.StaticConfig) *ethclient.Client {
+	var rpcClient *ethclient.Client
+	for i, endpointUrl := range staticConfig.EthRpcUrls {
+		client := checkEndpoint(report, fmt.Sprintf("eth rpc endpoint %d", i), endpointUrl, staticConfig)
+		if client == nil {
+			continue
+		}
+		if rpcClient == nil {
+			rpcClient = client
+		} else {
+			client.Close()
+		}
+	}
+	for i, endpointUrl := range staticConfig.EthWsUrls {
+		if client := checkEndpoint(report, fmt.Sprintf("eth ws endpoint %d", i), endpointUrl, staticConfig); client != nil {
+			client.Close()
+		}
+	}
+	return rpcClient
+}
+
+// checkEndpoint dials the endpoint and compares its chain id with the deployment files
+func checkEndpoint(report *Report, name string, endpointUrl string, staticConfig *config.StaticConfig) *ethclient.Client {
+	ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+	defer cancel()
+
+	// Only the host is printed, as the url may contain an api key
+	name = fmt.Sprintf("%s (%s)", name, redactUrl(endpointUrl))
+	client, err := ethclient.DialContext(ctx, endpointUrl)
+	if err != nil {
+		report.fail(name, err)
+		return nil
+	}
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		report.fail(name, fmt.Errorf("could not get chain id: %w", err))
+		return nil
+	}
+
+	deployments := []struct {
+		file    string
+		chainId uint64
+	}{
+		{"aligned layer", staticConfig.AlignedLayerDeploymentConfig.ChainId},
+	}
+	// Read from the chain later when only the service manager is configured
+	if staticConfig.EigenLayerDeploymentConfig != nil {
+		deployments = append(deployments, struct {
+			file    string
+			chainId uint64
+		}{"eigen layer", staticConfig.EigenLayerDeploymentConfig.ChainId})
+	}
+	for _, deployment := range deployments {
+		if deployment.chainId != 0 && new(big.Int).SetUint64(deployment.chainId).Cmp(chainId) != 0 {
+			report.fail(name, fmt.Errorf("chain id is %s, but the %s deployment is on chain %d", chainId, deployment.file, deployment.chainId))
+			return client
+		}
+	}
+	report.pass(name, fmt.Sprintf("chain id %s", chainId))
+	return client
+}
+
+type deployedContract struct {
+	name    string
+	address common.Address
+}
+
+// checkContractCode checks there is a contract at every address of the deployment
+func checkContractCode(report *Report, client *ethclient.Client, alignedLayer *config.AlignedLayerDeploymentConfig, eigenLayer *config.EigenLayerDeploymentConfig) {
+	contracts := []deployedContract{
+		{"alignedLayerServiceManager", alignedLayer.AlignedLayerServiceManagerAddr},
+		{"registryCoordinator", alignedLayer.AlignedLayerRegistryCoordinatorAddr},
+		{"operatorStateRetriever", alignedLayer.AlignedLayerOperatorStateRetrieverAddr},
+		{"delegationManager", eigenLayer.DelegationManagerAddr},
+		{"avsDirectory", eigenLayer.AVSDirectoryAddr},
+		{"slasher", eigenLayer.SlasherAddr},
+	}
+	optionalContracts := []deployedContract{
+		{"stakeRegistry", alignedLayer.AlignedLayerStakeRegistryAddr},
+		{"blsApkRegistry", alignedLayer.AlignedLayerBlsApkRegistryAddr},
+		{"multicall3", alignedLayer.Multicall3Addr},
+	}
+	for _, contract := range optionalContracts {
+		if contract.address != (common.Address{}) {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	for _, contract := range contracts {
+		name := fmt.Sprintf("contract code of %s", contract.name)
+		ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+		code, err := client.CodeAt(ctx, contract.address, nil)
+		cancel()
+		if err == nil && len(code) == 0 {
+			err = fmt.Errorf("no contract deployed at %s", contract.address)
+		}
+		report.check(name, contract.address.String(), err)
+	}
+}
+
+func isOperatorRegistered(operatorConfig *config.OperatorConfig) (bool, error) {
+	baseConfig := operatorConfig.BaseConfig
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinatorCaller(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+	defer cancel()
+	status, err := registryCoordinator.GetOperatorStatus(&bind.CallOpts{Context: ctx}, operatorConfig.Operator.Address)
+	if err != nil {
+		return false, fmt.Errorf("could not get registration status: %w", err)
+	}
+	// 0 = NEVER_REGISTERED, 1 = REGISTERED, 2 = DEREGISTERED
+	return status == 1, nil
+}
+
+func getAlignedAggregator(baseConfig *config.BaseConfig) (common.Address, error) {
+	serviceManager, err := servicemanager.NewContractAlignedLayerServiceManagerCaller(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+	defer cancel()
+	return serviceManager.AlignedAggregator(&bind.CallOpts{Context: ctx})
+}
+
+// redactUrl returns the scheme and host of the url, leaving out the path and credentials where api keys go
+func redactUrl(rawUrl string) string {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil || parsedUrl.Host == "" {
+		return "invalid url"
+	}
+	return parsedUrl.Scheme + "://" + parsedUrl.Host
+}
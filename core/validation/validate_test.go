@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDeploymentDir = "../../contracts/script/output/devnet"
+
+func writeTestConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Could not write config: %v", err)
+	}
+	return path
+}
+
+// newTestRpcServer answers eth_chainId with chainId, and eth_getCode with empty code
+func newTestRpcServer(t *testing.T, chainId string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Id     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := `"0x"`
+		if request.Method == "eth_chainId" {
+			result = fmt.Sprintf("%q", chainId)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, request.Id, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func checksByName(report *Report) map[string]Check {
+	checks := make(map[string]Check)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestValidateOperatorConfigSkipsNetworkChecksOfInvalidConfig(t *testing.T) {
+	configFilePath := writeTestConfig(t, `
+environment: development
+eth_rpc_url: http://localhost:1
+eigen_metrics_ip_port_address: localhost:9090
+aligned_layer_deployment_config_file_path: `+testDeploymentDir+`/alignedlayer_deployment_output.json
+eigen_layer_deployment_config_file_path: `+testDeploymentDir+`/eigenlayer_deployment_output.json
+bls:
+  private_key_store_path: ./missing.bls.key.json
+`)
+
+	report := ValidateOperatorConfig(configFilePath, true)
+	// bls key store, ecdsa key store, operator address, aggregator address and last processed batch file
+	if report.Failed() != 5 {
+		t.Errorf("Expected 5 failed checks, got %+v", report.Checks)
+	}
+	last := report.Checks[len(report.Checks)-1]
+	if last.Name != "network" || last.Status != StatusSkip {
+		t.Errorf("Expected the network checks to be skipped, got %+v", last)
+	}
+}
+
+func TestValidateOperatorConfigChecksEndpointsAndContracts(t *testing.T) {
+	server := newTestRpcServer(t, "0x1")
+	configFilePath := writeTestConfig(t, `
+environment: development
+eth_rpc_url: `+server.URL+`/secret-api-key
+event_source: http
+eigen_metrics_ip_port_address: localhost:9090
+aligned_layer_deployment_config_file_path: `+testDeploymentDir+`/alignedlayer_deployment_output.json
+eigen_layer_deployment_config_file_path: `+testDeploymentDir+`/eigenlayer_deployment_output.json
+bls:
+  private_key_store_path: ../../config-files/devnet/keys/operator-1.bls.key.json
+  private_key_store_password: ''
+ecdsa:
+  private_key_store_path: ../../config-files/devnet/keys/operator-1.ecdsa.key.json
+  private_key_store_password: ''
+operator:
+  address: 0x70997970C51812dc3A010C7d01b50e0d17dc79C8
+  aggregator_rpc_server_ip_port_address: localhost:8090
+  last_processed_batch_filepath: ./operator.last_processed_batch.json
+`)
+
+	report := ValidateOperatorConfig(configFilePath, true)
+	checks := checksByName(report)
+
+	if checks["config"].Status != StatusPass {
+		t.Fatalf("Expected the config to be valid, got %+v", checks["config"])
+	}
+	endpoint, ok := checks["eth rpc endpoint 0 ("+server.URL+")"]
+	if !ok {
+		t.Fatalf("Expected a check of the endpoint without its path, got %+v", report.Checks)
+	}
+	// The devnet deployment is on chain 31337
+	if endpoint.Status != StatusFail || !strings.Contains(endpoint.Message, "31337") {
+		t.Errorf("Expected the endpoint to fail for its chain id, got %+v", endpoint)
+	}
+	if check := checks["contract code of alignedLayerServiceManager"]; check.Status != StatusFail {
+		t.Errorf("Expected the contract check to fail without code, got %+v", check)
+	}
+	if check := checks["operator registered"]; check.Status != StatusSkip {
+		t.Errorf("Expected the registration check to be skipped, got %+v", check)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := &Report{}
+	report.pass("config", "")
+	report.fail("operator registered", fmt.Errorf("not registered"))
+	report.skip("aggregator rpc server reachable", "earlier checks failed")
+
+	var text bytes.Buffer
+	if err := report.Write(&text, FormatText); err != nil {
+		t.Fatalf("Could not write text report: %v", err)
+	}
+	expected := `PASS  config
+FAIL  operator registered: not registered
+SKIP  aggregator rpc server reachable: earlier checks failed
+1 passed, 1 failed, 1 skipped
+`
+	if text.String() != expected {
+		t.Errorf("Unexpected text report:\n%s", text.String())
+	}
+
+	var jsonReport bytes.Buffer
+	if err := report.Write(&jsonReport, FormatJson); err != nil {
+		t.Fatalf("Could not write json report: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(jsonReport.Bytes(), &decoded); err != nil || len(decoded.Checks) != 3 || decoded.Checks[1].Status != StatusFail {
+		t.Errorf("Unexpected json report %s: %v", jsonReport.String(), err)
+	}
+
+	if err := report.Write(&text, "xml"); err == nil {
+		t.Errorf("Expected an unknown format to fail")
+	}
+}
+
+func TestValidateOperatorConfigChecksEcdsaKeyIsTheOperators(t *testing.T) {
+	configFilePath := writeTestConfig(t, `
+environment: development
+eth_rpc_url: http://localhost:1
+eigen_metrics_ip_port_address: localhost:9090
+aligned_layer_deployment_config_file_path: `+testDeploymentDir+`/alignedlayer_deployment_output.json
+eigen_layer_deployment_config_file_path: `+testDeploymentDir+`/eigenlayer_deployment_output.json
+bls:
+  private_key_store_path: ../../config-files/devnet/keys/operator-1.bls.key.json
+  private_key_store_password: ''
+ecdsa:
+  private_key_store_path: ../../config-files/devnet/keys/operator-2.ecdsa.key.json
+  private_key_store_password: ''
+operator:
+  address: 0x70997970C51812dc3A010C7d01b50e0d17dc79C8
+  aggregator_rpc_server_ip_port_address: localhost:8090
+  last_processed_batch_filepath: ./operator.last_processed_batch.json
+`)
+
+	report := ValidateOperatorConfig(configFilePath, false)
+	if report.Failed() != 1 {
+		t.Fatalf("Expected 1 failed check, got %+v", report.Checks)
+	}
+	if check := report.Checks[0]; !strings.Contains(check.Message, "operator.address") || !strings.Contains(check.Message, "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC") {
+		t.Errorf("Expected the operator address to be checked against the ecdsa key, got %+v", check)
+	}
+}
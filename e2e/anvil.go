@@ -0,0 +1,81 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// State of an anvil node with the EigenLayer and Aligned devnet contracts deployed,
+// see contracts/script/output/devnet for their addresses
+const AnvilStateFilePath = "../contracts/scripts/anvil/state/alignedlayer-deployed-anvil-state.json"
+
+const anvilStartTimeout = 30 * time.Second
+
+var ErrAnvilNotFound = errors.New("anvil not found in PATH, install foundry to run the end to end tests")
+
+// Anvil is a local node spawned from the devnet state
+type Anvil struct {
+	Url string
+	cmd *exec.Cmd
+}
+
+func StartAnvil() (*Anvil, error) {
+	anvilPath, err := exec.LookPath("anvil")
+	if err != nil {
+		return nil, ErrAnvilNotFound
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(anvilPath, "--load-state", AnvilStateFilePath, "--port", strconv.Itoa(port), "--silent")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting anvil: %w", err)
+	}
+	anvil := &Anvil{Url: fmt.Sprintf("http://localhost:%d", port), cmd: cmd}
+
+	ctx, cancel := context.WithTimeout(context.Background(), anvilStartTimeout)
+	defer cancel()
+	for {
+		client, err := ethclient.DialContext(ctx, anvil.Url)
+		if err == nil {
+			_, err = client.ChainID(ctx)
+			client.Close()
+		}
+		if err == nil {
+			return anvil, nil
+		}
+		select {
+		case <-ctx.Done():
+			anvil.Stop()
+			return nil, fmt.Errorf("anvil did not start: %w", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (a *Anvil) Stop() {
+	_ = a.cmd.Process.Kill()
+	_ = a.cmd.Wait()
+}
+
+// freePort returns a port that is free to listen on
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
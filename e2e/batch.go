@@ -0,0 +1,157 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// Batch of proofs from the batcher tests, every proof is a valid Groth16Bn254 proof
+const TestBatchFilePath = "../operator/merkle_tree/lib/test_files/merkle_tree_batch.bin"
+
+// BatchEntry is a proof of a batch, as the batcher stores it in the data service
+type BatchEntry struct {
+	ProvingSystemId       common.ProvingSystemId
+	Proof                 []byte
+	PubInput              []byte
+	VerificationKey       []byte
+	VmProgramCode         []byte
+	ProofGeneratorAddress ethcommon.Address
+}
+
+// batchEntryCbor is the layout of a BatchEntry in the batches serialized by the batcher.
+// Byte fields are arrays of integers, as serde serializes Vec<u8>, and the address is an hex string
+type batchEntryCbor struct {
+	ProvingSystem      string    `cbor:"proving_system"`
+	Proof              byteArray `cbor:"proof"`
+	PubInput           byteArray `cbor:"pub_input"`
+	VerificationKey    byteArray `cbor:"verification_key"`
+	VmProgramCode      byteArray `cbor:"vm_program_code"`
+	ProofGeneratorAddr string    `cbor:"proof_generator_addr"`
+}
+
+type byteArray []byte
+
+func (b byteArray) MarshalCBOR() ([]byte, error) {
+	if b == nil {
+		return cbor.Marshal(nil)
+	}
+	// A []uint16 is encoded as an array, while a []byte would be encoded as a byte string
+	array := make([]uint16, len(b))
+	for i, value := range b {
+		array[i] = uint16(value)
+	}
+	return cbor.Marshal(array)
+}
+
+func (b *byteArray) UnmarshalCBOR(data []byte) error {
+	var bytes []byte
+	if err := cbor.Unmarshal(data, &bytes); err != nil {
+		return err
+	}
+	*b = bytes
+	return nil
+}
+
+// LoadBatch reads a batch serialized by the batcher
+func LoadBatch(path string) ([]BatchEntry, error) {
+	batchBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := cbor.DecOptions{MaxArrayElements: 2147483647}.DecMode()
+	if err != nil {
+		return nil, err
+	}
+	var entries []batchEntryCbor
+	if err := decoder.Unmarshal(batchBytes, &entries); err != nil {
+		return nil, fmt.Errorf("error decoding batch %s: %w", path, err)
+	}
+
+	batch := make([]BatchEntry, 0, len(entries))
+	for _, entry := range entries {
+		provingSystemId, err := common.ProvingSystemIdFromString(entry.ProvingSystem)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, BatchEntry{
+			ProvingSystemId:       provingSystemId,
+			Proof:                 entry.Proof,
+			PubInput:              entry.PubInput,
+			VerificationKey:       entry.VerificationKey,
+			VmProgramCode:         entry.VmProgramCode,
+			ProofGeneratorAddress: ethcommon.HexToAddress(entry.ProofGeneratorAddr),
+		})
+	}
+	return batch, nil
+}
+
+// EncodeBatch serializes a batch as the batcher does, so it can be checked by the operator merkle tree verifier
+func EncodeBatch(batch []BatchEntry) ([]byte, error) {
+	entries := make([]batchEntryCbor, 0, len(batch))
+	for _, entry := range batch {
+		provingSystem, err := common.ProvingSystemIdToString(entry.ProvingSystemId)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, batchEntryCbor{
+			ProvingSystem:      provingSystem,
+			Proof:              entry.Proof,
+			PubInput:           entry.PubInput,
+			VerificationKey:    entry.VerificationKey,
+			VmProgramCode:      entry.VmProgramCode,
+			ProofGeneratorAddr: hexutil.Encode(entry.ProofGeneratorAddress[:]),
+		})
+	}
+	return cbor.Marshal(entries)
+}
+
+// BatchMerkleRoot computes the root of the merkle tree the batcher builds over the commitments of the proofs.
+// Leaves are padded to a power of two by repeating the last one.
+func BatchMerkleRoot(batch []BatchEntry) ([32]byte, error) {
+	if len(batch) == 0 {
+		return [32]byte{}, fmt.Errorf("empty batch")
+	}
+
+	nodes := make([][]byte, 0, len(batch))
+	for _, entry := range batch {
+		nodes = append(nodes, batchLeaf(entry))
+	}
+	for len(nodes)&(len(nodes)-1) != 0 {
+		nodes = append(nodes, nodes[len(nodes)-1])
+	}
+	for len(nodes) > 1 {
+		parents := make([][]byte, 0, len(nodes)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			parents = append(parents, crypto.Keccak256(nodes[i], nodes[i+1]))
+		}
+		nodes = parents
+	}
+	return [32]byte(nodes[0]), nil
+}
+
+// batchLeaf hashes the commitments of a proof, see VerificationDataCommitment in the aligned sdk
+func batchLeaf(entry BatchEntry) []byte {
+	proofCommitment := crypto.Keccak256(entry.Proof)
+
+	pubInputCommitment := make([]byte, 32)
+	if entry.PubInput != nil {
+		pubInputCommitment = crypto.Keccak256(entry.PubInput)
+	}
+
+	// The program code for the zkvms, or the verification key for the rest of the proving systems
+	auxDataCommitment := make([]byte, 32)
+	provingSystemByte := []byte{byte(entry.ProvingSystemId)}
+	if entry.VmProgramCode != nil {
+		auxDataCommitment = crypto.Keccak256(entry.VmProgramCode, provingSystemByte)
+	} else if entry.VerificationKey != nil {
+		auxDataCommitment = crypto.Keccak256(entry.VerificationKey, provingSystemByte)
+	}
+
+	return crypto.Keccak256(proofCommitment, pubInputCommitment, auxDataCommitment, entry.ProofGeneratorAddress[:])
+}
@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+const TestBatchMerkleRootFilePath = "../operator/merkle_tree/lib/test_files/merkle_root.bin"
+
+func TestBatchMerkleRootMatchesTheBatcher(t *testing.T) {
+	batch, err := LoadBatch(TestBatchFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHex, err := os.ReadFile(TestBatchMerkleRootFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merkleRoot, err := BatchMerkleRoot(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(merkleRoot[:]) != string(expectedRootHex) {
+		t.Errorf("expected merkle root %s, got %x", expectedRootHex, merkleRoot)
+	}
+}
+
+func TestEncodeBatchRoundTrip(t *testing.T) {
+	batch, err := LoadBatch(TestBatchFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchBytes, err := EncodeBatch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchFilePath := t.TempDir() + "/batch.bin"
+	if err := os.WriteFile(batchFilePath, batchBytes, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	decodedBatch, err := LoadBatch(batchFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRoot, _ := BatchMerkleRoot(batch)
+	decodedRoot, _ := BatchMerkleRoot(decodedBatch)
+	if expectedRoot != decodedRoot {
+		t.Errorf("expected merkle root %x after a round trip, got %x", expectedRoot, decodedRoot)
+	}
+}
@@ -0,0 +1,186 @@
+package e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	aggregator "github.com/yetanotherco/aligned_layer/aggregator/pkg"
+)
+
+const (
+	// Time a valid batch has to be responded in
+	responseTimeout = 2 * time.Minute
+	// Time a batch that must not reach quorum is watched for
+	noResponseWindow = 30 * time.Second
+)
+
+// The network is shared by the tests, as registering the operators takes a while
+var (
+	sharedNetwork     *Network
+	sharedNetworkErr  error
+	sharedNetworkOnce sync.Once
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if sharedNetwork != nil {
+		sharedNetwork.Close()
+	}
+	os.Exit(code)
+}
+
+// startNetwork returns the shared network with every operator online and a reliable RPC.
+// The test is skipped if anvil is not installed.
+func startNetwork(t *testing.T) *Network {
+	if testing.Short() {
+		t.Skip("skipping end to end test in short mode")
+	}
+	sharedNetworkOnce.Do(func() {
+		sharedNetwork, sharedNetworkErr = NewNetwork(NetworkParams{})
+	})
+	if errors.Is(sharedNetworkErr, ErrAnvilNotFound) {
+		t.Skip(sharedNetworkErr)
+	}
+	if sharedNetworkErr != nil {
+		t.Fatal(sharedNetworkErr)
+	}
+
+	sharedNetwork.RpcProxy.SetFailureRate(0)
+	for i := range sharedNetwork.Operators {
+		if err := sharedNetwork.StartOperator(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sharedNetwork
+}
+
+// newTestBatch returns a batch of valid proofs. It has a unique merkle root, so it can be submitted once per test
+func newTestBatch(t *testing.T, size int) []BatchEntry {
+	proofs, err := LoadBatch(TestBatchFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var proofGeneratorAddress ethcommon.Address
+	if _, err := rand.Read(proofGeneratorAddress[:]); err != nil {
+		t.Fatal(err)
+	}
+	batch := proofs[:size]
+	for i := range batch {
+		batch[i].ProofGeneratorAddress = proofGeneratorAddress
+	}
+	return batch
+}
+
+func TestQuorumReached(t *testing.T) {
+	network := startNetwork(t)
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+
+	batch, err := network.SubmitBatch(ctx, newTestBatch(t, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.WaitForResponse(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOperatorsOffline(t *testing.T) {
+	network := startNetwork(t)
+	// Every operator has the same stake, so the 67% quorum threshold is not reached without one of the three
+	network.StopOperator(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	batch, err := network.SubmitBatch(ctx, newTestBatch(t, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The online operators still sign the task
+	onlineOperators := len(network.Operators) - 1
+	status, err := network.WaitForTaskStatus(ctx, batch, func(status aggregator.TaskStatus) bool {
+		return len(status.SignersOperatorIds) == onlineOperators
+	})
+	if err != nil {
+		t.Fatalf("%v, signers: %v", err, status.SignersOperatorIds)
+	}
+	for _, operatorId := range status.SignersOperatorIds {
+		if operatorId == network.Operators[0].OperatorId {
+			t.Errorf("offline operator signed the task")
+		}
+	}
+
+	windowCtx, windowCancel := context.WithTimeout(context.Background(), noResponseWindow)
+	defer windowCancel()
+	err = network.WaitForResponse(windowCtx, batch)
+	if err == nil {
+		t.Fatal("batch was responded without the signature of an offline operator")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if status, _ := network.Aggregator.GetTaskStatusByBatchIdentifierHash(batch.BatchIdentifierHash); status.State != aggregator.TaskStatePending {
+		t.Errorf("expected the task to wait for more signatures, got state %s", status.State)
+	}
+}
+
+func TestInvalidProofs(t *testing.T) {
+	network := startNetwork(t)
+
+	proofs := newTestBatch(t, 4)
+	proofs[1].Proof[len(proofs[1].Proof)/2] ^= 0xff
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	batch, err := network.SubmitBatch(ctx, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every operator receives the batch before rejecting it
+	if err := network.WaitForDownloads(ctx, batch, len(network.Operators)); err != nil {
+		t.Fatal(err)
+	}
+
+	windowCtx, windowCancel := context.WithTimeout(context.Background(), noResponseWindow)
+	defer windowCancel()
+	err = network.WaitForResponse(windowCtx, batch)
+	if err == nil {
+		t.Fatal("batch with an invalid proof was responded")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	status, ok := network.Aggregator.GetTaskStatusByBatchIdentifierHash(batch.BatchIdentifierHash)
+	if !ok {
+		t.Fatal("aggregator did not receive the task")
+	}
+	if len(status.SignersOperatorIds) != 0 {
+		t.Errorf("expected every operator to reject the batch, got signatures of %v", status.SignersOperatorIds)
+	}
+}
+
+func TestRpcFailures(t *testing.T) {
+	network := startNetwork(t)
+	network.RpcProxy.SetFailureRate(0.2)
+	defer network.RpcProxy.SetFailureRate(0)
+	failuresBefore := network.RpcProxy.Failures()
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	batch, err := network.SubmitBatch(ctx, newTestBatch(t, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.WaitForResponse(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	if network.RpcProxy.Failures() == failuresBefore {
+		t.Error("no RPC request failed while the batch was processed")
+	}
+}
@@ -0,0 +1,666 @@
+/*
+Package e2e runs a whole Aligned deployment in process, to test the aggregator and the operators together.
+
+An anvil node is spawned from the devnet state, batches are served from an in-process data service, and the
+aggregator and the operators run in the test process, with generated keys. Every RPC request of the aggregator
+and the operators goes through an RpcProxy, which can fail a share of them.
+*/
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	contractIStrategy "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IStrategy"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	ecdsa2 "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/Layr-Labs/eigensdk-go/metrics"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	aggregator "github.com/yetanotherco/aligned_layer/aggregator/pkg"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	contractERC20Mock "github.com/yetanotherco/aligned_layer/contracts/bindings/ERC20Mock"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+	alignedmetrics "github.com/yetanotherco/aligned_layer/metrics"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+const (
+	AlignedLayerDeploymentFilePath = "../contracts/script/output/devnet/alignedlayer_deployment_output.json"
+	EigenLayerDeploymentFilePath   = "../contracts/script/output/devnet/eigenlayer_deployment_output.json"
+	// Keys of the aggregator address set in the devnet AlignedLayerServiceManager
+	AggregatorEcdsaKeyFilePath = "../config-files/anvil.aggregator.ecdsa.key.json"
+	AggregatorBlsKeyFilePath   = "../config-files/anvil.aggregator.bls.key.json"
+	// Owner of the devnet contracts, the first anvil account
+	DevnetOwnerPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+)
+
+var (
+	// Balance set to every generated account
+	AccountBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	// Mock tokens each operator deposits into the mock strategy, as make operator_full_registration does
+	OperatorStake = big.NewInt(100000000000000000)
+	// Deposited by the batcher to pay for the responses of its batches
+	BatcherDeposit = big.NewInt(1e18)
+	// Max fee the batcher pays for the response of each batch
+	RespondToTaskFeeLimit = big.NewInt(1e17)
+)
+
+const (
+	DefaultOperators   = 3
+	DefaultTaskTimeout = 2 * time.Minute
+	// Period the responses are polled at
+	responsePollInterval   = 500 * time.Millisecond
+	aggregatorStartTimeout = 30 * time.Second
+)
+
+// Functions of the devnet contracts without a binding in the repo
+const devnetAbi = `[
+	{"type":"function","name":"add_multiple","stateMutability":"nonpayable","inputs":[{"name":"_addresses","type":"address[]"}],"outputs":[]}
+]`
+
+type NetworkParams struct {
+	// Number of operators, they all have the same stake
+	Operators int
+	// Time the aggregator waits for the signatures of a task
+	TaskTimeout time.Duration
+}
+
+type Network struct {
+	Anvil       *Anvil
+	RpcProxy    *RpcProxy
+	BatchServer *BatchServer
+	Aggregator  *aggregator.Aggregator
+	Operators   []*OperatorNode
+
+	dir     string
+	chainId *big.Int
+	// Talks to anvil directly, so the harness is not affected by the failures of the proxy
+	client         *ethclient.Client
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager
+	ownerKey       *ecdsa.PrivateKey
+	batcherKey     *ecdsa.PrivateKey
+	cancel         context.CancelFunc
+}
+
+// OperatorNode is an operator of the network, which can be taken offline and back
+type OperatorNode struct {
+	Address        ethcommon.Address
+	OperatorId     eigentypes.OperatorId
+	ConfigFilePath string
+
+	mutex sync.Mutex
+	// Stops the running operator, nil while it is offline
+	cancel context.CancelFunc
+}
+
+// SubmittedBatch is a batch whose task was created in the AlignedLayerServiceManager
+type SubmittedBatch struct {
+	MerkleRoot          [32]byte
+	SenderAddress       ethcommon.Address
+	BatchIdentifierHash [32]byte
+}
+
+// NewNetwork spawns anvil, registers the operators and starts the aggregator and every operator.
+// It returns ErrAnvilNotFound if anvil is not installed.
+func NewNetwork(params NetworkParams) (*Network, error) {
+	if params.Operators == 0 {
+		params.Operators = DefaultOperators
+	}
+	if params.TaskTimeout == 0 {
+		params.TaskTimeout = DefaultTaskTimeout
+	}
+
+	anvil, err := StartAnvil()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	network := &Network{Anvil: anvil, BatchServer: NewBatchServer(), cancel: cancel}
+	if err := network.start(ctx, params); err != nil {
+		network.Close()
+		return nil, err
+	}
+	return network, nil
+}
+
+func (n *Network) start(ctx context.Context, params NetworkParams) error {
+	var err error
+	if n.dir, err = os.MkdirTemp("", "aligned-e2e"); err != nil {
+		return err
+	}
+	if n.RpcProxy, err = NewRpcProxy(n.Anvil.Url); err != nil {
+		return err
+	}
+	if n.client, err = ethclient.Dial(n.Anvil.Url); err != nil {
+		return err
+	}
+	if n.chainId, err = n.client.ChainID(ctx); err != nil {
+		return err
+	}
+	if n.ownerKey, err = crypto.HexToECDSA(DevnetOwnerPrivateKey); err != nil {
+		return err
+	}
+
+	alignedLayerDeploymentConfig, err := config.NewAlignedLayerDeploymentConfig(AlignedLayerDeploymentFilePath)
+	if err != nil {
+		return err
+	}
+	n.serviceManager, err = servicemanager.NewContractAlignedLayerServiceManager(alignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, n.client)
+	if err != nil {
+		return err
+	}
+
+	aggregatorAddress, err := freeAddress()
+	if err != nil {
+		return err
+	}
+
+	// Operators are registered before the aggregator starts, as it loads the operators public keys on start
+	for i := 0; i < params.Operators; i++ {
+		node, err := n.newOperatorNode(ctx, i, aggregatorAddress)
+		if err != nil {
+			return fmt.Errorf("error setting up operator %d: %w", i, err)
+		}
+		n.Operators = append(n.Operators, node)
+	}
+
+	if err := n.setUpBatcher(ctx); err != nil {
+		return fmt.Errorf("error setting up the batcher: %w", err)
+	}
+
+	if err := n.startAggregator(ctx, aggregatorAddress, params.TaskTimeout); err != nil {
+		return fmt.Errorf("error starting the aggregator: %w", err)
+	}
+
+	for i := range n.Operators {
+		if err := n.StartOperator(i); err != nil {
+			return fmt.Errorf("error starting operator %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Close stops every component of the network and removes its files
+func (n *Network) Close() {
+	for i := range n.Operators {
+		n.StopOperator(i)
+	}
+	n.cancel()
+	if n.RpcProxy != nil {
+		n.RpcProxy.Close()
+	}
+	n.BatchServer.Close()
+	if n.client != nil {
+		n.client.Close()
+	}
+	n.Anvil.Stop()
+	if n.dir != "" {
+		_ = os.RemoveAll(n.dir)
+	}
+}
+
+// StartOperator brings an operator online. It does nothing if the operator is already running.
+func (n *Network) StartOperator(i int) error {
+	node := n.Operators[i]
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	if node.cancel != nil {
+		return nil
+	}
+
+	operatorConfig, err := config.NewOperatorConfig(node.ConfigFilePath)
+	if err != nil {
+		return err
+	}
+	chainClients, err := operator.NewChainClientsFromConfig(operatorConfig.BaseConfig)
+	if err != nil {
+		operatorConfig.BaseConfig.Close()
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	op, err := operator.NewOperatorFromConfig(ctx, *operatorConfig, *chainClients)
+	if err != nil {
+		cancel()
+		operatorConfig.BaseConfig.Close()
+		return err
+	}
+	go func() {
+		defer operatorConfig.BaseConfig.Close()
+		if err := op.Start(ctx); err != nil {
+			op.Logger.Error("Operator stopped", "err", err)
+		}
+	}()
+	node.cancel = cancel
+	return nil
+}
+
+// StopOperator takes an operator offline. Batches it was verifying may still be signed.
+func (n *Network) StopOperator(i int) {
+	node := n.Operators[i]
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	if node.cancel != nil {
+		node.cancel()
+		node.cancel = nil
+	}
+}
+
+// SubmitBatch uploads a batch to the data service and creates its task, as the batcher does
+func (n *Network) SubmitBatch(ctx context.Context, batch []BatchEntry) (*SubmittedBatch, error) {
+	merkleRoot, err := BatchMerkleRoot(batch)
+	if err != nil {
+		return nil, err
+	}
+	batchBytes, err := EncodeBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+	batchDataPointer := n.BatchServer.Put(merkleRoot, batchBytes)
+
+	txOpts, err := n.transactOpts(ctx, n.batcherKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := n.serviceManager.CreateNewTask(txOpts, merkleRoot, batchDataPointer, RespondToTaskFeeLimit)
+	if err := n.waitMined(ctx, tx, err); err != nil {
+		return nil, fmt.Errorf("error creating task: %w", err)
+	}
+
+	senderAddress := crypto.PubkeyToAddress(n.batcherKey.PublicKey)
+	return &SubmittedBatch{
+		MerkleRoot:          merkleRoot,
+		SenderAddress:       senderAddress,
+		BatchIdentifierHash: crypto.Keccak256Hash(merkleRoot[:], senderAddress[:]),
+	}, nil
+}
+
+// IsResponded tells whether the aggregated response of a batch was accepted by the AlignedLayerServiceManager
+func (n *Network) IsResponded(ctx context.Context, batch *SubmittedBatch) (bool, error) {
+	batchState, err := n.serviceManager.BatchesState(&bind.CallOpts{Context: ctx}, batch.BatchIdentifierHash)
+	if err != nil {
+		return false, err
+	}
+	return batchState.Responded, nil
+}
+
+// WaitForResponse waits until the batch is responded, or returns the context error
+func (n *Network) WaitForResponse(ctx context.Context, batch *SubmittedBatch) error {
+	ticker := time.NewTicker(responsePollInterval)
+	defer ticker.Stop()
+	for {
+		responded, err := n.IsResponded(ctx, batch)
+		if err == nil && responded {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("batch 0x%x was not responded: %w", batch.MerkleRoot, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForTaskStatus waits until the aggregator holds a status of the batch task for which done returns true,
+// or returns the context error along with the last status seen
+func (n *Network) WaitForTaskStatus(ctx context.Context, batch *SubmittedBatch, done func(aggregator.TaskStatus) bool) (aggregator.TaskStatus, error) {
+	ticker := time.NewTicker(responsePollInterval)
+	defer ticker.Stop()
+	for {
+		status, ok := n.Aggregator.GetTaskStatusByBatchIdentifierHash(batch.BatchIdentifierHash)
+		if ok && done(status) {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("task of batch 0x%x did not reach the expected status: %w", batch.MerkleRoot, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForDownloads waits until the batch was served at least downloads times, or returns the context error
+func (n *Network) WaitForDownloads(ctx context.Context, batch *SubmittedBatch, downloads int) error {
+	ticker := time.NewTicker(responsePollInterval)
+	defer ticker.Stop()
+	for n.BatchServer.Downloads(batch.MerkleRoot) < downloads {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("batch 0x%x was downloaded %d times out of %d: %w", batch.MerkleRoot,
+				n.BatchServer.Downloads(batch.MerkleRoot), downloads, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// newOperatorNode generates the keys and config of an operator, and registers it in EigenLayer and Aligned
+func (n *Network) newOperatorNode(ctx context.Context, i int, aggregatorAddress string) (*OperatorNode, error) {
+	ecdsaKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKeyFilePath := filepath.Join(n.dir, fmt.Sprintf("operator-%d.ecdsa.key.json", i))
+	if err := ecdsa2.WriteKey(ecdsaKeyFilePath, ecdsaKey, ""); err != nil {
+		return nil, err
+	}
+	blsKeyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		return nil, err
+	}
+	blsKeyFilePath := filepath.Join(n.dir, fmt.Sprintf("operator-%d.bls.key.json", i))
+	if err := blsKeyPair.SaveToFile(blsKeyFilePath, ""); err != nil {
+		return nil, err
+	}
+
+	address := crypto.PubkeyToAddress(ecdsaKey.PublicKey)
+	node := &OperatorNode{
+		Address:        address,
+		OperatorId:     eigentypes.OperatorIdFromKeyPair(blsKeyPair),
+		ConfigFilePath: filepath.Join(n.dir, fmt.Sprintf("config-operator-%d.yaml", i)),
+	}
+	operatorConfig := n.baseConfigYaml(ecdsaKeyFilePath, blsKeyFilePath) + fmt.Sprintf(`
+operator:
+  aggregator_rpc_server_ip_port_address: %s
+  address: %s
+  earnings_receiver_address: %s
+  delegation_approver_address: '0x0000000000000000000000000000000000000000'
+  staker_opt_out_window_blocks: 0
+  metadata_url: 'https://yetanotherco.github.io/operator_metadata/metadata.json'
+  enable_metrics: false
+  max_batch_size: 268435456
+  last_processed_batch_filepath: '%s'
+`, aggregatorAddress, address.Hex(), address.Hex(), filepath.Join(n.dir, fmt.Sprintf("operator-%d.last_processed_batch.json", i)))
+	if err := os.WriteFile(node.ConfigFilePath, []byte(operatorConfig), 0o644); err != nil {
+		return nil, err
+	}
+
+	if err := n.setBalance(ctx, address, AccountBalance); err != nil {
+		return nil, err
+	}
+	if err := n.registerOperator(ctx, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// registerOperator runs the steps of make operator_full_registration
+func (n *Network) registerOperator(ctx context.Context, node *OperatorNode) error {
+	operatorConfig, err := config.NewOperatorConfig(node.ConfigFilePath)
+	if err != nil {
+		return err
+	}
+	defer operatorConfig.BaseConfig.Close()
+	ecdsaConfig, err := config.NewEcdsaConfig(node.ConfigFilePath, operatorConfig.BaseConfig.ChainId)
+	if err != nil {
+		return err
+	}
+	baseConfig := operatorConfig.BaseConfig
+
+	signerFn, _, err := signerv2.SignerFromConfig(signerv2.Config{PrivateKey: ecdsaConfig.PrivateKey}, baseConfig.ChainId)
+	if err != nil {
+		return err
+	}
+	w, err := wallet.NewPrivateKeyWallet(baseConfig.EthRpcPool, signerFn, node.Address, baseConfig.Logger)
+	if err != nil {
+		return err
+	}
+	txMgr := txmgr.NewSimpleTxManager(w, baseConfig.EthRpcPool, baseConfig.Logger, node.Address)
+	eigenLayerWriter, err := elcontracts.BuildELChainWriter(baseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr,
+		baseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr, baseConfig.EthRpcPool, baseConfig.Logger, metrics.NewNoopMetrics(), txMgr)
+	if err != nil {
+		return err
+	}
+
+	_, err = eigenLayerWriter.RegisterAsOperator(ctx, eigentypes.Operator{
+		Address:                   node.Address.Hex(),
+		DelegationApproverAddress: operatorConfig.Operator.DelegationApproverAddress.Hex(),
+		StakerOptOutWindowBlocks:  uint32(operatorConfig.Operator.StakerOptOutWindowBlocks),
+		MetadataUrl:               operatorConfig.Operator.MetadataUrl,
+	}, true)
+	if err != nil {
+		return fmt.Errorf("error registering in EigenLayer: %w", err)
+	}
+
+	strategyAddr, err := mockStrategyAddress()
+	if err != nil {
+		return err
+	}
+	if err := n.mintMockTokens(ctx, strategyAddr, node.Address, OperatorStake); err != nil {
+		return fmt.Errorf("error minting mock tokens: %w", err)
+	}
+	if _, err := eigenLayerWriter.DepositERC20IntoStrategy(ctx, strategyAddr, OperatorStake, true); err != nil {
+		return fmt.Errorf("error depositing into the mock strategy: %w", err)
+	}
+
+	if err := n.whitelistOperator(ctx, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr, node.Address); err != nil {
+		return fmt.Errorf("error whitelisting: %w", err)
+	}
+
+	var salt [32]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return err
+	}
+	return operator.RegisterOperator(ctx, operatorConfig, ecdsaConfig, salt)
+}
+
+func (n *Network) setUpBatcher(ctx context.Context) error {
+	var err error
+	if n.batcherKey, err = crypto.GenerateKey(); err != nil {
+		return err
+	}
+	batcherAddress := crypto.PubkeyToAddress(n.batcherKey.PublicKey)
+	if err := n.setBalance(ctx, batcherAddress, AccountBalance); err != nil {
+		return err
+	}
+
+	txOpts, err := n.transactOpts(ctx, n.batcherKey)
+	if err != nil {
+		return err
+	}
+	txOpts.Value = BatcherDeposit
+	tx, err := n.serviceManager.DepositToBatcher(txOpts, batcherAddress)
+	return n.waitMined(ctx, tx, err)
+}
+
+func (n *Network) startAggregator(ctx context.Context, aggregatorAddress string, taskTimeout time.Duration) error {
+	ecdsaKeyFilePath, err := filepath.Abs(AggregatorEcdsaKeyFilePath)
+	if err != nil {
+		return err
+	}
+	blsKeyFilePath, err := filepath.Abs(AggregatorBlsKeyFilePath)
+	if err != nil {
+		return err
+	}
+	configFilePath := filepath.Join(n.dir, "config-aggregator.yaml")
+	aggregatorConfigYaml := n.baseConfigYaml(ecdsaKeyFilePath, blsKeyFilePath) + fmt.Sprintf(`
+aggregator:
+  server_ip_port_address: %s
+  enable_metrics: false
+  telemetry_ip_port_address: localhost:1
+  garbage_collector_period: 2m
+  garbage_collector_tasks_age: 20
+  garbage_collector_tasks_interval: 10
+  bls_service_task_timeout: %s
+  gas_base_bump_percentage: 25
+  gas_bump_incremental_percentage: 20
+  gas_bump_percentage_limit: 150
+  time_to_wait_before_bump: 5s
+`, aggregatorAddress, taskTimeout)
+	if err := os.WriteFile(configFilePath, []byte(aggregatorConfigYaml), 0o644); err != nil {
+		return err
+	}
+
+	aggregatorConfig, err := config.NewAggregatorConfig(configFilePath)
+	if err != nil {
+		return err
+	}
+	logger := aggregatorConfig.BaseConfig.Logger
+	reg := prometheus.NewRegistry()
+	aggregatorMetrics := alignedmetrics.NewMetrics(aggregatorConfig.Aggregator.MetricsIpPortAddress, reg, logger)
+	chainClients, err := aggregator.NewChainClientsFromConfig(*aggregatorConfig, aggregatorMetrics)
+	if err != nil {
+		return err
+	}
+	n.Aggregator, err = aggregator.NewAggregator(*aggregatorConfig, *chainClients, reg, aggregatorMetrics)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := n.Aggregator.SubscribeToNewTasks(ctx); err != nil {
+			logger.Error("Aggregator stopped listening for new tasks", "err", err)
+		}
+	}()
+	go func() {
+		if err := n.Aggregator.Start(ctx); err != nil {
+			logger.Error("Aggregator stopped", "err", err)
+		}
+	}()
+
+	// Operators connect to the aggregator when they are created
+	deadline := time.Now().Add(aggregatorStartTimeout)
+	for {
+		conn, err := net.Dial("tcp", aggregatorAddress)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("aggregator is not listening on %s: %w", aggregatorAddress, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// baseConfigYaml returns the config shared by the aggregator and the operators.
+// Events are polled over http, so every request goes through the RpcProxy
+func (n *Network) baseConfigYaml(ecdsaKeyFilePath string, blsKeyFilePath string) string {
+	alignedLayerDeploymentFilePath, _ := filepath.Abs(AlignedLayerDeploymentFilePath)
+	eigenLayerDeploymentFilePath, _ := filepath.Abs(EigenLayerDeploymentFilePath)
+	return strings.TrimLeft(fmt.Sprintf(`
+environment: 'production'
+aligned_layer_deployment_config_file_path: '%s'
+eigen_layer_deployment_config_file_path: '%s'
+eth_rpc_url: '%s'
+event_source: 'http'
+event_poll_interval: 500ms
+eth_health_check_period: 1s
+eigen_metrics_ip_port_address: 'localhost:0'
+new_batch_confirmation_depth: 0
+
+ecdsa:
+  private_key_store_path: '%s'
+  private_key_store_password: ''
+
+bls:
+  private_key_store_path: '%s'
+  private_key_store_password: ''
+`, alignedLayerDeploymentFilePath, eigenLayerDeploymentFilePath, n.RpcProxy.Url(), ecdsaKeyFilePath, blsKeyFilePath), "\n")
+}
+
+func (n *Network) setBalance(ctx context.Context, address ethcommon.Address, balance *big.Int) error {
+	return n.client.Client().CallContext(ctx, nil, "anvil_setBalance", address, hexutil.EncodeBig(balance))
+}
+
+func (n *Network) mintMockTokens(ctx context.Context, strategyAddr ethcommon.Address, to ethcommon.Address, amount *big.Int) error {
+	strategy, err := contractIStrategy.NewContractIStrategy(strategyAddr, n.client)
+	if err != nil {
+		return err
+	}
+	tokenAddr, err := strategy.UnderlyingToken(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return err
+	}
+	token, err := contractERC20Mock.NewContractERC20Mock(tokenAddr, n.client)
+	if err != nil {
+		return err
+	}
+	txOpts, err := n.transactOpts(ctx, n.ownerKey)
+	if err != nil {
+		return err
+	}
+	tx, err := token.Mint(txOpts, to, amount)
+	return n.waitMined(ctx, tx, err)
+}
+
+// whitelistOperator adds the operator to the whitelist of the devnet registry coordinator
+func (n *Network) whitelistOperator(ctx context.Context, registryCoordinatorAddr ethcommon.Address, address ethcommon.Address) error {
+	parsedAbi, err := abi.JSON(strings.NewReader(devnetAbi))
+	if err != nil {
+		return err
+	}
+	registryCoordinator := bind.NewBoundContract(registryCoordinatorAddr, parsedAbi, n.client, n.client, n.client)
+	txOpts, err := n.transactOpts(ctx, n.ownerKey)
+	if err != nil {
+		return err
+	}
+	tx, err := registryCoordinator.Transact(txOpts, "add_multiple", []ethcommon.Address{address})
+	return n.waitMined(ctx, tx, err)
+}
+
+func (n *Network) transactOpts(ctx context.Context, key *ecdsa.PrivateKey) (*bind.TransactOpts, error) {
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, n.chainId)
+	if err != nil {
+		return nil, err
+	}
+	txOpts.Context = ctx
+	return txOpts, nil
+}
+
+// waitMined waits for the receipt of a transaction, taking the error of sending it
+func (n *Network) waitMined(ctx context.Context, tx *gethtypes.Transaction, err error) error {
+	if err != nil {
+		return err
+	}
+	receipt, err := bind.WaitMined(ctx, n.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return nil
+}
+
+func mockStrategyAddress() (ethcommon.Address, error) {
+	var deployment struct {
+		Addresses struct {
+			Strategies struct {
+				Mock ethcommon.Address `json:"MOCK"`
+			} `json:"strategies"`
+		} `json:"addresses"`
+	}
+	if err := utils.ReadJsonConfig(EigenLayerDeploymentFilePath, &deployment); err != nil {
+		return ethcommon.Address{}, err
+	}
+	return deployment.Addresses.Strategies.Mock, nil
+}
+
+// freeAddress returns a localhost address that is free to listen on
+func freeAddress() (string, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("localhost:%d", port), nil
+}
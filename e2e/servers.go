@@ -0,0 +1,115 @@
+package e2e
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchServer stands in for the data service the batcher uploads batches to
+type BatchServer struct {
+	server  *httptest.Server
+	mutex   sync.Mutex
+	batches map[string][]byte
+	// Number of times each batch was served
+	downloads map[string]int
+}
+
+func NewBatchServer() *BatchServer {
+	batchServer := &BatchServer{batches: make(map[string][]byte), downloads: make(map[string]int)}
+	batchServer.server = httptest.NewServer(http.HandlerFunc(batchServer.serveBatch))
+	return batchServer
+}
+
+// Put stores a serialized batch and returns its url, to be used as batch data pointer
+func (s *BatchServer) Put(merkleRoot [32]byte, batchBytes []byte) string {
+	path := batchPath(merkleRoot)
+	s.mutex.Lock()
+	s.batches[path] = batchBytes
+	s.mutex.Unlock()
+	return s.server.URL + path
+}
+
+// Downloads returns the number of times a batch was served, that is, received by an operator
+func (s *BatchServer) Downloads(merkleRoot [32]byte) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.downloads[batchPath(merkleRoot)]
+}
+
+func (s *BatchServer) Close() {
+	s.server.Close()
+}
+
+func (s *BatchServer) serveBatch(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	batchBytes, ok := s.batches[r.URL.Path]
+	if ok {
+		s.downloads[r.URL.Path]++
+	}
+	s.mutex.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Operators limit the download to the content length
+	w.Header().Set("Content-Length", strconv.Itoa(len(batchBytes)))
+	_, _ = w.Write(batchBytes)
+}
+
+func batchPath(merkleRoot [32]byte) string {
+	return "/" + hex.EncodeToString(merkleRoot[:]) + ".json"
+}
+
+// RpcProxy forwards the requests of the aggregator and the operators to the node, failing a share of them
+// before they reach the node, as an unreliable RPC provider would
+type RpcProxy struct {
+	server *httptest.Server
+	proxy  *httputil.ReverseProxy
+	// Probability that a request fails, in units of 1/2^32
+	failureRate atomic.Uint64
+	failures    atomic.Uint64
+}
+
+func NewRpcProxy(target string) (*RpcProxy, error) {
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	rpcProxy := &RpcProxy{proxy: httputil.NewSingleHostReverseProxy(targetUrl)}
+	rpcProxy.server = httptest.NewServer(http.HandlerFunc(rpcProxy.serveRpc))
+	return rpcProxy, nil
+}
+
+func (p *RpcProxy) Url() string {
+	return p.server.URL
+}
+
+// SetFailureRate sets the probability, between 0 and 1, that a request fails with a 503
+func (p *RpcProxy) SetFailureRate(rate float64) {
+	p.failureRate.Store(uint64(rate * (1 << 32)))
+}
+
+// Failures returns the number of requests failed so far
+func (p *RpcProxy) Failures() uint64 {
+	return p.failures.Load()
+}
+
+func (p *RpcProxy) Close() {
+	p.server.Close()
+}
+
+func (p *RpcProxy) serveRpc(w http.ResponseWriter, r *http.Request) {
+	if uint64(rand.Int63n(1<<32)) < p.failureRate.Load() {
+		p.failures.Add(1)
+		http.Error(w, "injected failure", http.StatusServiceUnavailable)
+		return
+	}
+	p.proxy.ServeHTTP(w, r)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	retry "github.com/yetanotherco/aligned_layer/core"
 )
 
 type Metrics struct {
@@ -21,6 +22,19 @@ type Metrics struct {
 	aggregatorGasCostPaidForBatcherTotal   prometheus.Gauge
 	aggregatorNumTimesPaidForBatcher       prometheus.Counter
 	numBumpedGasPriceForAggregatedResponse prometheus.Counter
+	numResentAggregatedResponses           prometheus.Counter
+	operatorTasksSigned                    *prometheus.CounterVec
+	operatorTasksMissed                    *prometheus.GaugeVec
+	operatorLateSignatures                 *prometheus.CounterVec
+	operatorMedianResponseLatency          *prometheus.GaugeVec
+	verifierDisabled                       *prometheus.GaugeVec
+	operatorSkippedBatches                 *prometheus.CounterVec
+	operatorSkippedProofs                  prometheus.Counter
+	aggregatorReleasedTasks                prometheus.Counter
+	retryAttempts                          *prometheus.CounterVec
+	retryAttemptDuration                   *prometheus.HistogramVec
+	retryGiveUps                           *prometheus.CounterVec
+	circuitBreakerState                    *prometheus.GaugeVec
 }
 
 const alignedNamespace = "aligned"
@@ -59,6 +73,72 @@ func NewMetrics(ipPortAddress string, reg prometheus.Registerer, logger logging.
 			Name:      "respond_to_task_gas_price_bumped",
 			Help:      "Number of times gas price was bumped while sending aggregated response",
 		}),
+		numResentAggregatedResponses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregated_responses_resent",
+			Help:      "Number of aggregated responses sent again because the previous transaction was dropped by a reorg",
+		}),
+		operatorTasksSigned: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_tasks_signed",
+			Help:      "Number of tasks signed by each operator, as seen by the aggregator",
+		}, []string{"operator_id"}),
+		operatorTasksMissed: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_tasks_missed",
+			Help:      "Number of tasks each operator did not sign, leaving out the ones it signed late",
+		}, []string{"operator_id"}),
+		operatorLateSignatures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_late_signatures",
+			Help:      "Number of signatures each operator sent after the task had already reached quorum",
+		}, []string{"operator_id"}),
+		operatorMedianResponseLatency: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_median_response_latency_seconds",
+			Help:      "Median time between the NewBatchV3 event and the operator signature, over the last responses",
+		}, []string{"operator_id"}),
+		verifierDisabled: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "verifier_disabled",
+			Help:      "Whether the verifier of each proving system is disabled in the Aligned Service Manager (1) or not (0)",
+		}, []string{"proving_system"}),
+		operatorSkippedBatches: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_skipped_batches",
+			Help:      "Number of batches the operator stopped processing, because they were removed by a reorg or already verified",
+		}, []string{"reason"}),
+		operatorSkippedProofs: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_skipped_proofs",
+			Help:      "Number of proofs not verified by the operator because their batch processing was cancelled",
+		}),
+		aggregatorReleasedTasks: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_released_tasks",
+			Help:      "Number of tasks dropped by the aggregator because the batch was responded on-chain before reaching quorum",
+		}),
+		retryAttempts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "retry_attempts",
+			Help:      "Number of attempts of each retried call, by whether the attempt was the first one or a retry and whether it failed",
+		}, []string{"call", "attempt", "result"}),
+		retryAttemptDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "retry_attempt_duration_seconds",
+			Help:      "Duration of each attempt of the retried calls",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"call"}),
+		retryGiveUps: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "retry_give_ups",
+			Help:      "Number of retried calls that failed for good, after a permanent error or after running out of retries",
+		}, []string{"call"}),
+		circuitBreakerState: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "rpc_circuit_breaker_state",
+			Help:      "State of the circuit breaker of each RPC endpoint: closed (0), half-open (1) or open (2)",
+		}, []string{"endpoint"}),
 	}
 }
 
@@ -113,6 +193,71 @@ func (m *Metrics) AddAggregatorGasPaidForBatcher(value float64) {
 	m.aggregatorGasCostPaidForBatcherTotal.Add(value)
 }
 
+func (m *Metrics) IncResentAggregatedResponses() {
+	m.numResentAggregatedResponses.Inc()
+}
+
 func (m *Metrics) IncBumpedGasPriceForAggregatedResponse() {
 	m.numBumpedGasPriceForAggregatedResponse.Inc()
 }
+
+func (m *Metrics) IncOperatorTasksSigned(operatorId string) {
+	m.operatorTasksSigned.WithLabelValues(operatorId).Inc()
+}
+
+func (m *Metrics) SetOperatorTasksMissed(operatorId string, tasksMissed uint64) {
+	m.operatorTasksMissed.WithLabelValues(operatorId).Set(float64(tasksMissed))
+}
+
+func (m *Metrics) IncOperatorLateSignatures(operatorId string) {
+	m.operatorLateSignatures.WithLabelValues(operatorId).Inc()
+}
+
+func (m *Metrics) SetOperatorMedianResponseLatency(operatorId string, seconds float64) {
+	m.operatorMedianResponseLatency.WithLabelValues(operatorId).Set(seconds)
+}
+
+func (m *Metrics) SetVerifierDisabled(provingSystem string, disabled bool) {
+	value := 0.0
+	if disabled {
+		value = 1.0
+	}
+	m.verifierDisabled.WithLabelValues(provingSystem).Set(value)
+}
+
+func (m *Metrics) IncOperatorSkippedBatches(reason string) {
+	m.operatorSkippedBatches.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) IncOperatorSkippedProofs() {
+	m.operatorSkippedProofs.Inc()
+}
+
+func (m *Metrics) IncAggregatorReleasedTasks() {
+	m.aggregatorReleasedTasks.Inc()
+}
+
+// ObserveRetryAttempt, ObserveRetryGiveUp and ObserveCircuitState implement the retry Observer, so the retried calls
+// of the process are reported by call name and the circuit breakers by endpoint
+func (m *Metrics) ObserveRetryAttempt(name string, attempt uint64, duration time.Duration, err error) {
+	attemptLabel := "first"
+	if attempt > 1 {
+		attemptLabel = "retry"
+	}
+	result := "success"
+	if errors.Is(err, retry.ErrRetryBudgetExhausted) {
+		result = "budget_exhausted"
+	} else if err != nil {
+		result = "error"
+	}
+	m.retryAttempts.WithLabelValues(name, attemptLabel, result).Inc()
+	m.retryAttemptDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveRetryGiveUp(name string, attempts uint64, err error) {
+	m.retryGiveUps.WithLabelValues(name).Inc()
+}
+
+func (m *Metrics) ObserveCircuitState(name string, state retry.CircuitState) {
+	m.circuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
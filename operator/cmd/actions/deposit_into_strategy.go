@@ -32,7 +32,8 @@ var (
 
 var DepositIntoStrategyCommand = &cli.Command{
 	Name:        "deposit-into-strategy",
-	Description: "CLI command to deposit into a given strategy",
+	Usage:       "Deposit an amount in the smallest unit of the token into a strategy, see staking deposit",
+	Description: "CLI command to deposit into a given strategy. Kept for existing scripts, staking deposit takes amounts with decimals, checks the allowance and simulates the transactions",
 	Flags:       depositFlags,
 	Action:      depositIntoStrategyMain,
 }
@@ -50,8 +51,14 @@ func depositIntoStrategyMain(ctx *cli.Context) error {
 		return nil
 	}
 
-	opConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
-	ecdsaConfig := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), opConfig.BaseConfig.ChainId)
+	opConfig, err := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	ecdsaConfig, err := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), opConfig.BaseConfig.ChainId)
+	if err != nil {
+		return err
+	}
 	strategyAddressStr := ctx.String(StrategyAddressFlag.Name)
 	if strategyAddressStr == "" {
 		log.Println("Strategy address is required")
@@ -70,18 +77,18 @@ func depositIntoStrategyMain(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	w, err := wallet.NewPrivateKeyWallet(&opConfig.BaseConfig.EthRpcClient, signerFn,
+	w, err := wallet.NewPrivateKeyWallet(opConfig.BaseConfig.EthRpcPool, signerFn,
 		opConfig.Operator.Address, opConfig.BaseConfig.Logger)
 
 	if err != nil {
 		return err
 	}
 
-	txMgr := txmgr.NewSimpleTxManager(w, &opConfig.BaseConfig.EthRpcClient, opConfig.BaseConfig.Logger,
+	txMgr := txmgr.NewSimpleTxManager(w, opConfig.BaseConfig.EthRpcPool, opConfig.BaseConfig.Logger,
 		opConfig.Operator.Address)
 	eigenMetrics := metrics.NewNoopMetrics()
 	eigenLayerWriter, err := elcontracts.BuildELChainWriter(delegationManagerAddr, avsDirectoryAddr,
-		&opConfig.BaseConfig.EthRpcClient, opConfig.BaseConfig.Logger, eigenMetrics, txMgr)
+		opConfig.BaseConfig.EthRpcPool, opConfig.BaseConfig.Logger, eigenMetrics, txMgr)
 	if err != nil {
 		return err
 	}
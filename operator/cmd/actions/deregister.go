@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	YesFlag = &cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Do not ask for confirmation",
+	}
+)
+
+var deregisterFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	YesFlag,
+}
+
+var DeregisterCommand = &cli.Command{
+	Name:        "deregister",
+	Usage:       "Deregister operator from Aligned Layer",
+	Description: "Removes the operator from the quorums of Aligned Layer through the registry coordinator, which also deregisters it from the AVS. The operator stops receiving tasks",
+	Flags:       deregisterFlags,
+	Action:      deregisterOperatorMain,
+}
+
+func deregisterOperatorMain(ctx *cli.Context) error {
+	operatorConfig, err := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	ecdsaConfig, err := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
+	if err != nil {
+		return err
+	}
+
+	if err := confirm(ctx, fmt.Sprintf("Deregister operator %s from Aligned Layer?", operatorConfig.Operator.Address)); err != nil {
+		return err
+	}
+
+	err = operator.DeregisterOperator(context.Background(), operatorConfig, ecdsaConfig)
+	if err != nil {
+		operatorConfig.BaseConfig.Logger.Error("Failed to deregister operator", "err", err)
+		return err
+	}
+	operatorConfig.BaseConfig.Logger.Info("Operator deregistered", "address", operatorConfig.Operator.Address)
+
+	return nil
+}
+
+// confirm asks the question on the terminal unless --yes is set, and returns an error if it is not confirmed
+func confirm(ctx *cli.Context, question string) error {
+	if ctx.Bool(YesFlag.Name) {
+		return nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("stdin is not a terminal to ask for confirmation, set --%s", YesFlag.Name)
+	}
+	confirmed, err := prompt.Stdin.PromptConfirm(question)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errors.New("cancelled")
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/keys"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+var (
+	keyTypeFlag = &cli.StringFlag{
+		Name:     "key-type",
+		Required: true,
+		Usage:    "Type of the key, 'bls' or 'ecdsa'",
+	}
+	keyStoreFlag = &cli.StringFlag{
+		Name:     "key-store",
+		Required: true,
+		Usage:    "Path of the key store `FILE`",
+	}
+	passwordFileFlag = &cli.StringFlag{
+		Name:  "password-file",
+		Usage: "Read the password of the key store from `FILE` instead of prompting for it",
+	}
+	newPasswordFileFlag = &cli.StringFlag{
+		Name:  "new-password-file",
+		Usage: "Read the new password of the key store from `FILE` instead of prompting for it",
+	}
+	privateKeyFileFlag = &cli.StringFlag{
+		Name:  "private-key-file",
+		Usage: "Read the private key to import from `FILE` instead of prompting for it",
+	}
+)
+
+var KeysCommand = &cli.Command{
+	Name:  "keys",
+	Usage: "Manage the BLS and ECDSA key stores of the operator",
+	Description: "Key stores are written in the format the operator and the EigenLayer CLI read. " +
+		"Passwords and private keys are prompted for, or read from files with the --*-file flags",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "generate",
+			Usage:  "Generate a new key and save it in a new key store",
+			Flags:  []cli.Flag{keyTypeFlag, keyStoreFlag, passwordFileFlag},
+			Action: generateKeyMain,
+		},
+		{
+			Name:   "import",
+			Usage:  "Save an existing private key in a new key store",
+			Flags:  []cli.Flag{keyTypeFlag, keyStoreFlag, passwordFileFlag, privateKeyFileFlag},
+			Action: importKeyMain,
+		},
+		{
+			Name:   "export",
+			Usage:  "Print the private key of a key store",
+			Flags:  []cli.Flag{keyTypeFlag, keyStoreFlag, passwordFileFlag},
+			Action: exportKeyMain,
+		},
+		{
+			Name:   "show",
+			Usage:  "Print the public keys of a key store: the address for ECDSA, the G1 and G2 points and the operator id for BLS",
+			Flags:  []cli.Flag{keyTypeFlag, keyStoreFlag, passwordFileFlag},
+			Action: showKeyMain,
+		},
+		{
+			Name:   "change-password",
+			Usage:  "Encrypt a key store with a new password",
+			Flags:  []cli.Flag{keyTypeFlag, keyStoreFlag, passwordFileFlag, newPasswordFileFlag},
+			Action: changeKeyPasswordMain,
+		},
+	},
+}
+
+func generateKeyMain(ctx *cli.Context) error {
+	keyType, err := keys.ParseKeyType(ctx.String(keyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	key, err := keys.Generate(keyType)
+	if err != nil {
+		return err
+	}
+	return writeKeyStore(ctx, key)
+}
+
+func importKeyMain(ctx *cli.Context) error {
+	keyType, err := keys.ParseKeyType(ctx.String(keyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	privateKey, err := readSecret(ctx, privateKeyFileFlag, fmt.Sprintf("%s private key: ", keyType), false)
+	if err != nil {
+		return err
+	}
+	key, err := keys.ParsePrivateKey(keyType, privateKey)
+	if err != nil {
+		return err
+	}
+	return writeKeyStore(ctx, key)
+}
+
+func exportKeyMain(ctx *cli.Context) error {
+	key, err := readKeyStore(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Anyone with this private key controls the operator, keep it secret")
+	fmt.Println(key.PrivateKey())
+	return nil
+}
+
+func showKeyMain(ctx *cli.Context) error {
+	key, err := readKeyStore(ctx)
+	if err != nil {
+		return err
+	}
+	printPublicKeys(ctx.String(keyStoreFlag.Name), key)
+	return nil
+}
+
+func changeKeyPasswordMain(ctx *cli.Context) error {
+	keyType, err := keys.ParseKeyType(ctx.String(keyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	password, err := readSecret(ctx, passwordFileFlag, "Current password: ", false)
+	if err != nil {
+		return err
+	}
+	newPassword, err := readSecret(ctx, newPasswordFileFlag, "New password: ", true)
+	if err != nil {
+		return err
+	}
+	keyStorePath := ctx.String(keyStoreFlag.Name)
+	if err := keys.ChangePassword(keyType, keyStorePath, password, newPassword); err != nil {
+		return err
+	}
+	fmt.Printf("Changed the password of %s\n", keyStorePath)
+	return nil
+}
+
+func readKeyStore(ctx *cli.Context) (*keys.Key, error) {
+	keyType, err := keys.ParseKeyType(ctx.String(keyTypeFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	password, err := readSecret(ctx, passwordFileFlag, "Password: ", false)
+	if err != nil {
+		return nil, err
+	}
+	return keys.ReadKeyStore(keyType, ctx.String(keyStoreFlag.Name), password)
+}
+
+func writeKeyStore(ctx *cli.Context, key *keys.Key) error {
+	password, err := readSecret(ctx, passwordFileFlag, "Password of the new key store: ", true)
+	if err != nil {
+		return err
+	}
+	keyStorePath := ctx.String(keyStoreFlag.Name)
+	if err := key.WriteKeyStore(keyStorePath, password); err != nil {
+		return err
+	}
+	printPublicKeys(keyStorePath, key)
+	return nil
+}
+
+func printPublicKeys(keyStorePath string, key *keys.Key) {
+	publicKeys := key.PublicKeys()
+	fmt.Printf("Key store: %s\n", keyStorePath)
+	if key.Type == keys.KeyTypeEcdsa {
+		fmt.Printf("Address: %s\n", publicKeys.Address.Hex())
+		return
+	}
+	fmt.Printf("G1 public key:\n  X: %s\n  Y: %s\n", publicKeys.G1.X.String(), publicKeys.G1.Y.String())
+	fmt.Printf("G2 public key:\n  X: [%s, %s]\n  Y: [%s, %s]\n",
+		publicKeys.G2.X.A0.String(), publicKeys.G2.X.A1.String(), publicKeys.G2.Y.A0.String(), publicKeys.G2.Y.A1.String())
+	fmt.Printf("Operator id: 0x%x\n", publicKeys.OperatorId[:])
+}
+
+// readSecret reads a password or private key from the file of the flag, or prompts for it if stdin is a terminal,
+// twice when confirm is set
+func readSecret(ctx *cli.Context, fileFlag *cli.StringFlag, promptText string, confirm bool) (string, error) {
+	if ctx.IsSet(fileFlag.Name) {
+		b, err := utils.ReadFile(ctx.String(fileFlag.Name))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("stdin is not a terminal to prompt in, set --%s", fileFlag.Name)
+	}
+	secret, err := prompt.Stdin.PromptPassword(promptText)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		repeated, err := prompt.Stdin.PromptPassword("Repeat it: ")
+		if err != nil {
+			return "", err
+		}
+		if repeated != secret {
+			return "", errors.New("the values entered do not match")
+		}
+	}
+	return secret, nil
+}
@@ -2,12 +2,43 @@ package actions
 
 import (
 	"context"
-	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/keys"
+	"github.com/yetanotherco/aligned_layer/core/registration"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	payloadFlag = &cli.StringFlag{
+		Name:     "payload",
+		Required: true,
+		Usage:    "Path of the registration payload `FILE`",
+	}
+	saltFlag = &cli.StringFlag{
+		Name:  "salt",
+		Usage: "32 bytes hex `SALT` of the registration signature, random by default",
+	}
+	validForFlag = &cli.DurationFlag{
+		Name:  "valid-for",
+		Value: 24 * time.Hour,
+		Usage: "Time the payload can be signed and submitted in",
+	}
+	gasLimitFlag = &cli.Uint64Flag{
+		Name:  "gas-limit",
+		Value: registration.DefaultGasLimit,
+		Usage: "Gas limit of the registration transaction",
+	}
 )
 
 var registerFlags = []cli.Flag{
@@ -17,14 +48,43 @@ var registerFlags = []cli.Flag{
 var RegisterCommand = &cli.Command{
 	Name:        "register",
 	Usage:       "Register operator with Aligned Layer",
-	Description: "CLI command to register opeartor with Aligned Layer",
+	Description: "CLI command to register opeartor with Aligned Layer. Without a subcommand, the registration is signed with the ECDSA key of the config file and sent right away",
 	Flags:       registerFlags,
 	Action:      registerOperatorMain,
+	Subcommands: []*cli.Command{
+		{
+			Name:        "prepare",
+			Usage:       "Write the registration payload for the operator of the config file, to be signed offline",
+			Description: "Reads the chain and signs the BLS public key registration. The ECDSA key is not needed",
+			Flags:       []cli.Flag{config.ConfigFileFlag, payloadFlag, saltFlag, validForFlag, gasLimitFlag},
+			Action:      prepareRegistrationMain,
+		},
+		{
+			Name:        "sign",
+			Usage:       "Sign the registration payload with the ECDSA key store of the operator, without connecting to the chain",
+			Description: "Checks the digest matches the payload, shows what is signed, then adds the operator signature and the signed registration transaction to the payload",
+			Flags:       []cli.Flag{payloadFlag, keyStoreFlag, passwordFileFlag, YesFlag},
+			Action:      signRegistrationMain,
+		},
+		{
+			Name:        "submit",
+			Usage:       "Send the signed registration transaction of the payload",
+			Description: "Any host can submit the payload, no key is needed. The operator address pays for the transaction",
+			Flags:       []cli.Flag{config.ConfigFileFlag, payloadFlag},
+			Action:      submitRegistrationMain,
+		},
+	},
 }
 
 func registerOperatorMain(ctx *cli.Context) error {
-	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
-	ecdsaConfig := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
+	operatorConfig, err := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	ecdsaConfig, err := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
+	if err != nil {
+		return err
+	}
 
 	quorumNumbers := []byte{0}
 
@@ -34,7 +94,7 @@ func registerOperatorMain(ctx *cli.Context) error {
 
 	copy(salt[:], crypto.Keccak256([]byte("churn"), []byte(time.Now().String()), quorumNumbers, privateKeyBytes))
 
-	err := operator.RegisterOperator(context.Background(), operatorConfig, ecdsaConfig, salt)
+	err = operator.RegisterOperator(context.Background(), operatorConfig, ecdsaConfig, salt)
 	if err != nil {
 		operatorConfig.BaseConfig.Logger.Error("Failed to register operator", "err", err)
 		return err
@@ -42,3 +102,102 @@ func registerOperatorMain(ctx *cli.Context) error {
 
 	return nil
 }
+
+func prepareRegistrationMain(ctx *cli.Context) error {
+	operatorConfig, err := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	salt := [32]byte{}
+	if ctx.IsSet(saltFlag.Name) {
+		decoded, err := hexutil.Decode(ctx.String(saltFlag.Name))
+		if err != nil || len(decoded) != len(salt) {
+			return fmt.Errorf("--%s must be %d bytes in hex with the 0x prefix", saltFlag.Name, len(salt))
+		}
+		copy(salt[:], decoded)
+	} else if _, err := rand.Read(salt[:]); err != nil {
+		return err
+	}
+
+	payload, err := operator.PrepareRegistration(context.Background(), operatorConfig, salt, ctx.Duration(validForFlag.Name), ctx.Uint64(gasLimitFlag.Name))
+	if err != nil {
+		return err
+	}
+	if err := payload.Write(ctx.String(payloadFlag.Name)); err != nil {
+		return err
+	}
+	fmt.Printf("Registration payload written to %s, sign it before %s\n", ctx.String(payloadFlag.Name), payload.ExpiryTime())
+	return nil
+}
+
+func signRegistrationMain(ctx *cli.Context) error {
+	payload, err := registration.ReadPayload(ctx.String(payloadFlag.Name))
+	if err != nil {
+		return err
+	}
+	if err := payload.CheckDigest(); err != nil {
+		return err
+	}
+	if err := printRegistration(payload); err != nil {
+		return err
+	}
+	if err := confirm(ctx, "Sign this registration?"); err != nil {
+		return err
+	}
+
+	password, err := readSecret(ctx, passwordFileFlag, "Password: ", false)
+	if err != nil {
+		return err
+	}
+	key, err := keys.ReadKeyStore(keys.KeyTypeEcdsa, ctx.String(keyStoreFlag.Name), password)
+	if err != nil {
+		return err
+	}
+	if err := payload.Sign(key.Ecdsa, time.Now()); err != nil {
+		return err
+	}
+	if err := payload.Write(ctx.String(payloadFlag.Name)); err != nil {
+		return err
+	}
+	fmt.Printf("Registration signed, submit %s before %s\n", ctx.String(payloadFlag.Name), payload.ExpiryTime())
+	return nil
+}
+
+func submitRegistrationMain(ctx *cli.Context) error {
+	baseConfig, err := config.NewBaseConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	payload, err := registration.ReadPayload(ctx.String(payloadFlag.Name))
+	if err != nil {
+		return err
+	}
+	receipt, err := operator.SubmitRegistration(context.Background(), baseConfig, payload)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Operator %s registered in transaction %s\n", payload.Operator, receipt.TxHash)
+	return nil
+}
+
+// printRegistration shows what signing the payload authorizes
+func printRegistration(payload *registration.Payload) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Operator\t%s\n", payload.Operator)
+	fmt.Fprintf(tw, "Chain id\t%d\n", payload.ChainId)
+	fmt.Fprintf(tw, "Service manager\t%s\n", payload.ServiceManager)
+	fmt.Fprintf(tw, "Registry coordinator\t%s\n", payload.RegistryCoordinator)
+	fmt.Fprintf(tw, "AVS directory\t%s\n", payload.AvsDirectory)
+	fmt.Fprintf(tw, "Quorums\t%v\n", []byte(payload.QuorumNumbers))
+	fmt.Fprintf(tw, "Salt\t%s\n", payload.Salt)
+	fmt.Fprintf(tw, "Expires\t%s\n", payload.ExpiryTime())
+	fmt.Fprintf(tw, "Digest\t%s\n", payload.Digest)
+	fmt.Fprintf(tw, "Nonce\t%d\n", payload.Nonce)
+	fmt.Fprintf(tw, "Max cost\t%s ETH\n", weiToEther(payload.MaxCost()))
+	return tw.Flush()
+}
+
+func weiToEther(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Text('f', -1)
+}
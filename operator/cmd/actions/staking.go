@@ -0,0 +1,303 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+// Blocks scanned for queued withdrawals by default, a few times the 7 days delay of mainnet
+const defaultWithdrawalsLookback = 200_000
+
+var (
+	tokenAmountFlag = &cli.StringFlag{
+		Name:     "amount",
+		Usage:    "Amount of tokens, with decimals, like 1.5",
+		Required: true,
+	}
+	operatorAddressFlag = &cli.StringFlag{
+		Name:     "operator-address",
+		Usage:    "Address of the operator to delegate to",
+		Required: true,
+	}
+	fromBlockFlag = &cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: fmt.Sprintf("Block to look for queued withdrawals from, %d blocks ago by default", defaultWithdrawalsLookback),
+	}
+	receiveAsSharesFlag = &cli.BoolFlag{
+		Name:  "receive-as-shares",
+		Usage: "Receive the withdrawn shares back instead of the tokens, to delegate them to another operator",
+	}
+	dryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Show and simulate the transactions without sending them",
+	}
+)
+
+var StakingCommand = &cli.Command{
+	Name:  "staking",
+	Usage: "Manage the EigenLayer stake of the address of the ECDSA key",
+	Description: "Every transaction is simulated and shown before it is sent, and sent once confirmed. " +
+		"Use --dry-run to only show them, and --yes to send them without confirmation",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "show",
+			Usage:  "Show the delegation, and the balance, allowance and deposit of the strategy token",
+			Flags:  []cli.Flag{config.ConfigFileFlag, optionalStrategyAddressFlag},
+			Action: showStakeMain,
+		},
+		{
+			Name:   "approve",
+			Usage:  "Allow the strategy manager to take an amount of the strategy token",
+			Flags:  []cli.Flag{config.ConfigFileFlag, StrategyAddressFlag, tokenAmountFlag, dryRunFlag, YesFlag},
+			Action: approveMain,
+		},
+		{
+			Name:   "deposit",
+			Usage:  "Deposit an amount of the strategy token into the strategy, approving the amount first",
+			Flags:  []cli.Flag{config.ConfigFileFlag, StrategyAddressFlag, tokenAmountFlag, dryRunFlag, YesFlag},
+			Action: depositMain,
+		},
+		{
+			Name:   "delegate",
+			Usage:  "Delegate the deposited shares to an operator",
+			Flags:  []cli.Flag{config.ConfigFileFlag, operatorAddressFlag, dryRunFlag, YesFlag},
+			Action: delegateMain,
+		},
+		{
+			Name:   "undelegate",
+			Usage:  "Undelegate from the operator, which queues the withdrawal of all the shares",
+			Flags:  []cli.Flag{config.ConfigFileFlag, dryRunFlag, YesFlag},
+			Action: undelegateMain,
+		},
+		{
+			Name:   "queue-withdrawal",
+			Usage:  "Queue the withdrawal of an amount of the strategy token",
+			Flags:  []cli.Flag{config.ConfigFileFlag, StrategyAddressFlag, tokenAmountFlag, dryRunFlag, YesFlag},
+			Action: queueWithdrawalMain,
+		},
+		{
+			Name:   "complete-withdrawals",
+			Usage:  "Complete the queued withdrawals whose delay has passed",
+			Flags:  []cli.Flag{config.ConfigFileFlag, fromBlockFlag, receiveAsSharesFlag, dryRunFlag, YesFlag},
+			Action: completeWithdrawalsMain,
+		},
+	},
+}
+
+var optionalStrategyAddressFlag = &cli.StringFlag{
+	Name:    StrategyAddressFlag.Name,
+	Usage:   StrategyAddressFlag.Usage,
+	EnvVars: StrategyAddressFlag.EnvVars,
+}
+
+func newStaker(ctx *cli.Context) (*operator.Staker, error) {
+	baseConfig, err := config.NewBaseConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	ecdsaConfig, err := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), baseConfig.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	staker, err := operator.NewStaker(baseConfig, ecdsaConfig)
+	if err != nil {
+		return nil, err
+	}
+	staker.DryRun = ctx.Bool(dryRunFlag.Name)
+	return staker, nil
+}
+
+// stakingToken reads the token of the strategy flag
+func stakingToken(ctx *cli.Context, staker *operator.Staker) (*operator.StakingToken, error) {
+	strategy := ctx.String(StrategyAddressFlag.Name)
+	if !common.IsHexAddress(strategy) {
+		return nil, fmt.Errorf("invalid strategy address %q", strategy)
+	}
+	return staker.Token(ctx.Context, common.HexToAddress(strategy))
+}
+
+func showStakeMain(ctx *cli.Context) error {
+	staker, err := newStaker(ctx)
+	if err != nil {
+		return err
+	}
+	delegatedTo, err := staker.DelegatedTo(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Staker\t%s\n", staker.Address)
+	if delegatedTo == (common.Address{}) {
+		fmt.Fprintf(tw, "Delegated to\tnone\n")
+	} else {
+		fmt.Fprintf(tw, "Delegated to\t%s\n", delegatedTo)
+	}
+	if ctx.IsSet(StrategyAddressFlag.Name) {
+		token, err := stakingToken(ctx, staker)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "Strategy\t%s\n", token.Strategy)
+		fmt.Fprintf(tw, "Token\t%s %s\n", token.Symbol, token.Token)
+		fmt.Fprintf(tw, "Balance\t%s %s\n", utils.FormatTokenAmount(token.Balance, token.Decimals), token.Symbol)
+		fmt.Fprintf(tw, "Allowance\t%s %s\n", utils.FormatTokenAmount(token.Allowance, token.Decimals), token.Symbol)
+		fmt.Fprintf(tw, "Deposited\t%s %s (%s shares)\n", utils.FormatTokenAmount(token.Deposited, token.Decimals), token.Symbol, token.Shares)
+	}
+	return tw.Flush()
+}
+
+func approveMain(ctx *cli.Context) error {
+	return runWithAmount(ctx, func(staker *operator.Staker, token *operator.StakingToken, amount *big.Int) ([]operator.StakingTx, error) {
+		tx, err := staker.Approve(token, amount)
+		return []operator.StakingTx{tx}, err
+	})
+}
+
+func depositMain(ctx *cli.Context) error {
+	return runWithAmount(ctx, func(staker *operator.Staker, token *operator.StakingToken, amount *big.Int) ([]operator.StakingTx, error) {
+		return staker.Deposit(token, amount)
+	})
+}
+
+func queueWithdrawalMain(ctx *cli.Context) error {
+	return runWithAmount(ctx, func(staker *operator.Staker, token *operator.StakingToken, amount *big.Int) ([]operator.StakingTx, error) {
+		tx, err := staker.QueueWithdrawal(ctx.Context, token, amount)
+		return []operator.StakingTx{tx}, err
+	})
+}
+
+func delegateMain(ctx *cli.Context) error {
+	operatorAddress := ctx.String(operatorAddressFlag.Name)
+	if !common.IsHexAddress(operatorAddress) {
+		return fmt.Errorf("invalid operator address %q", operatorAddress)
+	}
+	staker, err := newStaker(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := staker.Delegate(ctx.Context, common.HexToAddress(operatorAddress))
+	if err != nil {
+		return err
+	}
+	return runStakingTxs(ctx, staker, []operator.StakingTx{tx})
+}
+
+func undelegateMain(ctx *cli.Context) error {
+	staker, err := newStaker(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := staker.Undelegate(ctx.Context)
+	if err != nil {
+		return err
+	}
+	return runStakingTxs(ctx, staker, []operator.StakingTx{tx})
+}
+
+func completeWithdrawalsMain(ctx *cli.Context) error {
+	staker, err := newStaker(ctx)
+	if err != nil {
+		return err
+	}
+	latest, err := staker.LatestBlock(ctx.Context)
+	if err != nil {
+		return err
+	}
+	fromBlock := ctx.Uint64(fromBlockFlag.Name)
+	if !ctx.IsSet(fromBlockFlag.Name) && latest > defaultWithdrawalsLookback {
+		fromBlock = latest - defaultWithdrawalsLookback
+	}
+
+	withdrawals, err := staker.QueuedWithdrawals(ctx.Context, fromBlock)
+	if err != nil {
+		return err
+	}
+	var txs []operator.StakingTx
+	for _, withdrawal := range withdrawals {
+		if withdrawal.CompletableBlock > latest {
+			fmt.Printf("Withdrawal %s can be completed from block %d, the latest is %d\n", withdrawal.Root, withdrawal.CompletableBlock, latest)
+			continue
+		}
+		tx, err := staker.CompleteWithdrawal(ctx.Context, withdrawal, !ctx.Bool(receiveAsSharesFlag.Name))
+		if err != nil {
+			return err
+		}
+		txs = append(txs, tx)
+	}
+	if len(txs) == 0 {
+		fmt.Printf("No queued withdrawal to complete since block %d\n", fromBlock)
+		return nil
+	}
+	return runStakingTxs(ctx, staker, txs)
+}
+
+// runWithAmount builds the transactions of an action on an amount of the strategy token, then runs them
+func runWithAmount(ctx *cli.Context, build func(*operator.Staker, *operator.StakingToken, *big.Int) ([]operator.StakingTx, error)) error {
+	staker, err := newStaker(ctx)
+	if err != nil {
+		return err
+	}
+	token, err := stakingToken(ctx, staker)
+	if err != nil {
+		return err
+	}
+	amount, err := utils.ParseTokenAmount(ctx.String(tokenAmountFlag.Name), token.Decimals)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() <= 0 {
+		return errors.New("amount must be greater than 0")
+	}
+	txs, err := build(staker, token, amount)
+	if err != nil {
+		return err
+	}
+	return runStakingTxs(ctx, staker, txs)
+}
+
+// runStakingTxs shows the simulated transactions, and sends them unless it is a dry run and once confirmed
+func runStakingTxs(ctx *cli.Context, staker *operator.Staker, txs []operator.StakingTx) error {
+	simulations := staker.Simulate(ctx.Context, txs)
+	failed := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "From\t%s\n", staker.Address)
+	for i, simulation := range simulations {
+		fmt.Fprintf(tw, "%d. %s\n", i+1, simulation.Tx.Description)
+		fmt.Fprintf(tw, "   To\t%s\n", simulation.Tx.To)
+		fmt.Fprintf(tw, "   Data\t%s\n", hexutil.Encode(simulation.Tx.Data))
+		switch {
+		case !simulation.Simulated:
+			fmt.Fprintf(tw, "   Simulation\tskipped, it needs the previous transactions\n")
+		case simulation.Err != nil:
+			failed++
+			fmt.Fprintf(tw, "   Simulation\tfailed: %s\n", simulation.Err)
+		default:
+			fmt.Fprintf(tw, "   Simulation\tsucceeded, %d gas\n", simulation.Gas)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d transaction(s) would fail", failed)
+	}
+	if staker.DryRun {
+		return nil
+	}
+	if err := confirm(ctx, fmt.Sprintf("Send %d transaction(s)?", len(txs))); err != nil {
+		return err
+	}
+	return staker.Send(ctx.Context, txs)
+}
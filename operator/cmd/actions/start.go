@@ -3,15 +3,18 @@ package actions
 import (
 	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
-	"github.com/yetanotherco/aligned_layer/core/utils"
 	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
 )
 
 var StartFlags = []cli.Flag{
 	config.ConfigFileFlag,
+	config.WatchConfigFileFlag,
 }
 
 var StartCommand = &cli.Command{
@@ -23,13 +26,34 @@ var StartCommand = &cli.Command{
 
 func operatorMain(ctx *cli.Context) error {
 	operatorConfigFilePath := ctx.String("config")
-	operatorConfig := config.NewOperatorConfig(operatorConfigFilePath)
-	err := utils.ReadYamlConfig(operatorConfigFilePath, &operatorConfig)
+	operatorConfig, err := config.NewOperatorConfig(operatorConfigFilePath)
 	if err != nil {
 		return err
 	}
+	defer operatorConfig.BaseConfig.Close()
 
-	operator, err := operator.NewOperatorFromConfig(*operatorConfig)
+	// Stop on SIGINT or SIGTERM, cancelling the batches in flight
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	chainClients, err := operator.NewChainClientsFromConfig(operatorConfig.BaseConfig)
+	if err != nil {
+		return err
+	}
+
+	operator, err := operator.NewOperatorFromConfig(runCtx, *operatorConfig, *chainClients)
+	if err != nil {
+		return err
+	}
+
+	// Reload the fields that can be changed while running on SIGHUP or when the file changes
+	reloader, err := config.NewOperatorReloader(operatorConfigFilePath)
+	if err != nil {
+		return err
+	}
+	err = config.WatchReloads(operatorConfigFilePath, ctx.Bool(config.WatchConfigFileFlag.Name), operator.Logger, func() {
+		operator.ReloadConfig(reloader)
+	})
 	if err != nil {
 		return err
 	}
@@ -40,7 +64,7 @@ func operatorMain(ctx *cli.Context) error {
 	}
 
 	operator.Logger.Info("Operator starting...")
-	err = operator.Start(context.Background())
+	err = operator.Start(runCtx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+const statusTimeout = 1 * time.Minute
+
+var (
+	StatusFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Value: operator.StatusFormatText,
+		Usage: "Write the status as `FORMAT`, 'text' or 'json'",
+	}
+)
+
+var statusFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	StatusFormatFlag,
+}
+
+var StatusCommand = &cli.Command{
+	Name:        "status",
+	Usage:       "Show the status of the operator",
+	Description: "Shows the registration state, quorums, stake per strategy, operator ID, last processed batch and whether the aggregator is reachable",
+	Flags:       statusFlags,
+	Action:      operatorStatusMain,
+}
+
+func operatorStatusMain(ctx *cli.Context) error {
+	operatorConfig, err := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	status, err := operator.GetOperatorStatus(timeoutCtx, operatorConfig)
+	if err != nil {
+		return err
+	}
+	return status.Write(os.Stdout, ctx.String(StatusFormatFlag.Name))
+}
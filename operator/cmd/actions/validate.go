@@ -0,0 +1,26 @@
+package actions
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/validation"
+)
+
+var validateFlags = []cli.Flag{
+	config.ConfigFileFlag,
+	validation.NetworkFlag,
+	validation.FormatFlag,
+}
+
+var ValidateCommand = &cli.Command{
+	Name:        "validate",
+	Usage:       "Check the operator config file",
+	Description: "Lists every problem of the config file. With --network, also checks the endpoints, the deployed contracts and the registration of the operator",
+	Flags:       validateFlags,
+	Action:      validateOperatorConfigMain,
+}
+
+func validateOperatorConfigMain(ctx *cli.Context) error {
+	report := validation.ValidateOperatorConfig(ctx.String(config.ConfigFileFlag.Name), ctx.Bool(validation.NetworkFlag.Name))
+	return validation.Result(ctx, report)
+}
@@ -18,8 +18,13 @@ func main() {
 		Name: "Aligned Layer Node Operator",
 		Commands: []*cli.Command{
 			actions.RegisterCommand,
+			actions.DeregisterCommand,
+			actions.StatusCommand,
 			actions.StartCommand,
 			actions.DepositIntoStrategyCommand,
+			actions.StakingCommand,
+			actions.ValidateCommand,
+			actions.KeysCommand,
 		},
 		Version: Version,
 	}
@@ -0,0 +1,54 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// AvsReader is the part of chainio.AvsReader used by the operator
+type AvsReader interface {
+	IsOperatorRegistered(address ethcommon.Address) (bool, error)
+	DisabledVerifiersRetryable(ctx context.Context, opts *bind.CallOpts, config *retry.RetryParams) (*big.Int, error)
+}
+
+// AvsSubscriber is the part of chainio.AvsSubscriber used by the operator
+type AvsSubscriber interface {
+	SubscribeToNewTasksV2(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error)
+	SubscribeToNewTasksV3(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, fromBlock uint64) (chan error, error)
+	SubscribeToVerifierStatusChanges(ctx context.Context, verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled, verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled) (chan error, error)
+	SubscribeToBatchVerified(ctx context.Context, batchVerifiedChan chan [32]byte) (chan error, error)
+	RemovedBatches() <-chan [32]byte
+}
+
+// ChainClients are the clients the operator reads the chain with.
+// Tests may build them with the in-memory chain of core/chainio/fake
+type ChainClients struct {
+	AvsReader     AvsReader
+	AvsSubscriber AvsSubscriber
+}
+
+// NewChainClientsFromConfig connects to the endpoints of the config
+func NewChainClientsFromConfig(baseConfig *config.BaseConfig) (*ChainClients, error) {
+	avsReader, err := chainio.NewAvsReaderFromConfig(baseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create AVS reader: %w", err)
+	}
+
+	avsSubscriber, err := chainio.NewAvsSubscriberFromConfig(baseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create AVS subscriber: %w", err)
+	}
+
+	return &ChainClients{
+		AvsReader:     avsReader,
+		AvsSubscriber: avsSubscriber,
+	}, nil
+}
@@ -0,0 +1,167 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/yetanotherco/aligned_layer/common"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// Highest verifier index tracked in the logs and metrics, see IsVerifierDisabled
+const maxVerifierIdx = 63
+
+// DisabledVerifiersCache keeps the bitmap of disabled verifiers of the AVS contract, so it does not have to be
+// fetched for every batch. It is loaded at startup, updated from the VerifierDisabled and VerifierEnabled events
+// and periodically reconciled with the contract. Reconciliations run in the background, so they don't block the
+// operator main loop.
+type DisabledVerifiersCache struct {
+	mutex  sync.RWMutex
+	bitmap *big.Int
+	// Incremented on every update, so a reconciliation does not overwrite an event applied while it was fetching
+	version     uint64
+	reconciling atomic.Bool
+	logger      logging.Logger
+	metrics     *metrics.Metrics
+}
+
+func NewDisabledVerifiersCache(bitmap *big.Int, logger logging.Logger, metrics *metrics.Metrics) *DisabledVerifiersCache {
+	cache := &DisabledVerifiersCache{
+		bitmap:  new(big.Int),
+		logger:  logger,
+		metrics: metrics,
+	}
+	cache.Set(bitmap, "startup")
+	return cache
+}
+
+// Get returns a copy of the cached bitmap
+func (c *DisabledVerifiersCache) Get() *big.Int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return new(big.Int).Set(c.bitmap)
+}
+
+// Version returns the number of updates of the cached bitmap, see SetIfUnchanged
+func (c *DisabledVerifiersCache) Version() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.version
+}
+
+// Set replaces the cached bitmap, logging and exporting every verifier whose status changed
+func (c *DisabledVerifiersCache) Set(bitmap *big.Int, source string) {
+	c.mutex.Lock()
+	previous := c.bitmap
+	c.bitmap = new(big.Int).Set(bitmap)
+	c.version++
+	c.mutex.Unlock()
+	c.reportChanges(previous, bitmap, source)
+}
+
+// SetIfUnchanged replaces the cached bitmap only if it was not updated since version was read.
+// Returns whether the bitmap was replaced.
+func (c *DisabledVerifiersCache) SetIfUnchanged(bitmap *big.Int, version uint64, source string) bool {
+	c.mutex.Lock()
+	if c.version != version {
+		c.mutex.Unlock()
+		return false
+	}
+	previous := c.bitmap
+	c.bitmap = new(big.Int).Set(bitmap)
+	c.version++
+	c.mutex.Unlock()
+	c.reportChanges(previous, bitmap, source)
+	return true
+}
+
+func (c *DisabledVerifiersCache) reportChanges(previous *big.Int, bitmap *big.Int, source string) {
+	for verifierIdx := 0; verifierIdx <= maxVerifierIdx; verifierIdx++ {
+		wasDisabled := previous.Bit(verifierIdx) == 1
+		isDisabled := bitmap.Bit(verifierIdx) == 1
+		name := verifierName(uint8(verifierIdx))
+
+		// Every known proving system is always exported, unknown ones only while they are disabled
+		if c.metrics != nil && (isDisabled || wasDisabled || isKnownVerifier(uint8(verifierIdx))) {
+			c.metrics.SetVerifierDisabled(name, isDisabled)
+		}
+		if wasDisabled == isDisabled {
+			continue
+		}
+		if isDisabled {
+			c.logger.Warn("Verifier disabled", "provingSystem", name, "source", source)
+		} else {
+			c.logger.Info("Verifier enabled", "provingSystem", name, "source", source)
+		}
+	}
+}
+
+// SetVerifierDisabled updates a single verifier of the cached bitmap
+func (c *DisabledVerifiersCache) SetVerifierDisabled(verifierIdx uint8, disabled bool, source string) {
+	bit := uint(0)
+	if disabled {
+		bit = 1
+	}
+	c.mutex.Lock()
+	previous := c.bitmap
+	bitmap := new(big.Int).SetBit(previous, int(verifierIdx), bit)
+	c.bitmap = bitmap
+	c.version++
+	c.mutex.Unlock()
+	c.reportChanges(previous, bitmap, source)
+}
+
+// handleVerifierStatusChange applies a VerifierDisabled or VerifierEnabled event to the cache.
+// If the event was removed by a reorg, the bitmap is reloaded from the contract instead.
+func (o *Operator) handleVerifierStatusChange(ctx context.Context, verifierIdx uint8, disabled bool, removed bool) {
+	if removed {
+		o.startDisabledVerifiersReconciliation(ctx)
+		return
+	}
+	o.disabledVerifiers.SetVerifierDisabled(verifierIdx, disabled, "event")
+}
+
+// startDisabledVerifiersReconciliation reconciles the disabled verifiers in the background, as the retried call can
+// take minutes. Nothing is started if a reconciliation is already running.
+func (o *Operator) startDisabledVerifiersReconciliation(ctx context.Context) {
+	if !o.disabledVerifiers.reconciling.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer o.disabledVerifiers.reconciling.Store(false)
+		o.reconcileDisabledVerifiers(ctx)
+	}()
+}
+
+// reconcileDisabledVerifiers reloads the bitmap from the contract, in case an event was missed.
+// If an event is applied while the bitmap is fetched, the fetched bitmap may be stale and is discarded.
+func (o *Operator) reconcileDisabledVerifiers(ctx context.Context) {
+	version := o.disabledVerifiers.Version()
+	bitmap, err := o.avsReader.DisabledVerifiersRetryable(ctx, &bind.CallOpts{}, retry.NetworkRetryParams())
+	if err != nil {
+		o.Logger.Warn("Could not reconcile disabled verifiers, keeping cached value", "err", err)
+		return
+	}
+	if !o.disabledVerifiers.SetIfUnchanged(bitmap, version, "reconciliation") {
+		o.Logger.Debug("Disabled verifiers changed during reconciliation, keeping cached value")
+	}
+}
+
+func isKnownVerifier(verifierIdx uint8) bool {
+	_, err := common.ProvingSystemIdToString(common.ProvingSystemId(verifierIdx))
+	return err == nil
+}
+
+func verifierName(verifierIdx uint8) string {
+	name, err := common.ProvingSystemIdToString(common.ProvingSystemId(verifierIdx))
+	if err != nil {
+		return fmt.Sprintf("verifier_%d", verifierIdx)
+	}
+	return name
+}
@@ -0,0 +1,94 @@
+package operator
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+)
+
+func TestDisabledVerifiersCache(t *testing.T) {
+	logger := logging.NewTextSLogger(io.Discard, nil)
+	cache := NewDisabledVerifiersCache(big.NewInt(0), logger, nil)
+
+	cache.SetVerifierDisabled(uint8(common.SP1), true, "test")
+	if !IsVerifierDisabled(cache.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to be disabled")
+	}
+	if IsVerifierDisabled(cache.Get(), common.Risc0) {
+		t.Errorf("Expected Risc0 to be enabled")
+	}
+
+	cache.SetVerifierDisabled(uint8(common.SP1), false, "test")
+	if IsVerifierDisabled(cache.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to be enabled again")
+	}
+
+	// Reconciliation replaces the whole bitmap
+	cache.Set(big.NewInt(1<<common.Groth16Bn254), "test")
+	if !IsVerifierDisabled(cache.Get(), common.Groth16Bn254) {
+		t.Errorf("Expected Groth16Bn254 to be disabled after reconciliation")
+	}
+}
+
+func TestReconcileDisabledVerifiersFromChain(t *testing.T) {
+	chain := fake.NewChain()
+	o := newTestOperator()
+	o.avsReader = chain
+	o.disabledVerifiers = NewDisabledVerifiersCache(big.NewInt(0), o.Logger, nil)
+
+	// The event is missed, as the operator is not subscribed
+	chain.DisableVerifier(uint8(common.SP1))
+	if IsVerifierDisabled(o.disabledVerifiers.Get(), common.SP1) {
+		t.Fatalf("Expected SP1 to be enabled before reconciliation")
+	}
+
+	o.reconcileDisabledVerifiers(context.Background())
+	if !IsVerifierDisabled(o.disabledVerifiers.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to be disabled after reconciliation")
+	}
+}
+
+func TestStaleReconciliationIsDiscarded(t *testing.T) {
+	cache := NewDisabledVerifiersCache(big.NewInt(0), logging.NewTextSLogger(io.Discard, nil), nil)
+
+	// An event is applied while the contract is queried
+	version := cache.Version()
+	cache.SetVerifierDisabled(uint8(common.SP1), true, "event")
+	if cache.SetIfUnchanged(big.NewInt(0), version, "reconciliation") {
+		t.Errorf("Expected the reconciliation started before the event to be discarded")
+	}
+	if !IsVerifierDisabled(cache.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to stay disabled")
+	}
+
+	if !cache.SetIfUnchanged(big.NewInt(0), cache.Version(), "reconciliation") {
+		t.Errorf("Expected the reconciliation started after the event to be applied")
+	}
+	if IsVerifierDisabled(cache.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to be enabled after reconciliation")
+	}
+}
+
+func TestDisabledVerifiersReconciliationRunsInBackground(t *testing.T) {
+	chain := fake.NewChain()
+	o := newTestOperator()
+	o.avsReader = chain
+	o.disabledVerifiers = NewDisabledVerifiersCache(big.NewInt(0), o.Logger, nil)
+
+	chain.DisableVerifier(uint8(common.SP1))
+	o.startDisabledVerifiersReconciliation(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for !IsVerifierDisabled(o.disabledVerifiers.Get(), common.SP1) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !IsVerifierDisabled(o.disabledVerifiers.Get(), common.SP1) {
+		t.Errorf("Expected SP1 to be disabled after the background reconciliation")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -12,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -33,32 +35,45 @@ import (
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/backend/witness"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/yetanotherco/aligned_layer/common"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
-	"github.com/yetanotherco/aligned_layer/core/chainio"
+	retry "github.com/yetanotherco/aligned_layer/core"
 	"github.com/yetanotherco/aligned_layer/core/types"
 
 	"github.com/yetanotherco/aligned_layer/core/config"
 )
 
 type Operator struct {
+	// Config at startup. The fields that can be reloaded are read from settings
 	Config                    config.OperatorConfig
+	settings                  atomic.Pointer[config.OperatorSettings]
 	Address                   ethcommon.Address
 	Socket                    string
 	Timeout                   time.Duration
 	KeyPair                   *bls.KeyPair
 	OperatorId                eigentypes.OperatorId
-	avsSubscriber             chainio.AvsSubscriber
-	avsReader                 chainio.AvsReader
+	avsSubscriber             AvsSubscriber
+	avsReader                 AvsReader
 	NewTaskCreatedChanV2      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
 	NewTaskCreatedChanV3      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
 	Logger                    logging.Logger
-	aggRpcClient              AggregatorRpcClient
+	aggRpcClient              *AggregatorRpcClient
 	metricsReg                *prometheus.Registry
 	metrics                   *metrics.Metrics
 	lastProcessedBatch        OperatorLastProcessedBatch
 	lastProcessedBatchLogFile string
+	// Batches being processed, by batch identifier hash.
+	// Used to stop processing a batch whose NewBatchV3 log was removed by a reorg or that was already verified
+	inFlightBatches      map[[32]byte]*inFlightBatch
+	inFlightBatchesMutex sync.Mutex
+	// Batches recently verified on-chain, so they are skipped if their processing had not started yet
+	verifiedBatches      map[[32]byte]time.Time
+	verifiedBatchChan    chan [32]byte
+	disabledVerifiers    *DisabledVerifiersCache
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled
+	verifierEnabledChan  chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled
 	//Socket  string
 	//Timeout time.Duration
 }
@@ -68,15 +83,22 @@ const (
 	BatchDownloadMaxRetries = 3
 	BatchDownloadRetryDelay = 5 * time.Second
 	UnverifiedBatchOffset   = 100
+	// Period to reload the disabled verifiers bitmap from the contract, in case an event was missed
+	DisabledVerifiersReconcileInterval = 5 * time.Minute
+	// Time a verified batch is remembered, to skip it if its NewBatchV3 log arrives late
+	VerifiedBatchRetention = 10 * time.Minute
 )
 
-func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, error) {
-	logger := configuration.BaseConfig.Logger
+// Causes of the cancellation of a batch processing
+var (
+	errBatchRemoved  = errors.New("batch removed by a reorg")
+	errBatchVerified = errors.New("batch already verified")
+)
 
-	avsReader, err := chainio.NewAvsReaderFromConfig(configuration.BaseConfig)
-	if err != nil {
-		log.Fatalf("Could not create AVS reader")
-	}
+// NewOperatorFromConfig builds an operator on top of chainClients, see NewChainClientsFromConfig
+func NewOperatorFromConfig(ctx context.Context, configuration config.OperatorConfig, chainClients ChainClients) (*Operator, error) {
+	logger := configuration.BaseConfig.Logger
+	avsReader := chainClients.AvsReader
 
 	registered, err := avsReader.IsOperatorRegistered(configuration.Operator.Address)
 	if err != nil {
@@ -86,10 +108,6 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 		log.Fatal("Operator not registered")
 	}
 
-	avsSubscriber, err := chainio.NewAvsSubscriberFromConfig(configuration.BaseConfig)
-	if err != nil {
-		log.Fatalf("Could not create AVS subscriber")
-	}
 	newTaskCreatedChanV2 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2)
 	newTaskCreatedChanV3 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
@@ -109,16 +127,23 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	// Metrics
 	reg := prometheus.NewRegistry()
 	operatorMetrics := metrics.NewMetrics(configuration.Operator.MetricsIpPortAddress, reg, logger)
+	// Report the retried calls of the process in the metrics
+	retry.SetObserver(operatorMetrics)
+
+	disabledVerifiersBitmap, err := avsReader.DisabledVerifiersRetryable(ctx, &bind.CallOpts{}, retry.NetworkRetryParams())
+	if err != nil {
+		return nil, fmt.Errorf("could not get disabled verifiers: %w", err)
+	}
 
 	operator := &Operator{
 		Config:                    configuration,
 		Logger:                    logger,
-		avsSubscriber:             *avsSubscriber,
-		avsReader:                 *avsReader,
+		avsSubscriber:             chainClients.AvsSubscriber,
+		avsReader:                 avsReader,
 		Address:                   address,
 		NewTaskCreatedChanV2:      newTaskCreatedChanV2,
 		NewTaskCreatedChanV3:      newTaskCreatedChanV3,
-		aggRpcClient:              *rpcClient,
+		aggRpcClient:              rpcClient,
 		OperatorId:                operatorId,
 		metricsReg:                reg,
 		metrics:                   operatorMetrics,
@@ -127,11 +152,19 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 			BlockNumber:        0,
 			batchProcessedChan: make(chan uint32),
 		},
+		inFlightBatches:      make(map[[32]byte]*inFlightBatch),
+		verifiedBatches:      make(map[[32]byte]time.Time),
+		verifiedBatchChan:    make(chan [32]byte, 100),
+		disabledVerifiers:    NewDisabledVerifiersCache(disabledVerifiersBitmap, logger, operatorMetrics),
+		verifierDisabledChan: make(chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled),
+		verifierEnabledChan:  make(chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled),
 
 		// Timeout
 		// Socket
 	}
 
+	operator.settings.Store(configuration.Settings())
+
 	err = operator.LoadLastProcessedBatch()
 	if err != nil {
 		logger.Fatalf("Error while loading last process batch: %v. This is probably related to the `last_processed_batch_filepath` field passed in the config file", err)
@@ -140,12 +173,20 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	return operator, nil
 }
 
-func (o *Operator) SubscribeToNewTasksV2() (chan error, error) {
-	return o.avsSubscriber.SubscribeToNewTasksV2(o.NewTaskCreatedChanV2)
+func (o *Operator) SubscribeToNewTasksV2(ctx context.Context) (chan error, error) {
+	return o.avsSubscriber.SubscribeToNewTasksV2(ctx, o.NewTaskCreatedChanV2)
 }
 
-func (o *Operator) SubscribeToNewTasksV3() (chan error, error) {
-	return o.avsSubscriber.SubscribeToNewTasksV3(o.NewTaskCreatedChanV3)
+// SubscribeToNewTasksV3 subscribes to new batches, and backfills the ones not responded yet since the latest
+// batch processed by the operator. Batches are scanned from `UnverifiedBatchOffset` blocks before it, because as
+// batches are processed in parallel, there could be unverified batches slightly before it
+func (o *Operator) SubscribeToNewTasksV3(ctx context.Context) (chan error, error) {
+	// 0 means the operator hasn't processed anything yet, and only the latest blocks are scanned
+	var fromBlock uint64
+	if o.lastProcessedBatch.BlockNumber > UnverifiedBatchOffset {
+		fromBlock = uint64(o.lastProcessedBatch.BlockNumber - UnverifiedBatchOffset)
+	}
+	return o.avsSubscriber.SubscribeToNewTasksV3(ctx, o.NewTaskCreatedChanV3, fromBlock)
 }
 
 type OperatorLastProcessedBatch struct {
@@ -205,16 +246,26 @@ func (o *Operator) UpdateLastProcessBatch(blockNumber uint32) error {
 }
 
 func (o *Operator) Start(ctx context.Context) error {
-	subV2, err := o.SubscribeToNewTasksV2()
+	subV2, err := o.SubscribeToNewTasksV2(ctx)
 	if err != nil {
 		log.Fatal("Could not subscribe to new tasks")
 	}
 
-	subV3, err := o.SubscribeToNewTasksV3()
+	subV3, err := o.SubscribeToNewTasksV3(ctx)
 	if err != nil {
 		log.Fatal("Could not subscribe to new tasks")
 	}
 
+	subVerifiers, err := o.avsSubscriber.SubscribeToVerifierStatusChanges(ctx, o.verifierDisabledChan, o.verifierEnabledChan)
+	if err != nil {
+		log.Fatal("Could not subscribe to verifier status changes")
+	}
+
+	subVerifiedBatches, err := o.avsSubscriber.SubscribeToBatchVerified(ctx, o.verifiedBatchChan)
+	if err != nil {
+		log.Fatal("Could not subscribe to verified batches")
+	}
+
 	var metricsErrChan <-chan error
 	if o.Config.Operator.EnableMetrics {
 		metricsErrChan = o.metrics.Start(ctx, o.metricsReg)
@@ -222,31 +273,46 @@ func (o *Operator) Start(ctx context.Context) error {
 		metricsErrChan = make(chan error, 1)
 	}
 
-	go o.ProcessMissedBatchesWhileOffline()
+	reconcileDisabledVerifiersTicker := time.NewTicker(DisabledVerifiersReconcileInterval)
+	defer reconcileDisabledVerifiersTicker.Stop()
 
 	for {
 		select {
-		case <-context.Background().Done():
+		case <-ctx.Done():
 			o.Logger.Info("Operator shutting down...")
 			return nil
 		case err := <-metricsErrChan:
 			o.Logger.Errorf("Metrics server failed", "err", err)
 		case err := <-subV2:
 			o.Logger.Infof("Error in websocket subscription", "err", err)
-			subV2, err = o.SubscribeToNewTasksV2()
+			subV2, err = o.SubscribeToNewTasksV2(ctx)
 			if err != nil {
 				o.Logger.Fatal("Could not subscribe to new tasks V2")
 			}
 		case err := <-subV3:
 			o.Logger.Infof("Error in websocket subscription", "err", err)
-			subV2, err = o.SubscribeToNewTasksV3()
+			subV2, err = o.SubscribeToNewTasksV3(ctx)
 			if err != nil {
 				o.Logger.Fatal("Could not subscribe to new tasks V3")
 			}
 		case newBatchLogV2 := <-o.NewTaskCreatedChanV2:
-			go o.handleNewBatchLogV2(newBatchLogV2)
+			go o.handleNewBatchLogV2(ctx, newBatchLogV2)
 		case newBatchLogV3 := <-o.NewTaskCreatedChanV3:
-			go o.handleNewBatchLogV3(newBatchLogV3)
+			go o.handleNewBatchLogV3(ctx, newBatchLogV3)
+		case batchIdentifierHash := <-o.avsSubscriber.RemovedBatches():
+			o.cancelInFlightBatch(batchIdentifierHash, errBatchRemoved)
+		case batchIdentifierHash := <-o.verifiedBatchChan:
+			o.skipVerifiedBatch(batchIdentifierHash)
+		case err := <-subVerifiedBatches:
+			o.Logger.Errorf("Could not renew verified batches subscription, verified batches will be fully processed", "err", err)
+		case err := <-subVerifiers:
+			o.Logger.Errorf("Could not renew verifier status subscription, relying on periodic reconciliation", "err", err)
+		case verifierDisabled := <-o.verifierDisabledChan:
+			o.handleVerifierStatusChange(ctx, verifierDisabled.VerifierIdx, true, verifierDisabled.Raw.Removed)
+		case verifierEnabled := <-o.verifierEnabledChan:
+			o.handleVerifierStatusChange(ctx, verifierEnabled.VerifierIdx, false, verifierEnabled.Raw.Removed)
+		case <-reconcileDisabledVerifiersTicker.C:
+			o.startDisabledVerifiersReconciliation(ctx)
 		case blockNumber := <-o.lastProcessedBatch.batchProcessedChan:
 			err = o.UpdateLastProcessBatch(blockNumber)
 			if err != nil {
@@ -256,45 +322,6 @@ func (o *Operator) Start(ctx context.Context) error {
 	}
 }
 
-// Here we query all the batches that have not yet been verified starting from
-// the latest verified batch by the operator. We also read from the previous
-// `UnverifiedBatchOffset` blocks, because as batches are processed in parallel, there could be
-// unverified batches slightly before the latest verified batch
-func (o *Operator) ProcessMissedBatchesWhileOffline() {
-	// this is the default value
-	// and it means there was no file so no batches have been verified
-	if o.lastProcessedBatch.BlockNumber == 0 {
-		o.Logger.Info("Not continuing with missed batch processing, as operator hasn't verified anything yet...")
-		return
-	}
-
-	o.Logger.Info("Getting missed tasks")
-
-	// this check is necessary for overflows as go does not do saturating arithmetic
-	var fromBlock uint64
-	if o.lastProcessedBatch.BlockNumber < UnverifiedBatchOffset {
-		fromBlock = 0
-	} else {
-		fromBlock = uint64(o.lastProcessedBatch.BlockNumber - UnverifiedBatchOffset)
-	}
-
-	logs, err := o.avsReader.GetNotRespondedTasksFrom(fromBlock)
-	if err != nil {
-		return
-	}
-	o.Logger.Infof(fmt.Sprintf("Missed tasks retrieved, total tasks to process: %v", len(logs)))
-
-	if len(logs) == 0 {
-		return
-	}
-
-	o.Logger.Infof("Starting to verify missed batches while offline")
-	for _, logEntry := range logs {
-		go o.handleNewBatchLogV3(&logEntry)
-	}
-	o.Logger.Info("Finished verifying all batches missed while offline")
-}
-
 // Currently, Operator can handle NewBatchV2 and NewBatchV3 events.
 
 // The difference between these events do not affect the operator
@@ -305,12 +332,12 @@ func (o *Operator) ProcessMissedBatchesWhileOffline() {
 // different events enables the smooth operator upgradeability
 
 // Process of handling batches from V2 events:
-func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
+func (o *Operator) handleNewBatchLogV2(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
 	var err error
 	defer func() { o.afterHandlingBatchV2(newBatchLog, err == nil) }()
 
 	o.Logger.Info("Received new batch log V2")
-	err = o.ProcessNewBatchLogV2(newBatchLog)
+	err = o.ProcessNewBatchLogV2(ctx, newBatchLog)
 	if err != nil {
 		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
 		return
@@ -336,17 +363,17 @@ func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlign
 
 	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
 }
-func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
+func (o *Operator) ProcessNewBatchLogV2(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
+	downloadCtx, cancel := context.WithTimeout(ctx, BatchDownloadTimeout)
 	defer cancel()
 
-	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
+	verificationDataBatch, err := o.getBatchFromDataService(downloadCtx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
 	if err != nil {
 		o.Logger.Errorf("Could not get proofs from S3 bucket: %v", err)
 		return err
@@ -357,12 +384,7 @@ func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlig
 	var wg sync.WaitGroup
 	wg.Add(verificationDataBatchLen)
 
-	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
-	if err != nil {
-		o.Logger.Errorf("Could not check verifiers status: %s", err)
-		results <- false
-		return err
-	}
+	disabledVerifiersBitmap := o.disabledVerifiers.Get()
 
 	for _, verificationData := range verificationDataBatch {
 		go func(data VerificationData) {
@@ -387,18 +409,36 @@ func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlig
 }
 
 // Process of handling batches from V3 events:
-func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+func (o *Operator) handleNewBatchLogV3(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
 	var err error
 	defer func() { o.afterHandlingBatchV3(newBatchLog, err == nil) }()
 	o.Logger.Infof("Received new batch log V3")
-	err = o.ProcessNewBatchLogV3(newBatchLog)
+
+	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
+	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+
+	inFlight := o.trackInFlightBatch(ctx, batchIdentifierHash)
+	defer o.untrackInFlightBatch(batchIdentifierHash, inFlight)
+
+	err = o.ProcessNewBatchLogV3(inFlight.ctx, newBatchLog)
+
+	// The batch may have been removed by a reorg or verified by the others while it was being processed
+	if inFlight.ctx.Err() != nil {
+		cause := context.Cause(inFlight.ctx)
+		o.Logger.Infof("batch %x processing was cancelled, not signing it. Cause: %v", newBatchLog.BatchMerkleRoot, cause)
+		// A verified batch does not need to be processed again, so it counts as processed
+		if errors.Is(cause, errBatchVerified) {
+			err = nil
+		} else {
+			err = cause
+		}
+		return
+	}
 	if err != nil {
 		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
 		return
 	}
 
-	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
-	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
 	responseSignature := o.SignTaskResponse(batchIdentifierHash)
 	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
 
@@ -417,17 +457,17 @@ func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlign
 
 	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
 }
-func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
+func (o *Operator) ProcessNewBatchLogV3(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
+	downloadCtx, cancel := context.WithTimeout(ctx, BatchDownloadTimeout)
 	defer cancel()
 
-	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
+	verificationDataBatch, err := o.getBatchFromDataService(downloadCtx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
 	if err != nil {
 		o.Logger.Errorf("Could not get proofs from S3 bucket: %v", err)
 		return err
@@ -437,15 +477,15 @@ func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlig
 	results := make(chan bool, verificationDataBatchLen)
 	var wg sync.WaitGroup
 	wg.Add(verificationDataBatchLen)
-	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
-	if err != nil {
-		o.Logger.Errorf("Could not check verifiers status: %s", err)
-		results <- false
-		return err
-	}
+	disabledVerifiersBitmap := o.disabledVerifiers.Get()
 	for _, verificationData := range verificationDataBatch {
 		go func(data VerificationData) {
 			defer wg.Done()
+			// Proofs not started yet are skipped once the batch processing is cancelled
+			if ctx.Err() != nil {
+				o.metrics.IncOperatorSkippedProofs()
+				return
+			}
 			o.verify(data, disabledVerifiersBitmap, results)
 			o.metrics.IncOperatorTaskResponses()
 		}(verificationData)
@@ -465,6 +505,89 @@ func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlig
 	return nil
 }
 
+type inFlightBatch struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	// Number of goroutines processing the batch, as it may be delivered again once the subscriber forgets it
+	handlers int
+}
+
+// trackInFlightBatch returns a context derived from ctx that is also cancelled if the batch is removed by a reorg or
+// verified while being processed.
+// If the batch was already verified, the context is cancelled from the start.
+// untrackInFlightBatch must be called with the returned batch once the processing finishes
+func (o *Operator) trackInFlightBatch(ctx context.Context, batchIdentifierHash [32]byte) *inFlightBatch {
+	o.inFlightBatchesMutex.Lock()
+	defer o.inFlightBatchesMutex.Unlock()
+	batch, ok := o.inFlightBatches[batchIdentifierHash]
+	if !ok {
+		batchCtx, cancel := context.WithCancelCause(ctx)
+		batch = &inFlightBatch{ctx: batchCtx, cancel: cancel}
+		o.inFlightBatches[batchIdentifierHash] = batch
+		if _, verified := o.verifiedBatches[batchIdentifierHash]; verified {
+			o.metrics.IncOperatorSkippedBatches("verified")
+			cancel(errBatchVerified)
+		}
+	}
+	batch.handlers++
+	return batch
+}
+
+func (o *Operator) untrackInFlightBatch(batchIdentifierHash [32]byte, batch *inFlightBatch) {
+	o.inFlightBatchesMutex.Lock()
+	defer o.inFlightBatchesMutex.Unlock()
+	batch.handlers--
+	if batch.handlers > 0 {
+		return
+	}
+	batch.cancel(nil)
+	// The entry may already belong to a newer processing of the batch if it was removed and included again
+	if o.inFlightBatches[batchIdentifierHash] == batch {
+		delete(o.inFlightBatches, batchIdentifierHash)
+	}
+}
+
+// cancelInFlightBatch stops the processing of a batch, with the cause as the context cancellation cause
+func (o *Operator) cancelInFlightBatch(batchIdentifierHash [32]byte, cause error) {
+	o.inFlightBatchesMutex.Lock()
+	defer o.inFlightBatchesMutex.Unlock()
+	o.cancelInFlightBatchLocked(batchIdentifierHash, cause)
+}
+
+func (o *Operator) cancelInFlightBatchLocked(batchIdentifierHash [32]byte, cause error) {
+	batch, ok := o.inFlightBatches[batchIdentifierHash]
+	if !ok {
+		return
+	}
+	o.Logger.Warn("Cancelling batch processing", "cause", cause,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+	if errors.Is(cause, errBatchVerified) {
+		o.metrics.IncOperatorSkippedBatches("verified")
+	} else {
+		o.metrics.IncOperatorSkippedBatches("removed")
+	}
+	batch.cancel(cause)
+	delete(o.inFlightBatches, batchIdentifierHash)
+}
+
+// skipVerifiedBatch is called when a BatchVerified event is received. The batch already has an aggregated response,
+// so its processing is cancelled, and it is remembered for VerifiedBatchRetention in case its processing
+// has not started yet, as it happens with the batches backfilled when subscribing
+func (o *Operator) skipVerifiedBatch(batchIdentifierHash [32]byte) {
+	o.inFlightBatchesMutex.Lock()
+	defer o.inFlightBatchesMutex.Unlock()
+
+	now := time.Now()
+	for hash, verifiedAt := range o.verifiedBatches {
+		if now.Sub(verifiedAt) > VerifiedBatchRetention {
+			delete(o.verifiedBatches, hash)
+		}
+	}
+	o.verifiedBatches[batchIdentifierHash] = now
+
+	o.cancelInFlightBatchLocked(batchIdentifierHash, errBatchVerified)
+}
+
 func (o *Operator) afterHandlingBatchV2(log *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, succeeded bool) {
 	if succeeded {
 		o.lastProcessedBatch.batchProcessedChan <- uint32(log.Raw.BlockNumber)
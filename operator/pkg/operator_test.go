@@ -0,0 +1,61 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+var (
+	_ AvsReader     = (*fake.Chain)(nil)
+	_ AvsSubscriber = (*fake.Chain)(nil)
+)
+
+func newTestOperator() *Operator {
+	logger := logging.NewTextSLogger(io.Discard, nil)
+	return &Operator{
+		Logger:          logger,
+		metrics:         metrics.NewMetrics("", prometheus.NewRegistry(), logger),
+		inFlightBatches: make(map[[32]byte]*inFlightBatch),
+		verifiedBatches: make(map[[32]byte]time.Time),
+	}
+}
+
+func TestSkipVerifiedBatchCancelsInFlightBatch(t *testing.T) {
+	o := newTestOperator()
+	hash := [32]byte{1}
+
+	batch := o.trackInFlightBatch(context.Background(), hash)
+	o.skipVerifiedBatch(hash)
+
+	if !errors.Is(context.Cause(batch.ctx), errBatchVerified) {
+		t.Errorf("Expected batch to be cancelled as verified, got %v", context.Cause(batch.ctx))
+	}
+	o.untrackInFlightBatch(hash, batch)
+}
+
+func TestVerifiedBatchIsSkippedBeforeProcessing(t *testing.T) {
+	o := newTestOperator()
+	hash := [32]byte{2}
+
+	o.skipVerifiedBatch(hash)
+	batch := o.trackInFlightBatch(context.Background(), hash)
+	defer o.untrackInFlightBatch(hash, batch)
+
+	if !errors.Is(context.Cause(batch.ctx), errBatchVerified) {
+		t.Errorf("Expected batch verified before its processing to be cancelled, got %v", context.Cause(batch.ctx))
+	}
+
+	other := o.trackInFlightBatch(context.Background(), [32]byte{3})
+	defer o.untrackInFlightBatch([32]byte{3}, other)
+	if other.ctx.Err() != nil {
+		t.Errorf("Expected other batches to be processed")
+	}
+}
@@ -2,40 +2,318 @@ package operator
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
 
+	chainioutils "github.com/Layr-Labs/eigensdk-go/chainio/utils"
+	blsapkreg "github.com/Layr-Labs/eigensdk-go/contracts/bindings/BLSApkRegistry"
+	avsdirectory "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IAVSDirectory"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
 	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/registration"
 )
 
+// Validity of the registration signature when it is signed right away by RegisterOperator
+const registrationSigValidity = 1 * time.Hour
+
+// Percentage added to the gas estimate of the registration, as the registries may cost more when it is included
+const registrationGasMarginPercentage = 20
+
 // RegisterOperator operator registers the operator with the given public key for the given quorum IDs.
 // RegisterOperator registers a new operator with the given public key and socket with the provided quorum ids.
 // If the operator is already registered with a given quorum id, the transaction will fail (noop) and an error
 // will be returned.
+// The registration is prepared, signed and submitted at once, the ECDSA key must be the one of the operator address.
+// Its gas limit is estimated on the signed registration, registration.DefaultGasLimit is only used by the offline flow
 func RegisterOperator(
 	ctx context.Context,
 	configuration *config.OperatorConfig,
 	ecdsaConfig *config.EcdsaConfig,
 	operatorToAvsRegistrationSigSalt [32]byte,
 ) error {
-	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
+	payload, err := PrepareRegistration(ctx, configuration, operatorToAvsRegistrationSigSalt, registrationSigValidity, registration.DefaultGasLimit)
 	if err != nil {
-		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
+		configuration.BaseConfig.Logger.Error("Failed to prepare operator registration", "err", err)
+		return err
+	}
+	if err := payload.Sign(ecdsaConfig.PrivateKey, time.Now()); err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to sign operator registration", "err", err)
+		return err
+	}
+	gasLimit, err := estimateRegistrationGas(ctx, configuration.BaseConfig, payload)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to estimate operator registration gas", "err", err)
+		return err
+	}
+	// The gas limit is part of the signed transaction, so the payload is signed again with the estimate
+	payload.GasLimit = gasLimit
+	if err := payload.Sign(ecdsaConfig.PrivateKey, time.Now()); err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to sign operator registration", "err", err)
+		return err
+	}
+	if _, err := SubmitRegistration(ctx, configuration.BaseConfig, payload); err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to register operator", "err", err)
 		return err
 	}
+	return nil
+}
 
-	socket := "Not Needed"
+// PrepareRegistration reads what the registration of the operator needs from the chain and signs the BLS public
+// key registration. The returned payload is signed by the ECDSA key of the operator with Payload.Sign, which needs
+// no connection, then sent with SubmitRegistration
+func PrepareRegistration(
+	ctx context.Context,
+	configuration *config.OperatorConfig,
+	salt [32]byte,
+	validFor time.Duration,
+	gasLimit uint64,
+) (*registration.Payload, error) {
+	baseConfig := configuration.BaseConfig
+	operatorAddress := configuration.Operator.Address
+	opts := &bind.CallOpts{Context: ctx}
 
-	quorumNumbers := types.QuorumNums{0}
+	reader, err := chainio.NewAvsReaderFromConfig(baseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create AVS reader: %w", err)
+	}
+	registered, err := reader.IsOperatorRegistered(operatorAddress)
+	if err != nil {
+		return nil, fmt.Errorf("could not get registration status: %w", err)
+	}
+	if registered {
+		return nil, fmt.Errorf("operator %s is already registered", operatorAddress)
+	}
 
-	_, err = writer.RegisterOperator(ctx, ecdsaConfig.PrivateKey,
-		configuration.BlsConfig.KeyPair,
-		quorumNumbers, socket, true)
+	avsDirectoryAddr := baseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr
+	avsDirectory, err := avsdirectory.NewContractIAVSDirectoryCaller(avsDirectoryAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+	spent, err := avsDirectory.OperatorSaltIsSpent(opts, operatorAddress, salt)
+	if err != nil {
+		return nil, fmt.Errorf("could not check the salt: %w", err)
+	}
+	if spent {
+		return nil, fmt.Errorf("salt %s was already used by the operator", common.Hash(salt))
+	}
 
+	registryCoordinatorAddr := baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinatorCaller(registryCoordinatorAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+	keyPair := configuration.BlsConfig.KeyPair
+	if err := checkRegisteredBlsKey(opts, baseConfig, registryCoordinator, operatorAddress, types.OperatorIdFromKeyPair(keyPair)); err != nil {
+		return nil, err
+	}
+	pubkeyRegistrationMessageHash, err := registryCoordinator.PubkeyRegistrationMessageHash(opts, operatorAddress)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the public key registration message: %w", err)
+	}
+	pubkeyRegistration := regcoord.IBLSApkRegistryPubkeyRegistrationParams{
+		PubkeyRegistrationSignature: chainioutils.ConvertToBN254G1Point(
+			keyPair.SignHashedToCurveMessage(chainioutils.ConvertBn254GethToGnark(pubkeyRegistrationMessageHash)).G1Point,
+		),
+		PubkeyG1: chainioutils.ConvertToBN254G1Point(keyPair.GetPubKeyG1()),
+		PubkeyG2: chainioutils.ConvertToBN254G2Point(keyPair.GetPubKeyG2()),
+	}
+
+	// The expiry is checked against the block time, which may differ from the local clock
+	header, err := baseConfig.EthRpcPool.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the latest block: %w", err)
+	}
+	serviceManagerAddr := baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr
+	chainId := baseConfig.ChainId.Uint64()
+	expiry := header.Time + uint64(validFor.Seconds())
+	digest := registration.OperatorAVSRegistrationDigest(chainId, avsDirectoryAddr, operatorAddress, serviceManagerAddr, salt, expiry)
+	onChainDigest, err := avsDirectory.CalculateOperatorAVSRegistrationDigestHash(opts, operatorAddress, serviceManagerAddr, salt, new(big.Int).SetUint64(expiry))
+	if err != nil {
+		return nil, fmt.Errorf("could not get the registration digest: %w", err)
+	}
+	if digest != onChainDigest {
+		return nil, fmt.Errorf("the AVS directory computes the digest %s, expected %s", common.Hash(onChainDigest), digest)
+	}
+
+	nonce, err := baseConfig.EthRpcPool.PendingNonceAt(ctx, operatorAddress)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the nonce of the operator: %w", err)
+	}
+	gasTipCap, err := baseConfig.EthRpcPool.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the gas tip: %w", err)
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("the chain has no base fee, dynamic fee transactions are not supported")
+	}
+	// Leaves room for the base fee to double before the transaction is submitted
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(header.BaseFee, big.NewInt(2)))
+
+	return &registration.Payload{
+		ChainId:             chainId,
+		RegistryCoordinator: registryCoordinatorAddr,
+		AvsDirectory:        avsDirectoryAddr,
+		ServiceManager:      serviceManagerAddr,
+		Operator:            operatorAddress,
+		QuorumNumbers:       types.QuorumNums{0}.UnderlyingType(),
+		Socket:              "Not Needed",
+		PubkeyRegistration:  pubkeyRegistration,
+		Salt:                salt,
+		Expiry:              expiry,
+		Digest:              digest,
+		Nonce:               nonce,
+		GasLimit:            gasLimit,
+		GasTipCap:           gasTipCap,
+		GasFeeCap:           gasFeeCap,
+	}, nil
+}
+
+// checkRegisteredBlsKey fails if the address registered another BLS key before. The BLS APK registry binds the first
+// key registered by an address and can't update it, so the registry coordinator would register the operator again
+// with the old key and ignore the configured one
+func checkRegisteredBlsKey(
+	opts *bind.CallOpts,
+	baseConfig *config.BaseConfig,
+	registryCoordinator *regcoord.ContractRegistryCoordinatorCaller,
+	operatorAddress common.Address,
+	operatorId types.OperatorId,
+) error {
+	blsApkRegistryAddr, err := registryCoordinator.BlsApkRegistry(opts)
+	if err != nil {
+		return fmt.Errorf("could not get the BLS APK registry: %w", err)
+	}
+	blsApkRegistry, err := blsapkreg.NewContractBLSApkRegistryCaller(blsApkRegistryAddr, baseConfig.EthRpcPool)
 	if err != nil {
-		configuration.BaseConfig.Logger.Error("Failed to register operator", "err", err)
 		return err
 	}
+	registeredOperatorId, err := blsApkRegistry.GetOperatorId(opts, operatorAddress)
+	if err != nil {
+		return fmt.Errorf("could not get the registered BLS key: %w", err)
+	}
+	if registeredOperatorId != [32]byte{} && registeredOperatorId != operatorId {
+		return fmt.Errorf("%s registered the BLS key of operator id %s before, it can't be replaced by the configured key of operator id %s. "+
+			"Configure the previous key, or register a new operator address for the new key",
+			operatorAddress, common.Hash(registeredOperatorId), common.Hash(operatorId))
+	}
+	return nil
+}
+
+// SubmitRegistration sends the signed registration transaction of the payload and waits for its receipt.
+// It needs no key, the operator address pays for the transaction
+func SubmitRegistration(ctx context.Context, baseConfig *config.BaseConfig, payload *registration.Payload) (*gethtypes.Receipt, error) {
+	tx, err := payload.SignedTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if payload.ChainId != baseConfig.ChainId.Uint64() {
+		return nil, fmt.Errorf("payload is for chain %d, but the config is for chain %s", payload.ChainId, baseConfig.ChainId)
+	}
+	if payload.RegistryCoordinator != baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr {
+		return nil, fmt.Errorf("payload is for the registry coordinator %s, but the config has %s",
+			payload.RegistryCoordinator, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr)
+	}
+	header, err := baseConfig.EthRpcPool.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the latest block: %w", err)
+	}
+	if header.Time >= payload.Expiry {
+		return nil, fmt.Errorf("%w at %s, prepare the registration again", registration.ErrExpired, payload.ExpiryTime())
+	}
+	nonce, err := baseConfig.EthRpcPool.PendingNonceAt(ctx, payload.Operator)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the nonce of the operator: %w", err)
+	}
+	if nonce != payload.Nonce {
+		return nil, fmt.Errorf("the operator sent other transactions since the registration was prepared (nonce %d, expected %d), prepare it again", nonce, payload.Nonce)
+	}
+	balance, err := baseConfig.EthRpcPool.BalanceAt(ctx, payload.Operator, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the balance of the operator: %w", err)
+	}
+	if balance.Cmp(payload.MaxCost()) < 0 {
+		return nil, fmt.Errorf("the operator %s has %s wei, it needs up to %s wei to pay for the registration", payload.Operator, balance, payload.MaxCost())
+	}
+
+	if err := baseConfig.EthRpcPool.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("could not send the registration: %w", err)
+	}
+	baseConfig.Logger.Info("Registration sent, waiting for the receipt", "txHash", tx.Hash(), "operator", payload.Operator)
+	receipt, err := bind.WaitMined(ctx, baseConfig.EthRpcPool, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("registration transaction %s reverted", tx.Hash())
+	}
+	baseConfig.Logger.Info("Operator registered", "txHash", tx.Hash(), "operator", payload.Operator)
+	return receipt, nil
+}
+
+// estimateRegistrationGas estimates the gas of the signed registration of the payload, plus a margin.
+// If the registration would revert, the decoded revert is returned
+func estimateRegistrationGas(ctx context.Context, baseConfig *config.BaseConfig, payload *registration.Payload) (uint64, error) {
+	tx, err := payload.SignedTransaction()
+	if err != nil {
+		return 0, err
+	}
+	gas, err := baseConfig.EthRpcPool.EstimateGas(ctx, ethereum.CallMsg{From: payload.Operator, To: tx.To(), Data: tx.Data()})
+	if err != nil {
+		if revertErr, ok := chainio.DecodeRevert(err); ok {
+			return 0, fmt.Errorf("registration would revert: %w", revertErr)
+		}
+		return 0, fmt.Errorf("could not estimate the registration gas: %w", err)
+	}
+	return gas + gas*registrationGasMarginPercentage/100, nil
+}
+
+// DeregisterOperator removes the operator from the quorums of Aligned through the registry coordinator, which
+// in turn deregisters it from the AVS directory with DeregisterOperatorFromAVS of the service manager.
+// The ECDSA key must be the one of the operator address
+func DeregisterOperator(
+	ctx context.Context,
+	configuration *config.OperatorConfig,
+	ecdsaConfig *config.EcdsaConfig,
+) error {
+	address := crypto.PubkeyToAddress(ecdsaConfig.PrivateKey.PublicKey)
+	if address != configuration.Operator.Address {
+		return fmt.Errorf("the ecdsa key is for %s, but the operator address is %s", address, configuration.Operator.Address)
+	}
+
+	reader, err := chainio.NewAvsReaderFromConfig(configuration.BaseConfig)
+	if err != nil {
+		return fmt.Errorf("could not create AVS reader: %w", err)
+	}
+	registered, err := reader.IsOperatorRegistered(address)
+	if err != nil {
+		return fmt.Errorf("could not get registration status: %w", err)
+	}
+	if !registered {
+		return fmt.Errorf("operator %s is not registered", address)
+	}
 
+	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
+		return err
+	}
+
+	pubKey := configuration.BlsConfig.KeyPair.GetPubKeyG1()
+	_, err = writer.DeregisterOperator(ctx, types.QuorumNums{0}, regcoord.BN254G1Point{
+		X: pubKey.X.BigInt(new(big.Int)),
+		Y: pubKey.Y.BigInt(new(big.Int)),
+	}, true)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to deregister operator", "err", err)
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package operator
+
+import (
+	"fmt"
+	"net/rpc"
+
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// Settings returns the reloadable settings in use. They are swapped as a whole when the config file is reloaded
+func (o *Operator) Settings() *config.OperatorSettings {
+	return o.settings.Load()
+}
+
+// ReloadConfig re-reads the config file with reloader, swapping the reloadable settings and logging what changed.
+// The running config is kept if the file is invalid, a field that can not be reloaded changed,
+// or the aggregator at a new address can not be reached
+func (o *Operator) ReloadConfig(reloader *config.OperatorReloader) {
+	changes, err := reloader.Reload(func(environment sdklogging.LogLevel, settings *config.OperatorSettings) error {
+		// Every field is validated before any is applied, so a rejected reload changes nothing
+		var aggregatorClient *rpc.Client
+		if settings.AggregatorServerIpPortAddress != o.Settings().AggregatorServerIpPortAddress {
+			client, err := rpc.DialHTTP("tcp", settings.AggregatorServerIpPortAddress)
+			if err != nil {
+				return fmt.Errorf("could not connect to the aggregator at %s: %w", settings.AggregatorServerIpPortAddress, err)
+			}
+			aggregatorClient = client
+		}
+		setEnvironment := func() {}
+		if logger, ok := o.Logger.(*config.ReloadableLogger); ok {
+			prepared, err := logger.PrepareEnvironment(environment)
+			if err != nil {
+				if aggregatorClient != nil {
+					aggregatorClient.Close()
+				}
+				return err
+			}
+			setEnvironment = prepared
+		}
+
+		if aggregatorClient != nil {
+			o.aggRpcClient.setAggregatorClient(aggregatorClient, settings.AggregatorServerIpPortAddress)
+		}
+		setEnvironment()
+		o.settings.Store(settings)
+		return nil
+	})
+	if err != nil {
+		o.Logger.Error("Config reload rejected, keeping the running config", "err", err)
+		return
+	}
+	if len(changes) == 0 {
+		o.Logger.Info("Config reloaded, nothing changed")
+		return
+	}
+	for _, change := range changes {
+		o.Logger.Info("Config field reloaded", "field", change.Field, "old", change.Old, "new", change.New)
+	}
+}
@@ -3,6 +3,7 @@ package operator
 import (
 	"errors"
 	"net/rpc"
+	"sync"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
@@ -11,6 +12,8 @@ import (
 
 // AggregatorRpcClient is the client to communicate with the aggregator via RPC
 type AggregatorRpcClient struct {
+	// Guards the client and address, which are replaced on reconnections and config reloads
+	mutex                sync.Mutex
 	rpcClient            *rpc.Client
 	aggregatorIpPortAddr string
 	logger               logging.Logger
@@ -39,17 +42,18 @@ func NewAggregatorRpcClient(aggregatorIpPortAddr string, logger logging.Logger)
 func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskResponse *types.SignedTaskResponse) {
 	var reply uint8
 	for retries := 0; retries < MaxRetries; retries++ {
-		err := c.rpcClient.Call("Aggregator.ProcessOperatorSignedTaskResponseV2", signedTaskResponse, &reply)
+		rpcClient, aggregatorIpPortAddr := c.client()
+		err := rpcClient.Call("Aggregator.ProcessOperatorSignedTaskResponseV2", signedTaskResponse, &reply)
 		if err != nil {
 			c.logger.Error("Received error from aggregator", "err", err)
 			if errors.Is(err, rpc.ErrShutdown) {
 				c.logger.Error("Aggregator is shutdown. Reconnecting...")
-				client, err := rpc.DialHTTP("tcp", c.aggregatorIpPortAddr)
+				client, err := rpc.DialHTTP("tcp", aggregatorIpPortAddr)
 				if err != nil {
 					c.logger.Error("Could not reconnect to aggregator", "err", err)
 					time.Sleep(RetryInterval)
 				} else {
-					c.rpcClient = client
+					c.setClient(client, aggregatorIpPortAddr)
 					c.logger.Info("Reconnected to aggregator")
 				}
 			} else {
@@ -62,3 +66,30 @@ func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskRespo
 		}
 	}
 }
+
+// setAggregatorClient switches to a client connected to the aggregator at a new address, closing the current one
+func (c *AggregatorRpcClient) setAggregatorClient(client *rpc.Client, aggregatorIpPortAddr string) {
+	c.mutex.Lock()
+	previousClient := c.rpcClient
+	c.rpcClient = client
+	c.aggregatorIpPortAddr = aggregatorIpPortAddr
+	c.mutex.Unlock()
+	previousClient.Close()
+}
+
+func (c *AggregatorRpcClient) client() (*rpc.Client, string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.rpcClient, c.aggregatorIpPortAddr
+}
+
+// setClient replaces the client after a reconnection, unless the address changed meanwhile
+func (c *AggregatorRpcClient) setClient(client *rpc.Client, aggregatorIpPortAddr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.aggregatorIpPortAddr != aggregatorIpPortAddr {
+		client.Close()
+		return
+	}
+	c.rpcClient = client
+}
@@ -69,9 +69,10 @@ func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string,
 	}
 
 	contentLength := resp.ContentLength
-	if contentLength > o.Config.Operator.MaxBatchSize {
+	maxBatchSize := o.Settings().MaxBatchSize
+	if contentLength > maxBatchSize {
 		return nil, fmt.Errorf("proof size %d exceeds max batch size %d",
-			contentLength, o.Config.Operator.MaxBatchSize)
+			contentLength, maxBatchSize)
 	}
 
 	// Use io.LimitReader to limit the size of the response body
@@ -85,7 +86,7 @@ func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string,
 
 	// Check if the response body is larger than expected
 	if reader.N <= 0 {
-		return nil, fmt.Errorf("batch size exceeds max batch size %d", o.Config.Operator.MaxBatchSize)
+		return nil, fmt.Errorf("batch size exceeds max batch size %d", maxBatchSize)
 	}
 
 	// Checks if downloaded merkle root is the same as the expected one
@@ -0,0 +1,456 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	erc20 "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IERC20"
+	istrategy "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IStrategy"
+	strategymanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StrategyManager"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/metrics"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// StakingTx is a transaction of a staking action, built before it is simulated or sent
+type StakingTx struct {
+	Description string
+	To          common.Address
+	Data        []byte
+	// Set when the transaction can only succeed once the previous ones are included, so it is not simulated
+	AfterPrevious bool
+	// Sends the transaction through the elcontracts writer instead of the tx manager, see Deposit
+	send func(ctx context.Context) (*gethtypes.Receipt, error)
+	// Set when the transaction is sent by the send of the next one
+	sentWithNext bool
+}
+
+// StakingSimulation is the result of calling a StakingTx on the latest block
+type StakingSimulation struct {
+	Tx        StakingTx
+	Simulated bool
+	Gas       uint64
+	// Decoded revert of the call, nil if it succeeds
+	Err error
+}
+
+// Withdrawal is a queued withdrawal of the staker, and the block it can be completed from
+type Withdrawal struct {
+	Root             common.Hash
+	Withdrawal       delegationmanager.IDelegationManagerWithdrawal
+	CompletableBlock uint64
+}
+
+// StakingToken is the underlying token of a strategy and the position of the staker in it
+type StakingToken struct {
+	Strategy common.Address
+	Token    common.Address
+	Symbol   string
+	Decimals uint8
+	Balance  *big.Int
+	// Allowance of the strategy manager, which deposits take the tokens from
+	Allowance *big.Int
+	Shares    *big.Int
+	// Shares converted to tokens
+	Deposited *big.Int
+}
+
+// Staker builds, simulates and sends the EigenLayer staking transactions of the address of an ECDSA key.
+// Deposits are sent with the elcontracts writer of the eigensdk. The writer has no delegation, undelegation or
+// withdrawal calls, so those transactions are packed from the same contract bindings and sent with the same
+// tx manager the writer is built with
+type Staker struct {
+	Address common.Address
+	// Set to only simulate the transactions, Send then never broadcasts them
+	DryRun         bool
+	client         eth.HttpBackend
+	contracts      *elcontracts.ContractBindings
+	elWriter       *elcontracts.ChainWriter
+	txMgr          txmgr.TxManager
+	logger         logging.Logger
+	logsBlockRange uint64
+}
+
+func NewStaker(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig) (*Staker, error) {
+	address := crypto.PubkeyToAddress(ecdsaConfig.PrivateKey.PublicKey)
+	signerFn, _, err := signerv2.SignerFromConfig(signerv2.Config{PrivateKey: ecdsaConfig.PrivateKey}, baseConfig.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	w, err := wallet.NewPrivateKeyWallet(baseConfig.EthRpcPool, signerFn, address, baseConfig.Logger)
+	if err != nil {
+		return nil, err
+	}
+	logsBlockRange := baseConfig.LogsBlockRange
+	if logsBlockRange == 0 {
+		logsBlockRange = chainio.BlockInterval
+	}
+	return newStaker(
+		address,
+		baseConfig.EthRpcPool,
+		txmgr.NewSimpleTxManager(w, baseConfig.EthRpcPool, baseConfig.Logger, address),
+		baseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr,
+		baseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr,
+		baseConfig.Logger,
+		logsBlockRange,
+	)
+}
+
+func newStaker(address common.Address, client eth.HttpBackend, txMgr txmgr.TxManager, delegationManagerAddr common.Address, avsDirectoryAddr common.Address, logger logging.Logger, logsBlockRange uint64) (*Staker, error) {
+	contracts, err := elcontracts.NewEigenlayerContractBindings(delegationManagerAddr, avsDirectoryAddr, client, logger)
+	if err != nil {
+		return nil, err
+	}
+	elReader := elcontracts.NewChainReader(contracts.Slasher, contracts.DelegationManager, contracts.StrategyManager,
+		contracts.AvsDirectory, contracts.RewardsCoordinator, logger, client)
+	elWriter := elcontracts.NewChainWriter(contracts.Slasher, contracts.DelegationManager, contracts.StrategyManager,
+		contracts.RewardsCoordinator, contracts.AvsDirectory, contracts.StrategyManagerAddr, elReader, client, logger,
+		metrics.NewNoopMetrics(), txMgr)
+	return &Staker{
+		Address:        address,
+		client:         client,
+		contracts:      contracts,
+		elWriter:       elWriter,
+		txMgr:          txMgr,
+		logger:         logger,
+		logsBlockRange: logsBlockRange,
+	}, nil
+}
+
+// LatestBlock is the number withdrawal delays are compared to
+func (s *Staker) LatestBlock(ctx context.Context) (uint64, error) {
+	return s.client.BlockNumber(ctx)
+}
+
+// DelegatedTo is the operator the staker delegates to, the zero address if none
+func (s *Staker) DelegatedTo(ctx context.Context) (common.Address, error) {
+	return s.contracts.DelegationManager.DelegatedTo(&bind.CallOpts{Context: ctx}, s.Address)
+}
+
+// Token reads the underlying token of the strategy and the balance, allowance and deposit of the staker
+func (s *Staker) Token(ctx context.Context, strategyAddr common.Address) (*StakingToken, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	strategy, err := istrategy.NewContractIStrategyCaller(strategyAddr, s.client)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddr, err := strategy.UnderlyingToken(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the token of strategy %s: %w", strategyAddr, err)
+	}
+	token, err := erc20.NewContractIERC20Caller(tokenAddr, s.client)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &StakingToken{Strategy: strategyAddr, Token: tokenAddr}
+	if info.Symbol, err = token.Symbol(opts); err != nil {
+		return nil, fmt.Errorf("could not get the symbol of token %s: %w", tokenAddr, err)
+	}
+	if info.Decimals, err = token.Decimals(opts); err != nil {
+		return nil, fmt.Errorf("could not get the decimals of token %s: %w", tokenAddr, err)
+	}
+	if info.Balance, err = token.BalanceOf(opts, s.Address); err != nil {
+		return nil, fmt.Errorf("could not get the balance of token %s: %w", tokenAddr, err)
+	}
+	if info.Allowance, err = token.Allowance(opts, s.Address, s.contracts.StrategyManagerAddr); err != nil {
+		return nil, fmt.Errorf("could not get the allowance of token %s: %w", tokenAddr, err)
+	}
+	if info.Shares, err = s.contracts.StrategyManager.StakerStrategyShares(opts, s.Address, strategyAddr); err != nil {
+		return nil, fmt.Errorf("could not get the shares in strategy %s: %w", strategyAddr, err)
+	}
+	if info.Deposited, err = strategy.SharesToUnderlyingView(opts, info.Shares); err != nil {
+		return nil, fmt.Errorf("could not convert the shares of strategy %s: %w", strategyAddr, err)
+	}
+	return info, nil
+}
+
+// Approve allows the strategy manager to take the amount of the strategy token
+func (s *Staker) Approve(token *StakingToken, amount *big.Int) (StakingTx, error) {
+	data, err := pack(erc20.ContractIERC20MetaData, "approve", s.contracts.StrategyManagerAddr, amount)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	return StakingTx{
+		Description: fmt.Sprintf("Approve %s %s for the strategy manager", utils.FormatTokenAmount(amount, token.Decimals), token.Symbol),
+		To:          token.Token,
+		Data:        data,
+	}, nil
+}
+
+// Deposit approves the amount for the strategy manager and deposits it into the strategy. Both are sent by the
+// elcontracts writer, which approves every time, so the deposit is only simulated when the allowance already covers it
+func (s *Staker) Deposit(token *StakingToken, amount *big.Int) ([]StakingTx, error) {
+	if token.Balance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("balance of %s %s is lower than the amount", utils.FormatTokenAmount(token.Balance, token.Decimals), token.Symbol)
+	}
+	approval, err := s.Approve(token, amount)
+	if err != nil {
+		return nil, err
+	}
+	approval.sentWithNext = true
+	data, err := pack(strategymanager.ContractStrategyManagerMetaData, "depositIntoStrategy", token.Strategy, token.Token, amount)
+	if err != nil {
+		return nil, err
+	}
+	return []StakingTx{approval, {
+		Description:   fmt.Sprintf("Deposit %s %s into strategy %s", utils.FormatTokenAmount(amount, token.Decimals), token.Symbol, token.Strategy),
+		To:            s.contracts.StrategyManagerAddr,
+		Data:          data,
+		AfterPrevious: token.Allowance.Cmp(amount) < 0,
+		send: func(ctx context.Context) (*gethtypes.Receipt, error) {
+			return s.elWriter.DepositERC20IntoStrategy(ctx, token.Strategy, amount, true)
+		},
+	}}, nil
+}
+
+// Delegate delegates the shares of the staker to the operator. Operators with a delegation approver are not
+// supported, as their approval has to be signed by the approver
+func (s *Staker) Delegate(ctx context.Context, operatorAddr common.Address) (StakingTx, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	delegatedTo, err := s.DelegatedTo(ctx)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	if delegatedTo != (common.Address{}) {
+		return StakingTx{}, fmt.Errorf("already delegated to %s, undelegate first", delegatedTo)
+	}
+	isOperator, err := s.contracts.DelegationManager.IsOperator(opts, operatorAddr)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	if !isOperator {
+		return StakingTx{}, fmt.Errorf("%s is not an EigenLayer operator", operatorAddr)
+	}
+	approver, err := s.contracts.DelegationManager.DelegationApprover(opts, operatorAddr)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	if approver != (common.Address{}) {
+		return StakingTx{}, fmt.Errorf("operator %s requires a signature of its delegation approver %s, which is not supported", operatorAddr, approver)
+	}
+
+	data, err := pack(delegationmanager.ContractDelegationManagerMetaData, "delegateTo", operatorAddr,
+		delegationmanager.ISignatureUtilsSignatureWithExpiry{Signature: []byte{}, Expiry: big.NewInt(0)}, [32]byte{})
+	if err != nil {
+		return StakingTx{}, err
+	}
+	return StakingTx{
+		Description: fmt.Sprintf("Delegate to operator %s", operatorAddr),
+		To:          s.contracts.DelegationManagerAddr,
+		Data:        data,
+	}, nil
+}
+
+// Undelegate removes the delegation of the staker, which queues the withdrawal of all its shares
+func (s *Staker) Undelegate(ctx context.Context) (StakingTx, error) {
+	delegatedTo, err := s.DelegatedTo(ctx)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	if delegatedTo == (common.Address{}) {
+		return StakingTx{}, errors.New("not delegated to any operator")
+	}
+	data, err := pack(delegationmanager.ContractDelegationManagerMetaData, "undelegate", s.Address)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	return StakingTx{
+		Description: fmt.Sprintf("Undelegate from operator %s and queue the withdrawal of all shares", delegatedTo),
+		To:          s.contracts.DelegationManagerAddr,
+		Data:        data,
+	}, nil
+}
+
+// QueueWithdrawal queues the withdrawal of the shares worth the amount of the strategy token, to the staker
+func (s *Staker) QueueWithdrawal(ctx context.Context, token *StakingToken, amount *big.Int) (StakingTx, error) {
+	strategy, err := istrategy.NewContractIStrategyCaller(token.Strategy, s.client)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	shares, err := strategy.UnderlyingToSharesView(&bind.CallOpts{Context: ctx}, amount)
+	if err != nil {
+		return StakingTx{}, fmt.Errorf("could not convert the amount to shares: %w", err)
+	}
+	if shares.Cmp(token.Shares) > 0 {
+		return StakingTx{}, fmt.Errorf("deposit of %s %s is lower than the amount", utils.FormatTokenAmount(token.Deposited, token.Decimals), token.Symbol)
+	}
+	data, err := pack(delegationmanager.ContractDelegationManagerMetaData, "queueWithdrawals", []delegationmanager.IDelegationManagerQueuedWithdrawalParams{{
+		Strategies: []common.Address{token.Strategy},
+		Shares:     []*big.Int{shares},
+		Withdrawer: s.Address,
+	}})
+	if err != nil {
+		return StakingTx{}, err
+	}
+	return StakingTx{
+		Description: fmt.Sprintf("Queue the withdrawal of %s %s (%s shares) from strategy %s", utils.FormatTokenAmount(amount, token.Decimals), token.Symbol, shares, token.Strategy),
+		To:          s.contracts.DelegationManagerAddr,
+		Data:        data,
+	}, nil
+}
+
+// QueuedWithdrawals returns the withdrawals queued since fromBlock that the staker can still complete
+func (s *Staker) QueuedWithdrawals(ctx context.Context, fromBlock uint64) ([]Withdrawal, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	latest, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := func(ctx context.Context, fromBlock uint64, toBlock uint64) ([]delegationmanager.ContractDelegationManagerWithdrawalQueued, error) {
+		logs, err := s.contracts.DelegationManager.FilterWithdrawalQueued(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		var queued []delegationmanager.ContractDelegationManagerWithdrawalQueued
+		for logs.Next() {
+			if logs.Event.Withdrawal.Withdrawer == s.Address {
+				queued = append(queued, *logs.Event)
+			}
+		}
+		return queued, logs.Error()
+	}
+	queued, err := chainio.FilterInRanges(ctx, "FilterWithdrawalQueued", s.logsBlockRange, fromBlock, latest, filter, retry.NetworkRetryParams(), s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the queued withdrawals: %w", err)
+	}
+
+	var withdrawals []Withdrawal
+	for _, event := range queued {
+		pending, err := s.contracts.DelegationManager.PendingWithdrawals(opts, event.WithdrawalRoot)
+		if err != nil {
+			return nil, err
+		}
+		if !pending {
+			continue
+		}
+		delay, err := s.contracts.DelegationManager.GetWithdrawalDelay(opts, event.Withdrawal.Strategies)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, Withdrawal{
+			Root:             event.WithdrawalRoot,
+			Withdrawal:       event.Withdrawal,
+			CompletableBlock: uint64(event.Withdrawal.StartBlock) + delay.Uint64(),
+		})
+	}
+	return withdrawals, nil
+}
+
+// CompleteWithdrawal completes a queued withdrawal, receiving the underlying tokens, or the shares back to
+// redeposit them, possibly delegated to another operator
+func (s *Staker) CompleteWithdrawal(ctx context.Context, withdrawal Withdrawal, receiveAsTokens bool) (StakingTx, error) {
+	tokens := make([]common.Address, len(withdrawal.Withdrawal.Strategies))
+	for i, strategyAddr := range withdrawal.Withdrawal.Strategies {
+		strategy, err := istrategy.NewContractIStrategyCaller(strategyAddr, s.client)
+		if err != nil {
+			return StakingTx{}, err
+		}
+		if tokens[i], err = strategy.UnderlyingToken(&bind.CallOpts{Context: ctx}); err != nil {
+			return StakingTx{}, fmt.Errorf("could not get the token of strategy %s: %w", strategyAddr, err)
+		}
+	}
+	data, err := pack(delegationmanager.ContractDelegationManagerMetaData, "completeQueuedWithdrawal",
+		withdrawal.Withdrawal, tokens, big.NewInt(0), receiveAsTokens)
+	if err != nil {
+		return StakingTx{}, err
+	}
+	as := "shares"
+	if receiveAsTokens {
+		as = "tokens"
+	}
+	return StakingTx{
+		Description: fmt.Sprintf("Complete withdrawal %s as %s", withdrawal.Root, as),
+		To:          s.contracts.DelegationManagerAddr,
+		Data:        data,
+	}, nil
+}
+
+// Simulate calls each transaction from the staker on the latest block, and estimates its gas
+func (s *Staker) Simulate(ctx context.Context, txs []StakingTx) []StakingSimulation {
+	simulations := make([]StakingSimulation, len(txs))
+	for i, tx := range txs {
+		simulations[i].Tx = tx
+		if tx.AfterPrevious {
+			continue
+		}
+		simulations[i].Simulated = true
+		msg := ethereum.CallMsg{From: s.Address, To: &tx.To, Data: tx.Data}
+		if _, err := s.client.CallContract(ctx, msg, nil); err != nil {
+			simulations[i].Err = decodeStakingError(err)
+			continue
+		}
+		gas, err := s.client.EstimateGas(ctx, msg)
+		if err != nil {
+			simulations[i].Err = decodeStakingError(err)
+			continue
+		}
+		simulations[i].Gas = gas
+	}
+	return simulations
+}
+
+// Send sends the transactions in order, each one once the previous one is included. On a dry run it only logs them
+func (s *Staker) Send(ctx context.Context, txs []StakingTx) error {
+	if s.DryRun {
+		for _, tx := range txs {
+			s.logger.Info("Dry run, not sending the staking transaction", "description", tx.Description)
+		}
+		return nil
+	}
+	for _, tx := range txs {
+		if tx.sentWithNext {
+			continue
+		}
+		var receipt *gethtypes.Receipt
+		var err error
+		if tx.send != nil {
+			receipt, err = tx.send(ctx)
+		} else {
+			to := tx.To
+			receipt, err = s.txMgr.Send(ctx, gethtypes.NewTx(&gethtypes.DynamicFeeTx{To: &to, Data: tx.Data}), true)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", tx.Description, decodeStakingError(err))
+		}
+		if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+			return fmt.Errorf("%s: transaction %s reverted", tx.Description, receipt.TxHash)
+		}
+		s.logger.Info("Staking transaction included", "description", tx.Description, "txHash", receipt.TxHash)
+	}
+	return nil
+}
+
+// pack encodes a call of a staking transaction. Those sent by the elcontracts writer are packed too, to simulate them
+func pack(metaData *bind.MetaData, method string, args ...interface{}) ([]byte, error) {
+	contractAbi, err := metaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return contractAbi.Pack(method, args...)
+}
+
+// decodeStakingError replaces a contract revert by its reason, EigenLayer contracts revert with strings
+func decodeStakingError(err error) error {
+	if revert, ok := chainio.DecodeRevert(err); ok {
+		return revert
+	}
+	return err
+}
@@ -0,0 +1,281 @@
+package operator
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	erc20 "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IERC20"
+	strategymanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StrategyManager"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fake"
+)
+
+var testStakerAddress = common.HexToAddress("0x5a")
+
+func newTestStaker(t *testing.T, chain *fake.StakingChain) *Staker {
+	t.Helper()
+	staker, err := newStaker(testStakerAddress, chain, chain.TxManager(testStakerAddress), fake.DelegationManagerAddr,
+		fake.AVSDirectoryAddr, logging.NewTextSLogger(io.Discard, nil), 10)
+	if err != nil {
+		t.Fatalf("Could not create the staker: %v", err)
+	}
+	return staker
+}
+
+func stakingToken(t *testing.T, staker *Staker) *StakingToken {
+	t.Helper()
+	token, err := staker.Token(context.Background(), fake.StrategyAddr)
+	if err != nil {
+		t.Fatalf("Could not read the staking token: %v", err)
+	}
+	return token
+}
+
+// unpackCall decodes the calldata of a transaction with the ABI of the contract it is sent to
+func unpackCall(t *testing.T, metaData *bind.MetaData, data []byte) (string, []interface{}) {
+	t.Helper()
+	contractAbi, err := metaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Could not parse the ABI: %v", err)
+	}
+	method, err := contractAbi.MethodById(data[:4])
+	if err != nil {
+		t.Fatalf("Could not find the method of the calldata: %v", err)
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("Could not unpack the calldata of %s: %v", method.Name, err)
+	}
+	return method.Name, args
+}
+
+func TestStakerDepositApprovesWithTheDeposit(t *testing.T) {
+	chain := fake.NewStakingChain()
+	chain.SetBalance(testStakerAddress, big.NewInt(1000))
+	staker := newTestStaker(t, chain)
+	ctx := context.Background()
+
+	token := stakingToken(t, staker)
+	if token.Symbol != fake.StakingTokenSymbol || token.Decimals != fake.StakingTokenDecimals || token.Balance.Int64() != 1000 {
+		t.Fatalf("Expected the token of the strategy with a balance of 1000, got %+v", token)
+	}
+
+	txs, err := staker.Deposit(token, big.NewInt(600))
+	if err != nil {
+		t.Fatalf("Could not build the deposit: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("Expected an approval and a deposit, got %d transactions", len(txs))
+	}
+	approval, deposit := txs[0], txs[1]
+	if approval.To != fake.TokenAddr || approval.AfterPrevious {
+		t.Errorf("Expected the approval to be sent to the token first, got %+v", approval)
+	}
+	method, args := unpackCall(t, erc20.ContractIERC20MetaData, approval.Data)
+	if method != "approve" || args[0].(common.Address) != fake.StrategyManagerAddr || args[1].(*big.Int).Int64() != 600 {
+		t.Errorf("Expected approve(strategyManager, 600), got %s%v", method, args)
+	}
+	if deposit.To != fake.StrategyManagerAddr || !deposit.AfterPrevious {
+		t.Errorf("Expected the deposit to be sent to the strategy manager after the approval, got %+v", deposit)
+	}
+	method, args = unpackCall(t, strategymanager.ContractStrategyManagerMetaData, deposit.Data)
+	if method != "depositIntoStrategy" || args[0].(common.Address) != fake.StrategyAddr ||
+		args[1].(common.Address) != fake.TokenAddr || args[2].(*big.Int).Int64() != 600 {
+		t.Errorf("Expected depositIntoStrategy(strategy, token, 600), got %s%v", method, args)
+	}
+
+	if err := staker.Send(ctx, txs); err != nil {
+		t.Fatalf("Could not send the deposit: %v", err)
+	}
+	if sent := chain.SentTxs(); len(sent) != 2 || sent[0].To != fake.TokenAddr || sent[1].To != fake.StrategyManagerAddr {
+		t.Errorf("Expected the writer to send the approval and the deposit, got %+v", sent)
+	}
+	token = stakingToken(t, staker)
+	if token.Balance.Int64() != 400 || token.Shares.Int64() != 600 || token.Deposited.Int64() != 600 || token.Allowance.Sign() != 0 {
+		t.Errorf("Expected 600 deposited and 400 left, got %+v", token)
+	}
+
+	// The allowance covers the amount, so the deposit is simulated on its own
+	chain.SetAllowance(testStakerAddress, fake.StrategyManagerAddr, big.NewInt(400))
+	txs, err = staker.Deposit(stakingToken(t, staker), big.NewInt(400))
+	if err != nil {
+		t.Fatalf("Could not build the deposit: %v", err)
+	}
+	if len(txs) != 2 || txs[1].To != fake.StrategyManagerAddr || txs[1].AfterPrevious {
+		t.Fatalf("Expected the deposit not to wait for the approval, got %+v", txs)
+	}
+
+	if _, err := staker.Deposit(stakingToken(t, staker), big.NewInt(401)); err == nil {
+		t.Errorf("Expected an error when depositing more than the balance")
+	}
+}
+
+func TestStakerQueuesAndCompletesWithdrawal(t *testing.T) {
+	chain := fake.NewStakingChain()
+	chain.SetBalance(testStakerAddress, big.NewInt(1000))
+	chain.SetWithdrawalDelay(5)
+	staker := newTestStaker(t, chain)
+	ctx := context.Background()
+
+	deposit, err := staker.Deposit(stakingToken(t, staker), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("Could not build the deposit: %v", err)
+	}
+	if err := staker.Send(ctx, deposit); err != nil {
+		t.Fatalf("Could not send the deposit: %v", err)
+	}
+	fromBlock, err := staker.LatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("Could not get the latest block: %v", err)
+	}
+
+	if _, err := staker.QueueWithdrawal(ctx, stakingToken(t, staker), big.NewInt(1001)); err == nil {
+		t.Errorf("Expected an error when withdrawing more than the deposit")
+	}
+	queue, err := staker.QueueWithdrawal(ctx, stakingToken(t, staker), big.NewInt(300))
+	if err != nil {
+		t.Fatalf("Could not build the withdrawal: %v", err)
+	}
+	if queue.To != fake.DelegationManagerAddr {
+		t.Errorf("Expected the withdrawal to be queued in the delegation manager, got %s", queue.To)
+	}
+	method, args := unpackCall(t, delegationmanager.ContractDelegationManagerMetaData, queue.Data)
+	params := *abi.ConvertType(args[0], new([]delegationmanager.IDelegationManagerQueuedWithdrawalParams)).(*[]delegationmanager.IDelegationManagerQueuedWithdrawalParams)
+	if method != "queueWithdrawals" || len(params) != 1 || len(params[0].Strategies) != 1 || params[0].Strategies[0] != fake.StrategyAddr ||
+		len(params[0].Shares) != 1 || params[0].Shares[0].Int64() != 300 || params[0].Withdrawer != testStakerAddress {
+		t.Fatalf("Expected queueWithdrawals of 300 shares of the strategy to the staker, got %s%+v", method, params)
+	}
+	if err := staker.Send(ctx, []StakingTx{queue}); err != nil {
+		t.Fatalf("Could not queue the withdrawal: %v", err)
+	}
+	queuedBlock := chain.SentTxs()[len(chain.SentTxs())-1].BlockNumber
+
+	withdrawals, err := staker.QueuedWithdrawals(ctx, fromBlock)
+	if err != nil {
+		t.Fatalf("Could not get the queued withdrawals: %v", err)
+	}
+	if len(withdrawals) != 1 {
+		t.Fatalf("Expected 1 queued withdrawal, got %d", len(withdrawals))
+	}
+	withdrawal := withdrawals[0]
+	if withdrawal.CompletableBlock != queuedBlock+5 || withdrawal.Withdrawal.Shares[0].Int64() != 300 {
+		t.Errorf("Expected 300 shares completable at block %d, got %+v", queuedBlock+5, withdrawal)
+	}
+
+	complete, err := staker.CompleteWithdrawal(ctx, withdrawal, true)
+	if err != nil {
+		t.Fatalf("Could not build the completion: %v", err)
+	}
+	method, args = unpackCall(t, delegationmanager.ContractDelegationManagerMetaData, complete.Data)
+	completed := *abi.ConvertType(args[0], new(delegationmanager.IDelegationManagerWithdrawal)).(*delegationmanager.IDelegationManagerWithdrawal)
+	tokens := args[1].([]common.Address)
+	if method != "completeQueuedWithdrawal" || completed.Nonce.Cmp(withdrawal.Withdrawal.Nonce) != 0 ||
+		completed.StartBlock != withdrawal.Withdrawal.StartBlock || completed.Shares[0].Int64() != 300 ||
+		len(tokens) != 1 || tokens[0] != fake.TokenAddr || args[2].(*big.Int).Sign() != 0 || !args[3].(bool) {
+		t.Fatalf("Expected completeQueuedWithdrawal of the queued withdrawal as tokens, got %s%v", method, args)
+	}
+
+	// The withdrawal delay has not passed yet
+	simulations := staker.Simulate(ctx, []StakingTx{complete})
+	if simulations[0].Err == nil || !strings.Contains(simulations[0].Err.Error(), "minWithdrawalDelayBlocks") {
+		t.Errorf("Expected the completion to revert before the withdrawal delay, got %v", simulations[0].Err)
+	}
+
+	chain.MineBlocks(5)
+	if err := staker.Send(ctx, []StakingTx{complete}); err != nil {
+		t.Fatalf("Could not complete the withdrawal: %v", err)
+	}
+	token := stakingToken(t, staker)
+	if token.Balance.Int64() != 300 || token.Shares.Int64() != 700 {
+		t.Errorf("Expected 300 tokens withdrawn and 700 shares left, got %+v", token)
+	}
+	if withdrawals, err = staker.QueuedWithdrawals(ctx, fromBlock); err != nil || len(withdrawals) != 0 {
+		t.Errorf("Expected no queued withdrawals left, got %d (%v)", len(withdrawals), err)
+	}
+}
+
+func TestStakerSimulate(t *testing.T) {
+	chain := fake.NewStakingChain()
+	chain.SetBalance(testStakerAddress, big.NewInt(1000))
+	staker := newTestStaker(t, chain)
+	ctx := context.Background()
+
+	txs, err := staker.Deposit(stakingToken(t, staker), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("Could not build the deposit: %v", err)
+	}
+	simulations := staker.Simulate(ctx, txs)
+	if len(simulations) != 2 {
+		t.Fatalf("Expected 2 simulations, got %d", len(simulations))
+	}
+	if !simulations[0].Simulated || simulations[0].Err != nil || simulations[0].Gas == 0 {
+		t.Errorf("Expected the approval to be simulated with its gas, got %+v", simulations[0])
+	}
+	// The deposit would revert without the approval, so it is not simulated
+	if simulations[1].Simulated || simulations[1].Err != nil {
+		t.Errorf("Expected the deposit after the approval not to be simulated, got %+v", simulations[1])
+	}
+
+	// Without AfterPrevious the deposit reverts, and the revert string is decoded
+	deposit := txs[1]
+	deposit.AfterPrevious = false
+	simulations = staker.Simulate(ctx, []StakingTx{deposit})
+	if !simulations[0].Simulated {
+		t.Fatalf("Expected the deposit to be simulated")
+	}
+	revertErr, ok := chainio.DecodeRevert(simulations[0].Err)
+	if !ok || revertErr.Reason != "ERC20: insufficient allowance" {
+		t.Errorf("Expected the deposit to revert with the insufficient allowance, got %v", simulations[0].Err)
+	}
+
+	if len(chain.SentTxs()) != 0 {
+		t.Errorf("Expected simulating not to send any transaction, got %d", len(chain.SentTxs()))
+	}
+}
+
+func TestStakerDryRunNeverSends(t *testing.T) {
+	chain := fake.NewStakingChain()
+	chain.SetBalance(testStakerAddress, big.NewInt(1000))
+	operatorAddr := common.HexToAddress("0x0b")
+	chain.RegisterOperator(operatorAddr)
+	staker := newTestStaker(t, chain)
+	staker.DryRun = true
+	ctx := context.Background()
+
+	deposit, err := staker.Deposit(stakingToken(t, staker), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("Could not build the deposit: %v", err)
+	}
+	delegate, err := staker.Delegate(ctx, operatorAddr)
+	if err != nil {
+		t.Fatalf("Could not build the delegation: %v", err)
+	}
+	txs := append(deposit, delegate)
+	for _, simulation := range staker.Simulate(ctx, txs) {
+		if simulation.Err != nil {
+			t.Errorf("Expected %q to succeed, got %v", simulation.Tx.Description, simulation.Err)
+		}
+	}
+	if err := staker.Send(ctx, txs); err != nil {
+		t.Fatalf("Expected the dry run to succeed, got %v", err)
+	}
+
+	if len(chain.SentTxs()) != 0 {
+		t.Errorf("Expected the dry run not to send any transaction, got %d", len(chain.SentTxs()))
+	}
+	token := stakingToken(t, staker)
+	if token.Balance.Int64() != 1000 || token.Allowance.Sign() != 0 || token.Shares.Sign() != 0 {
+		t.Errorf("Expected the dry run not to change the position, got %+v", token)
+	}
+	if delegatedTo, err := staker.DelegatedTo(ctx); err != nil || delegatedTo != (common.Address{}) {
+		t.Errorf("Expected the dry run not to delegate, got %s (%v)", delegatedTo, err)
+	}
+}
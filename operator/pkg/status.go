@@ -0,0 +1,241 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	istrategy "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IStrategy"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	stakeregistry "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StakeRegistry"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// Registration states of the registry coordinator
+const (
+	RegistrationNeverRegistered = "never registered"
+	RegistrationRegistered      = "registered"
+	RegistrationDeregistered    = "deregistered"
+)
+
+// Time to wait for the aggregator to accept a connection when checking it is reachable
+const aggregatorDialTimeout = 10 * time.Second
+
+// Formats the status can be written in
+const (
+	StatusFormatText = "text"
+	StatusFormatJson = "json"
+)
+
+type QuorumStake struct {
+	Quorum uint8    `json:"quorum"`
+	Stake  *big.Int `json:"stake"`
+}
+
+// StrategyStake is the stake delegated to the operator in a strategy it restakes for Aligned
+type StrategyStake struct {
+	Strategy common.Address `json:"strategy"`
+	Token    common.Address `json:"token"`
+	Shares   *big.Int       `json:"shares"`
+	// Shares converted to tokens, in the smallest unit of the token
+	Amount *big.Int `json:"amount"`
+}
+
+// OperatorStatus is what the status command reports about the operator of a config
+type OperatorStatus struct {
+	Address common.Address `json:"address"`
+	// Id derived from the configured BLS key
+	OperatorId string `json:"operator_id"`
+	// Id the registry coordinator has for the address, empty if it never registered
+	RegisteredOperatorId string          `json:"registered_operator_id,omitempty"`
+	Registration         string          `json:"registration"`
+	Quorums              []QuorumStake   `json:"quorums"`
+	Strategies           []StrategyStake `json:"strategies"`
+	// Block of the last batch processed, 0 if there is none
+	LastProcessedBatchBlock uint32 `json:"last_processed_batch_block"`
+	AggregatorAddress       string `json:"aggregator_address"`
+	AggregatorReachable     bool   `json:"aggregator_reachable"`
+	AggregatorError         string `json:"aggregator_error,omitempty"`
+}
+
+// GetOperatorStatus reads the registration and stake of the operator from the chain, the last batch it processed
+// from its file, and checks the aggregator accepts connections
+func GetOperatorStatus(ctx context.Context, configuration *config.OperatorConfig) (*OperatorStatus, error) {
+	baseConfig := configuration.BaseConfig
+	address := configuration.Operator.Address
+	opts := &bind.CallOpts{Context: ctx}
+	status := &OperatorStatus{
+		Address:           address,
+		OperatorId:        fmt.Sprintf("0x%x", eigentypes.OperatorIdFromKeyPair(configuration.BlsConfig.KeyPair)),
+		Quorums:           []QuorumStake{},
+		Strategies:        []StrategyStake{},
+		AggregatorAddress: configuration.Operator.AggregatorServerIpPortAddress,
+	}
+
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinatorCaller(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+	registration, err := registryCoordinator.GetOperatorStatus(opts, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not get registration status: %w", err)
+	}
+	// 0 = NEVER_REGISTERED, 1 = REGISTERED, 2 = DEREGISTERED
+	switch registration {
+	case 0:
+		status.Registration = RegistrationNeverRegistered
+	case 1:
+		status.Registration = RegistrationRegistered
+	default:
+		status.Registration = RegistrationDeregistered
+	}
+
+	if registration != 0 {
+		operatorId, err := registryCoordinator.GetOperatorId(opts, address)
+		if err != nil {
+			return nil, fmt.Errorf("could not get operator id: %w", err)
+		}
+		status.RegisteredOperatorId = fmt.Sprintf("0x%x", operatorId)
+
+		quorumBitmap, err := registryCoordinator.GetCurrentQuorumBitmap(opts, operatorId)
+		if err != nil {
+			return nil, fmt.Errorf("could not get quorums: %w", err)
+		}
+		stakeRegistry, err := stakeregistry.NewContractStakeRegistryCaller(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerStakeRegistryAddr, baseConfig.EthRpcPool)
+		if err != nil {
+			return nil, err
+		}
+		for _, quorum := range eigentypes.BitmapToQuorumIds(quorumBitmap) {
+			stake, err := stakeRegistry.GetCurrentStake(opts, operatorId, uint8(quorum))
+			if err != nil {
+				return nil, fmt.Errorf("could not get stake in quorum %d: %w", quorum, err)
+			}
+			status.Quorums = append(status.Quorums, QuorumStake{Quorum: uint8(quorum), Stake: stake})
+		}
+	}
+
+	strategies, err := getStrategyStakes(opts, baseConfig, address)
+	if err != nil {
+		return nil, err
+	}
+	status.Strategies = strategies
+
+	var lastProcessedBatch OperatorLastProcessedBatch
+	if b, err := os.ReadFile(configuration.Operator.LastProcessedBatchFilePath); err == nil {
+		if err := json.Unmarshal(b, &lastProcessedBatch); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", configuration.Operator.LastProcessedBatchFilePath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	status.LastProcessedBatchBlock = lastProcessedBatch.BlockNumber
+
+	conn, err := net.DialTimeout("tcp", status.AggregatorAddress, aggregatorDialTimeout)
+	if err == nil {
+		conn.Close()
+		status.AggregatorReachable = true
+	} else {
+		status.AggregatorError = err.Error()
+	}
+	return status, nil
+}
+
+// getStrategyStakes returns the shares delegated to the operator in each strategy the service manager restakes
+func getStrategyStakes(opts *bind.CallOpts, baseConfig *config.BaseConfig, address common.Address) ([]StrategyStake, error) {
+	serviceManager, err := servicemanager.NewContractAlignedLayerServiceManagerCaller(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+	delegationManager, err := delegationmanager.NewContractDelegationManagerCaller(baseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr, baseConfig.EthRpcPool)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies, err := serviceManager.GetOperatorRestakedStrategies(opts, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not get restaked strategies: %w", err)
+	}
+	stakes := make([]StrategyStake, 0, len(strategies))
+	for _, strategyAddress := range strategies {
+		strategy, err := istrategy.NewContractIStrategyCaller(strategyAddress, baseConfig.EthRpcPool)
+		if err != nil {
+			return nil, err
+		}
+		shares, err := delegationManager.OperatorShares(opts, address, strategyAddress)
+		if err != nil {
+			return nil, fmt.Errorf("could not get shares in strategy %s: %w", strategyAddress, err)
+		}
+		token, err := strategy.UnderlyingToken(opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not get token of strategy %s: %w", strategyAddress, err)
+		}
+		amount, err := strategy.SharesToUnderlyingView(opts, shares)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert shares of strategy %s: %w", strategyAddress, err)
+		}
+		stakes = append(stakes, StrategyStake{Strategy: strategyAddress, Token: token, Shares: shares, Amount: amount})
+	}
+	return stakes, nil
+}
+
+// Write writes the status as aligned fields for text, or as a single object for json
+func (s *OperatorStatus) Write(w io.Writer, format string) error {
+	switch format {
+	case StatusFormatText:
+		return s.writeText(w)
+	case StatusFormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	}
+	return fmt.Errorf("unknown format %q, expected %q or %q", format, StatusFormatText, StatusFormatJson)
+}
+
+func (s *OperatorStatus) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Operator\t%s\n", s.Address)
+	fmt.Fprintf(tw, "Operator id\t%s\n", s.OperatorId)
+	registration := s.Registration
+	if s.RegisteredOperatorId != "" && s.RegisteredOperatorId != s.OperatorId {
+		registration += fmt.Sprintf(" with operator id %s, not the one of the configured BLS key", s.RegisteredOperatorId)
+	}
+	fmt.Fprintf(tw, "Registration\t%s\n", registration)
+
+	if len(s.Quorums) == 0 {
+		fmt.Fprintf(tw, "Quorums\tnone\n")
+	}
+	for _, quorum := range s.Quorums {
+		fmt.Fprintf(tw, "Stake in quorum %d\t%s\n", quorum.Quorum, quorum.Stake)
+	}
+	if len(s.Strategies) == 0 {
+		fmt.Fprintf(tw, "Restaked strategies\tnone\n")
+	}
+	for _, strategy := range s.Strategies {
+		fmt.Fprintf(tw, "Strategy %s\t%s shares, %s of token %s\n", strategy.Strategy, strategy.Shares, strategy.Amount, strategy.Token)
+	}
+
+	if s.LastProcessedBatchBlock == 0 {
+		fmt.Fprintf(tw, "Last processed batch\tnone\n")
+	} else {
+		fmt.Fprintf(tw, "Last processed batch\tblock %d\n", s.LastProcessedBatchBlock)
+	}
+	aggregator := "reachable"
+	if !s.AggregatorReachable {
+		aggregator = "unreachable: " + strings.TrimSpace(s.AggregatorError)
+	}
+	fmt.Fprintf(tw, "Aggregator %s\t%s\n", s.AggregatorAddress, aggregator)
+	return tw.Flush()
+}
@@ -0,0 +1,63 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestOperatorStatusWrite(t *testing.T) {
+	status := &OperatorStatus{
+		Address:              common.HexToAddress("0x1"),
+		OperatorId:           "0xaa",
+		RegisteredOperatorId: "0xbb",
+		Registration:         RegistrationRegistered,
+		Quorums:              []QuorumStake{{Quorum: 0, Stake: big.NewInt(10)}},
+		AggregatorAddress:    "localhost:1337",
+		AggregatorError:      "connection refused",
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := status.Write(&out, StatusFormatText); err != nil {
+			t.Fatal(err)
+		}
+		// Columns are padded to the longest field name
+		text := strings.Join(strings.Fields(out.String()), " ")
+		for _, want := range []string{
+			"with operator id 0xbb, not the one of the configured BLS key",
+			"Stake in quorum 0",
+			"Restaked strategies none",
+			"Last processed batch none",
+			"unreachable: connection refused",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("output does not contain %q:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := status.Write(&out, StatusFormatJson); err != nil {
+			t.Fatal(err)
+		}
+		var decoded OperatorStatus
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Registration != RegistrationRegistered || decoded.Quorums[0].Stake.Cmp(big.NewInt(10)) != 0 {
+			t.Errorf("decoded status %+v does not match", decoded)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := status.Write(&bytes.Buffer{}, "yaml"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}